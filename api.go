@@ -0,0 +1,407 @@
+package infinibox
+
+import (
+	"context"
+)
+
+//VolumeAPI represents volume operations
+type VolumeAPI interface {
+	GetVolume(volumeID int64) (*Volume, error)
+	GetVolumeByName(volumename string) (*Volume, error)
+	GetAllVolumes() (*[]Volume, error)
+	CreateVolume(volume *Volume) error
+	DeleteVolume(volume *Volume) error
+	RenameVolume(volume *Volume, name string) error
+	ResizeVolume(volume *Volume, size uint64) error
+	SnapshotVolume(volume *Volume, name string) (*Volume, error)
+	RestoreVolume(volume *Volume, snapshotID uint64) error
+	MapVolumeToHost(volume *Volume, host *Host, startLun int) (*Lun, error)
+	MapVolumeToCluster(volume *Volume, cluster *HostCluster, startLun int) (*Lun, error)
+	UnMapVolume(volume *Volume) error
+	UnMapAndDeleteVolume(volume *Volume) (*UnMapDeleteReport, error)
+	GetVolumeLUNs(volume *Volume) (*[]Lun, error)
+	RefreshVolume(volume *Volume, snapshotID uint64) error
+	UpdateVolumeProvisioning(volume *Volume, provtype string) error
+}
+
+//PoolAPI represents pool operations
+type PoolAPI interface {
+	GetPool(poolID int64) (*Pool, error)
+	GetPoolByName(poolname string) (*Pool, error)
+	GetAllPools() (*[]Pool, error)
+	CreatePool(pool *Pool) error
+	DeletePool(pool *Pool) error
+	RenamePool(pool *Pool, name string) error
+	UpdatePoolPhysicalCapacity(pool *Pool, capacity uint64) error
+	UpdatePoolVirtualCapacity(pool *Pool, capacity uint64) error
+}
+
+//HostAPI represents host operations
+type HostAPI interface {
+	GetHost(hostID int64) (*Host, error)
+	GetHostByName(hostname string) (*Host, error)
+	GetAllHosts() (*[]Host, error)
+	CreateHost(host *Host) error
+	DeleteHost(host *Host) error
+	UpdateHost(host *Host) error
+	RenameHost(host *Host, name string) error
+	AddHostPort(host *Host, port *Port) error
+	GetHostPorts(host *Host) (*[]Port, error)
+	AddHostLUN(host *Host, lun *Lun) error
+	DeleteHostLUN(host *Host, lunID int) (*Lun, error)
+	GetHostLUNs(host *Host) (*[]Lun, error)
+	UnMapVolumeFromHost(host *Host, volumeID uint64) (*Lun, error)
+	SetHostChap(host *Host, credentials ChapCredentials) error
+	ClearHostChap(host *Host) error
+	RotateHostChap(host *Host, onRotate ChapRotateFunc) (*ChapCredentials, error)
+}
+
+//HostClusterAPI represents host cluster operations
+type HostClusterAPI interface {
+	GetHostClusterByName(clustername string) (*HostCluster, error)
+	GetAllHostClusters() (*[]HostCluster, error)
+	CreateHostCluster(cluster *HostCluster) error
+	DeleteHostCluster(cluster *HostCluster) error
+	RenameHostCluster(cluster *HostCluster, name string) error
+	AddHostToCluster(cluster *HostCluster, hostID uint64) error
+	DeleteHostFromCluster(cluster *HostCluster, hostID uint64) error
+	GetHostClusterHosts(cluster *HostCluster) (*[]Host, error)
+	GetHostClusterLUNs(cluster *HostCluster) (*[]Lun, error)
+	AddHostClusterLUN(cluster *HostCluster, lun *Lun) error
+	DeleteHostClusterLUN(cluster *HostCluster, lunID int) (*Lun, error)
+}
+
+//TenantAPI represents tenant operations
+type TenantAPI interface {
+	GetTenant(tenantID int64) (*Tenant, error)
+	GetTenantByName(tenantname string) (*Tenant, error)
+	GetAllTenants() (*[]Tenant, error)
+	CreateTenant(tenant *Tenant) error
+	DeleteTenant(tenant *Tenant) error
+	RenameTenant(tenant *Tenant, name string) error
+	UpdateTenantVisibleToSysadmin(tenant *Tenant, visible bool) error
+}
+
+//MetadataAPI represents metadata operations
+type MetadataAPI interface {
+	GetAllMetadata() (*[]Metadata, error)
+	GetMetadataByObject(objectID int64) (*[]Metadata, error)
+	GetMetadataByObjectAndKey(objectID int64, key string) (*Metadata, error)
+	AddMetadata(metadata *Metadata) error
+	AddMetadataMap(objectID int64, values map[string]interface{}) error
+	DeleteMetadata(objectID int64) error
+	DeleteMetadataByKey(objectID int64, key string) error
+	FindObjectsByMetadata(objectType string, key string, value string) (*[]Metadata, error)
+	GetMetadataString(objectID int64, key string) (string, error)
+	GetMetadataInt(objectID int64, key string) (int64, error)
+	GetMetadataBool(objectID int64, key string) (bool, error)
+	GetMetadataJSON(objectID int64, key string, target interface{}) error
+	SetMetadataString(objectID int64, key string, value string) error
+	SetMetadataInt(objectID int64, key string, value int64) error
+	SetMetadataBool(objectID int64, key string, value bool) error
+	SetMetadataJSON(objectID int64, key string, value interface{}) error
+}
+
+//OwnershipAPI represents ownership operations
+type OwnershipAPI interface {
+	StampOwnership(objectID int64, ownership Ownership) error
+	GetOwnership(objectID int64) (*OwnedObject, error)
+	ListOwned(owner string) (*[]OwnedObject, error)
+	FindOrphans(owner string, exists OwnerExistsFunc) (*[]OwnedObject, error)
+	CheckOwnership(objectID int64, owner string) error
+}
+
+//PluginAPI represents plugin operations
+type PluginAPI interface {
+	GetPlugin(pluginID int64) (*Plugin, error)
+	GetPluginByName(pluginname string) (*Plugin, error)
+	GetAllPlugins() (*[]Plugin, error)
+	CreatePlugin(plugin *Plugin) error
+	DeletePlugin(plugin *Plugin) error
+	UpdatePlugin(plugin *Plugin) error
+	SendPluginHeartbeat(plugin *Plugin, heartbeat Heartbeat) error
+}
+
+//InitiatorAPI represents initiator operations
+type InitiatorAPI interface {
+	GetAllInitiators() (*[]Initiator, error)
+	GetInitiatorByAddress(address string) (*Initiator, error)
+	GetHostIDbyInitiatorAddress(address string) (int64, error)
+}
+
+//SystemAPI represents system operations
+type SystemAPI interface {
+	GetSystem() (*System, error)
+	Supports(feature string) (bool, error)
+}
+
+//EventAPI represents event operations
+type EventAPI interface {
+	GetEvents(filter EventFilter) (*[]Event, error)
+	GetEvent(eventID int64) (*Event, error)
+	CreateCustomEvent(code string, description string, data map[string]string) (*Event, error)
+	SubscribeEvents(ctx context.Context, filter EventFilter) <-chan Event
+}
+
+//API represents IBOX operations implemented by Client, code depending on API can be
+//tested with fake implementation from fake package. GetMetadataAs and SetMetadataAs are
+//generic and cannot be part of API, typed metadata methods cover them. Object methods taking
+//Client, like Volume.CreateOwned, and client settings, like WithTenant, are not part of API
+type API interface {
+	VolumeAPI
+	PoolAPI
+	HostAPI
+	HostClusterAPI
+	TenantAPI
+	MetadataAPI
+	PluginAPI
+	InitiatorAPI
+	OwnershipAPI
+	SystemAPI
+	EventAPI
+}
+
+var _ API = (*Client)(nil)
+
+//CreateVolume creates volume
+func (c *Client) CreateVolume(volume *Volume) error {
+	return volume.Create(c)
+}
+
+//DeleteVolume deletes volume
+func (c *Client) DeleteVolume(volume *Volume) error {
+	return volume.Delete(c)
+}
+
+//RenameVolume renames volume
+func (c *Client) RenameVolume(volume *Volume, name string) error {
+	return volume.UpdateName(c, name)
+}
+
+//ResizeVolume sets volume size
+func (c *Client) ResizeVolume(volume *Volume, size uint64) error {
+	return volume.UpdateSize(c, size)
+}
+
+//SnapshotVolume creates volume snapshot
+func (c *Client) SnapshotVolume(volume *Volume, name string) (*Volume, error) {
+	return volume.Snapshot(c, name)
+}
+
+//RestoreVolume restores volume from snapshot
+func (c *Client) RestoreVolume(volume *Volume, snapshotID uint64) error {
+	return volume.Restore(c, snapshotID)
+}
+
+//MapVolumeToHost maps volume to host using first free LUN starting from startLun
+func (c *Client) MapVolumeToHost(volume *Volume, host *Host, startLun int) (*Lun, error) {
+	return volume.MapToHost(c, host, startLun)
+}
+
+//MapVolumeToCluster maps volume to host cluster using first free LUN starting from startLun
+func (c *Client) MapVolumeToCluster(volume *Volume, cluster *HostCluster, startLun int) (*Lun, error) {
+	return volume.MapToCluster(c, cluster, startLun)
+}
+
+//UnMapVolume unmaps volume from all hosts and host clusters
+func (c *Client) UnMapVolume(volume *Volume) error {
+	return volume.UnMap(c)
+}
+
+//UnMapAndDeleteVolume unmaps volume and deletes it, mappings are restored when deletion fails
+func (c *Client) UnMapAndDeleteVolume(volume *Volume) (*UnMapDeleteReport, error) {
+	return volume.UnMapAndDelete(c)
+}
+
+//GetVolumeLUNs returns volume LUNs
+func (c *Client) GetVolumeLUNs(volume *Volume) (*[]Lun, error) {
+	return volume.GetLUNs(c)
+}
+
+//RefreshVolume refreshes volume snapshot with current volume data
+func (c *Client) RefreshVolume(volume *Volume, snapshotID uint64) error {
+	return volume.Refresh(c, snapshotID)
+}
+
+//UpdateVolumeProvisioning sets volume provisioning type, THIN or THICK
+func (c *Client) UpdateVolumeProvisioning(volume *Volume, provtype string) error {
+	return volume.UpdateProvisioning(c, provtype)
+}
+
+//CreatePool creates pool
+func (c *Client) CreatePool(pool *Pool) error {
+	return pool.Create(c)
+}
+
+//DeletePool deletes pool
+func (c *Client) DeletePool(pool *Pool) error {
+	_, err := pool.Delete(c)
+	return err
+}
+
+//RenamePool renames pool
+func (c *Client) RenamePool(pool *Pool, name string) error {
+	return pool.UpdateName(c, name)
+}
+
+//UpdatePoolPhysicalCapacity sets pool physical capacity
+func (c *Client) UpdatePoolPhysicalCapacity(pool *Pool, capacity uint64) error {
+	return pool.UpdatePhysicalCapacity(c, capacity)
+}
+
+//UpdatePoolVirtualCapacity sets pool virtual capacity
+func (c *Client) UpdatePoolVirtualCapacity(pool *Pool, capacity uint64) error {
+	return pool.UpdateVirtualCapacity(c, capacity)
+}
+
+//CreateHost creates host
+func (c *Client) CreateHost(host *Host) error {
+	return host.Create(c)
+}
+
+//DeleteHost deletes host
+func (c *Client) DeleteHost(host *Host) error {
+	return host.Delete(c)
+}
+
+//UpdateHost updates host name and CHAP attributes
+func (c *Client) UpdateHost(host *Host) error {
+	return host.Update(c)
+}
+
+//RenameHost renames host
+func (c *Client) RenameHost(host *Host, name string) error {
+	return host.UpdateName(c, name)
+}
+
+//AddHostPort adds port to host
+func (c *Client) AddHostPort(host *Host, port *Port) error {
+	return host.AddPort(c, port)
+}
+
+//GetHostPorts returns host ports
+func (c *Client) GetHostPorts(host *Host) (*[]Port, error) {
+	return host.GetPorts(c)
+}
+
+//AddHostLUN maps lun to host
+func (c *Client) AddHostLUN(host *Host, lun *Lun) error {
+	return host.AddLUN(c, lun)
+}
+
+//DeleteHostLUN unmaps host lun
+func (c *Client) DeleteHostLUN(host *Host, lunID int) (*Lun, error) {
+	return host.DeleteLUN(c, lunID)
+}
+
+//GetHostLUNs returns host LUNs
+func (c *Client) GetHostLUNs(host *Host) (*[]Lun, error) {
+	return host.GetLUNs(c)
+}
+
+//UnMapVolumeFromHost unmaps volume from host
+func (c *Client) UnMapVolumeFromHost(host *Host, volumeID uint64) (*Lun, error) {
+	return host.UnMapVolume(c, volumeID)
+}
+
+//SetHostChap sets host CHAP or mutual CHAP credentials
+func (c *Client) SetHostChap(host *Host, credentials ChapCredentials) error {
+	return host.SetChap(c, credentials)
+}
+
+//ClearHostChap disables host CHAP authentication
+func (c *Client) ClearHostChap(host *Host) error {
+	return host.ClearChap(c)
+}
+
+//RotateHostChap rotates host CHAP secrets and calls onRotate with new credentials
+func (c *Client) RotateHostChap(host *Host, onRotate ChapRotateFunc) (*ChapCredentials, error) {
+	return host.RotateChap(c, onRotate)
+}
+
+//CreateHostCluster creates host cluster
+func (c *Client) CreateHostCluster(cluster *HostCluster) error {
+	return cluster.Create(c)
+}
+
+//DeleteHostCluster deletes host cluster
+func (c *Client) DeleteHostCluster(cluster *HostCluster) error {
+	return cluster.Delete(c)
+}
+
+//RenameHostCluster renames host cluster
+func (c *Client) RenameHostCluster(cluster *HostCluster, name string) error {
+	return cluster.UpdateName(c, name)
+}
+
+//AddHostToCluster adds host to host cluster
+func (c *Client) AddHostToCluster(cluster *HostCluster, hostID uint64) error {
+	return cluster.AddHost(c, hostID)
+}
+
+//DeleteHostFromCluster removes host from host cluster
+func (c *Client) DeleteHostFromCluster(cluster *HostCluster, hostID uint64) error {
+	return cluster.DeleteHost(c, hostID)
+}
+
+//GetHostClusterHosts returns hosts of host cluster
+func (c *Client) GetHostClusterHosts(cluster *HostCluster) (*[]Host, error) {
+	return cluster.GetHosts(c)
+}
+
+//GetHostClusterLUNs returns host cluster LUNs
+func (c *Client) GetHostClusterLUNs(cluster *HostCluster) (*[]Lun, error) {
+	return cluster.GetLUNs(c)
+}
+
+//AddHostClusterLUN maps lun to host cluster
+func (c *Client) AddHostClusterLUN(cluster *HostCluster, lun *Lun) error {
+	return cluster.AddLUN(c, lun)
+}
+
+//DeleteHostClusterLUN unmaps host cluster lun
+func (c *Client) DeleteHostClusterLUN(cluster *HostCluster, lunID int) (*Lun, error) {
+	return cluster.DeleteLUN(c, lunID)
+}
+
+//CreateTenant creates tenant
+func (c *Client) CreateTenant(tenant *Tenant) error {
+	return tenant.Create(c)
+}
+
+//DeleteTenant deletes tenant
+func (c *Client) DeleteTenant(tenant *Tenant) error {
+	_, err := tenant.Delete(c)
+	return err
+}
+
+//RenameTenant renames tenant
+func (c *Client) RenameTenant(tenant *Tenant, name string) error {
+	return tenant.UpdateName(c, name)
+}
+
+//UpdateTenantVisibleToSysadmin sets whether tenant is visible to sysadmin
+func (c *Client) UpdateTenantVisibleToSysadmin(tenant *Tenant, visible bool) error {
+	return tenant.UpdateVisibleToSysadmin(c, visible)
+}
+
+//CreatePlugin registers plugin
+func (c *Client) CreatePlugin(plugin *Plugin) error {
+	return plugin.Create(c)
+}
+
+//DeletePlugin unregisters plugin
+func (c *Client) DeletePlugin(plugin *Plugin) error {
+	_, err := plugin.Delete(c)
+	return err
+}
+
+//UpdatePlugin updates plugin attributes
+func (c *Client) UpdatePlugin(plugin *Plugin) error {
+	return plugin.Update(c)
+}
+
+//SendPluginHeartbeat sends plugin heartbeat
+func (c *Client) SendPluginHeartbeat(plugin *Plugin, heartbeat Heartbeat) error {
+	return plugin.SendPluginHeartbeat(c, heartbeat)
+}
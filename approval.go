@@ -0,0 +1,175 @@
+package infinibox
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/go-resty/resty"
+	"regexp"
+)
+
+//ApprovalMode selects how client approves operations IBOX asks to confirm
+type ApprovalMode int
+
+//Approval modes
+const (
+	//ApproveAlways sends every operation approved, IBOX confirmation safeguards are bypassed
+	ApproveAlways ApprovalMode = iota
+	//ApproveNever sends operations unapproved, operations IBOX asks to confirm fail
+	ApproveNever
+	//ApproveCallback sends operations unapproved and resends them approved when callback accepts IBOX confirmation message
+	ApproveCallback
+)
+
+//Operations passed to approval callback
+const (
+	OperationDelete  = "delete"
+	OperationUpdate  = "update"
+	OperationAddPort = "add_port"
+	OperationMap     = "map"
+	OperationUnmap   = "unmap"
+	OperationRestore = "restore"
+	OperationRefresh = "refresh"
+)
+
+//APIErrorApprovalRequired is error code of operations IBOX refuses to perform without approval
+const APIErrorApprovalRequired = "APPROVAL_REQUIRED"
+
+//ErrApprovalDenied is wrapped by errors of operations which were not approved
+var ErrApprovalDenied = errors.New("approval denied")
+
+//ErrProtectedObject is wrapped by errors of deletions refused for protected objects
+var ErrProtectedObject = errors.New("object is protected")
+
+//ApprovalRequest describes operation waiting for approval, Message holds IBOX confirmation message,
+//in dry run mode IBOX is not asked and callback gets request with empty Message
+type ApprovalRequest struct {
+	Operation  string
+	ObjectType string
+	ObjectName string
+	Method     string
+	URL        string
+	Message    string
+}
+
+//ApprovalFunc returns true when operation should be performed
+type ApprovalFunc func(request ApprovalRequest) bool
+
+//ApprovalPolicy decides whether operations IBOX asks to confirm are approved, zero value approves always
+type ApprovalPolicy struct {
+	Mode    ApprovalMode
+	Approve ApprovalFunc
+}
+
+//ApprovalWith returns policy approving operations accepted by approve
+func ApprovalWith(approve ApprovalFunc) ApprovalPolicy {
+	return ApprovalPolicy{Mode: ApproveCallback, Approve: approve}
+}
+
+//isProtected returns true when name matches protected names or patterns
+func (c *Client) isProtected(name string) bool {
+	for _, protected := range c.config.ProtectedNames {
+		if name == protected {
+			return true
+		}
+	}
+	for _, pattern := range c.config.ProtectedPatterns {
+		if pattern != nil && pattern.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+//ProtectPattern compiles protected object name pattern, pattern must match whole name
+func ProtectPattern(pattern string) (*regexp.Regexp, error) {
+	return regexp.Compile("^(?:" + pattern + ")$")
+}
+
+//resolveObjectName returns name IBOX reports for object at url
+func (c *Client) resolveObjectName(url string) (string, error) {
+
+	response, err := c.request().Get(url)
+
+	result, err := CheckAPIResponse(response, err)
+	if err != nil {
+		return "", err
+	}
+
+	var object struct {
+		Name string `json:"name"`
+	}
+	err = json.Unmarshal(*result.APIResult, &object)
+	if err != nil {
+		return "", fmt.Errorf("json: %s", err.Error())
+	}
+
+	return object.Name, nil
+}
+
+//sendApproved sends operation applying client approval policy, deletion of objects protected by their current name is refused,
+//in dry run mode callback policy is asked up front and plan step records its decision
+func (c *Client) sendApproved(approval ApprovalRequest, body interface{}) (*resty.Response, error) {
+
+	//name passed by caller may be stale or missing, protection is checked against name IBOX reports for deleted ID
+	if approval.Operation == OperationDelete && (len(c.config.ProtectedNames) > 0 || len(c.config.ProtectedPatterns) > 0) {
+		name, err := c.resolveObjectName(approval.URL)
+		if err != nil {
+			return nil, fmt.Errorf("cannot check protection of %s %s, %s", approval.ObjectType, approval.URL, err.Error())
+		}
+		if c.isProtected(approval.ObjectName) || c.isProtected(name) {
+			return nil, fmt.Errorf("refusing to %s %s %s, %w", approval.Operation, approval.ObjectType, name, ErrProtectedObject)
+		}
+		approval.ObjectName = name
+	}
+
+	policy := c.config.Approval
+
+	if c.plan != nil && policy.Mode == ApproveCallback {
+		approved := policy.Approve != nil && policy.Approve(approval)
+		return c.sendOperation(approval, body, approved)
+	}
+
+	response, err := c.sendOperation(approval, body, policy.Mode == ApproveAlways)
+	if err != nil || policy.Mode == ApproveAlways {
+		return response, err
+	}
+
+	code, message := responseAPIError(response)
+	if code != APIErrorApprovalRequired {
+		return response, nil
+	}
+	approval.Message = message
+
+	if policy.Mode == ApproveCallback && policy.Approve != nil && policy.Approve(approval) {
+		c.infof("Approved %s of %s %s: %s", approval.Operation, approval.ObjectType, approval.ObjectName, message)
+		return c.sendOperation(approval, body, true)
+	}
+
+	return nil, fmt.Errorf("%s of %s %s requires approval: %s, %w", approval.Operation, approval.ObjectType, approval.ObjectName, message, ErrApprovalDenied)
+}
+
+//sendOperation sends operation request, approved operations carry approved flag
+func (c *Client) sendOperation(approval ApprovalRequest, body interface{}, approved bool) (*resty.Response, error) {
+
+	request := c.request()
+	if body != nil {
+		request.SetBody(body)
+	}
+	if approved {
+		request.SetQueryParam("approved", "true")
+	}
+
+	return request.Execute(approval.Method, approval.URL)
+}
+
+//responseAPIError returns code and message of API error carried by response
+func responseAPIError(response *resty.Response) (code string, message string) {
+
+	var apiresponse APIResponse
+	if err := json.Unmarshal(response.Body(), &apiresponse); err != nil {
+		return "", ""
+	}
+
+	return apiresponse.apiError()
+}
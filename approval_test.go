@@ -0,0 +1,58 @@
+package infinibox
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestDeleteChecksProtectionOfNameReportedByIBOX(t *testing.T) {
+
+	names := map[int64]string{5: "prod-db", 6: "scratch", 7: "renamed"}
+
+	tests := []struct {
+		name      string
+		id        int64
+		passed    string
+		protected bool
+	}{
+		{name: "protected ID with mismatched name", id: 5, passed: "anything", protected: true},
+		{name: "protected ID with empty name", id: 5, passed: "", protected: true},
+		{name: "protected ID with its name", id: 5, passed: "prod-db", protected: true},
+		{name: "stale protected name of unprotected ID", id: 7, passed: "prod-db", protected: true},
+		{name: "unprotected ID with empty name", id: 6, passed: "", protected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			ibox := newTestIBOX(t)
+			for id, name := range names {
+				volume := Volume{ID: id, Name: name}
+				path := fmt.Sprintf("/api/rest/volumes/%d", id)
+				ibox.handle(http.MethodGet, path, func(w http.ResponseWriter, r *http.Request) {
+					writeResult(w, volume)
+				})
+				ibox.handle(http.MethodDelete, path, func(w http.ResponseWriter, r *http.Request) {
+					writeResult(w, volume)
+				})
+			}
+			client := ibox.client(t, Config{ProtectedNames: []string{"prod-db"}})
+
+			err := (&Volume{ID: tt.id, Name: tt.passed}).Delete(client)
+
+			deletes := ibox.count(http.MethodDelete, fmt.Sprintf("/api/rest/volumes/%d", tt.id))
+			if tt.protected {
+				//Delete does not wrap errors, refusal is recognized by its message
+				if err == nil || !strings.Contains(err.Error(), ErrProtectedObject.Error()) || deletes != 0 {
+					t.Fatalf("error %v and %d deletes, want ErrProtectedObject and no delete", err, deletes)
+				}
+				return
+			}
+			if err != nil || deletes != 1 {
+				t.Fatalf("error %v and %d deletes, want volume deleted", err, deletes)
+			}
+		})
+	}
+}
@@ -0,0 +1,207 @@
+package infinibox
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+//AuditRecord represents single mutating request performed through client, Before holds
+//deleted object or object fetched before update, After holds object returned by IBOX
+type AuditRecord struct {
+	Time       time.Time       `json:"time"`
+	URL        string          `json:"url"`
+	User       string          `json:"user"`
+	Tenant     string          `json:"tenant,omitempty"`
+	Operation  string          `json:"operation"`
+	ObjectType string          `json:"object_type"`
+	ObjectID   string          `json:"object_id,omitempty"`
+	RequestID  string          `json:"request_id,omitempty"`
+	Approved   bool            `json:"approved"`
+	Request    json.RawMessage `json:"request,omitempty"`
+	Before     json.RawMessage `json:"before,omitempty"`
+	After      json.RawMessage `json:"after,omitempty"`
+	Status     int             `json:"status"`
+	Error      string          `json:"error,omitempty"`
+}
+
+//AuditSink receives audit records
+type AuditSink interface {
+	WriteAudit(record AuditRecord) error
+}
+
+//AuditFunc adapts function to AuditSink
+type AuditFunc func(record AuditRecord) error
+
+//WriteAudit calls f with record
+func (f AuditFunc) WriteAudit(record AuditRecord) error {
+	return f(record)
+}
+
+//AuditWriter writes audit records as JSON lines
+type AuditWriter struct {
+	mu     sync.Mutex
+	w      io.Writer
+	closer io.Closer
+}
+
+//NewAuditWriter returns sink writing JSON lines to w
+func NewAuditWriter(w io.Writer) *AuditWriter {
+	return &AuditWriter{w: w}
+}
+
+//OpenAuditFile returns sink appending JSON lines to file at path, file is created when missing
+func OpenAuditFile(path string) (*AuditWriter, error) {
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AuditWriter{w: file, closer: file}, nil
+}
+
+//WriteAudit writes record as single JSON line
+func (a *AuditWriter) WriteAudit(record AuditRecord) error {
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	_, err = a.w.Write(append(line, '\n'))
+	return err
+}
+
+//Close closes audit file, writers passed to NewAuditWriter are left open
+func (a *AuditWriter) Close() error {
+	if a.closer == nil {
+		return nil
+	}
+	return a.closer.Close()
+}
+
+//auditTransport passes record of every mutating request of RestClient to client audit sink
+type auditTransport struct {
+	base   http.RoundTripper
+	client *Client
+}
+
+func (t *auditTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+
+	e := parseEndpoint(req.URL.String())
+	if !isMutating(req.Method, e.template) {
+		return t.base.RoundTrip(req)
+	}
+
+	record := AuditRecord{
+		Time:       time.Now().UTC(),
+		URL:        t.client.config.URL,
+		User:       t.client.config.Username,
+		Tenant:     req.Header.Get("X-INFINIDAT-TENANT-ID"),
+		Operation:  req.Method + " " + e.template,
+		ObjectType: e.objectType,
+		ObjectID:   e.objectID,
+		RequestID:  req.Header.Get(RequestIDHeader),
+		Approved:   req.URL.Query().Get("approved") == "true",
+	}
+
+	if req.Body != nil {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req = req.Clone(req.Context())
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		record.Request = redactJSON(body)
+	}
+
+	if t.client.config.AuditCaptureBefore && req.Method != http.MethodPost && e.objectID != "" {
+		record.Before = t.fetch(req)
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		record.Error = err.Error()
+		t.write(record)
+		return nil, err
+	}
+	record.Status = resp.StatusCode
+
+	body, readErr := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if readErr != nil {
+		record.Error = readErr.Error()
+		t.write(record)
+		return resp, nil
+	}
+
+	var apiresponse APIResponse
+	if json.Unmarshal(body, &apiresponse) == nil {
+		if apiresponse.APIError != nil {
+			code, message := apiresponse.apiError()
+			record.Error = code + ": " + message
+		} else if apiresponse.APIResult != nil {
+			if req.Method == http.MethodDelete && record.Before == nil {
+				record.Before = redactJSON(*apiresponse.APIResult)
+			} else if req.Method != http.MethodDelete {
+				record.After = redactJSON(*apiresponse.APIResult)
+			}
+		}
+	}
+
+	t.write(record)
+
+	return resp, nil
+}
+
+//fetch returns current state of object addressed by request, nil when it cannot be fetched
+func (t *auditTransport) fetch(req *http.Request) json.RawMessage {
+
+	target := *req.URL
+	target.RawQuery = ""
+
+	get, err := http.NewRequestWithContext(req.Context(), http.MethodGet, target.String(), nil)
+	if err != nil {
+		return nil
+	}
+	get.Header = req.Header.Clone()
+
+	resp, err := t.base.RoundTrip(get)
+	if err != nil {
+		return nil
+	}
+	defer resp.Body.Close()
+
+	var apiresponse APIResponse
+	if err := json.NewDecoder(resp.Body).Decode(&apiresponse); err != nil || apiresponse.APIResult == nil {
+		return nil
+	}
+
+	return redactJSON(*apiresponse.APIResult)
+}
+
+//write passes record to audit sink, sink failures are logged
+func (t *auditTransport) write(record AuditRecord) {
+	if err := t.client.config.Audit.WriteAudit(record); err != nil {
+		t.client.errorf("unable to write audit record of %s, %s", record.Operation, err.Error())
+	}
+}
+
+//redactJSON masks secrets in JSON document, invalid JSON is dropped
+func redactJSON(document []byte) json.RawMessage {
+	redacted := []byte(redact(string(document)))
+	if !json.Valid(redacted) {
+		return nil
+	}
+	return json.RawMessage(redacted)
+}
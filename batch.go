@@ -0,0 +1,208 @@
+package infinibox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+//BatchMode selects how batch handles failed operations
+type BatchMode int
+
+//Batch modes
+const (
+	//BatchBestEffort runs every operation regardless of failures
+	BatchBestEffort BatchMode = iota
+	//BatchStopOnError skips operations not started yet once any operation failed
+	BatchStopOnError
+)
+
+//defaultBatchWorkers is worker count of batch created with non positive workers
+const defaultBatchWorkers = 4
+
+//BatchOperation represents single batch item, Run receives client scoped to batch context
+type BatchOperation struct {
+	Name string
+	Run  func(client *Client) (interface{}, error)
+}
+
+//BatchResult represents outcome of single batch operation
+type BatchResult struct {
+	Index   int
+	Name    string
+	Result  interface{}
+	Err     error
+	Skipped bool
+}
+
+//BatchReport represents per operation results of batch in operations order
+type BatchReport struct {
+	Results   []BatchResult
+	Succeeded int
+	Failed    int
+	Skipped   int
+}
+
+//Batch runs operations with bounded parallelism
+type Batch struct {
+	Workers    int
+	Mode       BatchMode
+	Operations []BatchOperation
+}
+
+//NewBatch returns empty batch running operations on workers goroutines
+func NewBatch(workers int, mode BatchMode) *Batch {
+	if workers <= 0 {
+		workers = defaultBatchWorkers
+	}
+	return &Batch{Workers: workers, Mode: mode}
+}
+
+//Add appends operation to batch
+func (b *Batch) Add(operation BatchOperation) *Batch {
+	b.Operations = append(b.Operations, operation)
+	return b
+}
+
+//Run executes batch operations and waits for started operations to finish, operations
+//not started before ctx is cancelled or before first failure in BatchStopOnError mode are skipped
+func (b *Batch) Run(ctx context.Context, client *Client) *BatchReport {
+
+	client.debugf("Running batch of %d operations on %d workers", len(b.Operations), b.Workers)
+
+	scoped := client.WithContext(ctx)
+	report := &BatchReport{Results: make([]BatchResult, len(b.Operations))}
+
+	workers := b.Workers
+	if workers <= 0 {
+		workers = defaultBatchWorkers
+	}
+
+	stop := make(chan struct{})
+	var stopOnce sync.Once
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				operation := b.Operations[i]
+				if stopped(stop, ctx) {
+					report.Results[i] = BatchResult{Index: i, Name: operation.Name, Skipped: true}
+					continue
+				}
+				result, err := operation.Run(scoped)
+				report.Results[i] = BatchResult{Index: i, Name: operation.Name, Result: result, Err: err}
+				if err != nil && b.Mode == BatchStopOnError {
+					stopOnce.Do(func() { close(stop) })
+				}
+			}
+		}()
+	}
+
+	next := 0
+dispatch:
+	for ; next < len(b.Operations); next++ {
+		if stopped(stop, ctx) {
+			break
+		}
+		select {
+		case jobs <- next:
+		case <-stop:
+			break dispatch
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	for i := next; i < len(b.Operations); i++ {
+		report.Results[i] = BatchResult{Index: i, Name: b.Operations[i].Name, Skipped: true}
+	}
+
+	for _, result := range report.Results {
+		switch {
+		case result.Skipped:
+			report.Skipped++
+		case result.Err != nil:
+			report.Failed++
+		default:
+			report.Succeeded++
+		}
+	}
+
+	client.debugf("Batch finished, %d succeeded, %d failed, %d skipped", report.Succeeded, report.Failed, report.Skipped)
+
+	return report
+}
+
+//stopped reports whether stop is closed or ctx is cancelled without blocking, select picks ready
+//cases randomly so dispatcher and workers check it before every operation
+func stopped(stop <-chan struct{}, ctx context.Context) bool {
+	select {
+	case <-stop:
+		return true
+	case <-ctx.Done():
+		return true
+	default:
+		return false
+	}
+}
+
+//Err returns errors of failed operations joined, nil when no operation failed
+func (r *BatchReport) Err() error {
+
+	var errs []error
+	for _, result := range r.Results {
+		if result.Err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", result.Name, result.Err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+//DeleteVolumeOperation returns batch operation deleting volume
+func DeleteVolumeOperation(v *Volume) BatchOperation {
+	return BatchOperation{
+		Name: fmt.Sprintf("delete volume %s", v.Name),
+		Run: func(client *Client) (interface{}, error) {
+			return v, v.Delete(client)
+		},
+	}
+}
+
+//SnapshotVolumeOperation returns batch operation creating volume snapshot, result is snapshot volume
+func SnapshotVolumeOperation(v *Volume, name string) BatchOperation {
+	return BatchOperation{
+		Name: fmt.Sprintf("snapshot volume %s", v.Name),
+		Run: func(client *Client) (interface{}, error) {
+			return v.Snapshot(client, name)
+		},
+	}
+}
+
+//AddLUNOperation returns batch operation mapping lun to host, result is mapped lun
+func AddLUNOperation(h *Host, lun *Lun) BatchOperation {
+	return BatchOperation{
+		Name: fmt.Sprintf("map volume ID %d to host %s", lun.VolumeID, h.Name),
+		Run: func(client *Client) (interface{}, error) {
+			return lun, h.AddLUN(client, lun)
+		},
+	}
+}
+
+//MapToHostOperation returns batch operation mapping volume to host on first free lun, result is mapped lun
+func MapToHostOperation(v *Volume, h *Host) BatchOperation {
+	return BatchOperation{
+		Name: fmt.Sprintf("map volume %s to host %s", v.Name, h.Name),
+		Run: func(client *Client) (interface{}, error) {
+			return v.MapToHost(client, h, firstAssignableLun)
+		},
+	}
+}
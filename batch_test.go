@@ -0,0 +1,83 @@
+package infinibox
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+)
+
+func TestBatchRun(t *testing.T) {
+
+	client, err := NewClient(&Config{URL: "http://127.0.0.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		mode      BatchMode
+		failing   int
+		ran       int32
+		succeeded int
+		failed    int
+		skipped   int
+	}{
+		{name: "stop on error skips after first failure", mode: BatchStopOnError, failing: 0, ran: 1, succeeded: 0, failed: 1, skipped: 4},
+		{name: "stop on error runs operations before failure", mode: BatchStopOnError, failing: 2, ran: 3, succeeded: 2, failed: 1, skipped: 2},
+		{name: "best effort runs every operation", mode: BatchBestEffort, failing: 0, ran: 5, succeeded: 4, failed: 1, skipped: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			//select picks ready cases randomly, repeat so lost stop checks show up
+			for run := 0; run < 100; run++ {
+				var ran int32
+				batch := NewBatch(1, tt.mode)
+				for i := 0; i < 5; i++ {
+					i := i
+					batch.Add(BatchOperation{Name: "operation", Run: func(*Client) (interface{}, error) {
+						atomic.AddInt32(&ran, 1)
+						if i == tt.failing {
+							return nil, errors.New("failed")
+						}
+						return i, nil
+					}})
+				}
+
+				report := batch.Run(context.Background(), client)
+
+				if ran != tt.ran || report.Succeeded != tt.succeeded || report.Failed != tt.failed || report.Skipped != tt.skipped {
+					t.Fatalf("run %d: ran %d, report %d succeeded %d failed %d skipped, want ran %d, %d succeeded %d failed %d skipped",
+						run, ran, report.Succeeded, report.Failed, report.Skipped, tt.ran, tt.succeeded, tt.failed, tt.skipped)
+				}
+			}
+		})
+	}
+}
+
+func TestBatchRunCancelled(t *testing.T) {
+
+	client, err := NewClient(&Config{URL: "http://127.0.0.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var ran int32
+	batch := NewBatch(1, BatchBestEffort)
+	for i := 0; i < 3; i++ {
+		batch.Add(BatchOperation{Name: "operation", Run: func(*Client) (interface{}, error) {
+			atomic.AddInt32(&ran, 1)
+			return nil, nil
+		}})
+	}
+
+	report := batch.Run(ctx, client)
+
+	if ran != 0 || report.Skipped != 3 {
+		t.Fatalf("ran %d operations and skipped %d after cancel, want 0 and 3", ran, report.Skipped)
+	}
+}
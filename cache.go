@@ -0,0 +1,189 @@
+package infinibox
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+//Collections of cached name lookups
+const (
+	cacheVolumes = "volumes"
+	cachePools   = "pools"
+	cacheHosts   = "hosts"
+	cacheTenants = "tenants"
+)
+
+//CacheStats represents name lookup cache counters
+type CacheStats struct {
+	Hits          uint64
+	NegativeHits  uint64
+	Misses        uint64
+	Invalidations uint64
+	Entries       int
+}
+
+//lookupCache keeps objects found by name lookups, including not found results, for ttl, hits are
+//served without requests so changes made outside of client are seen only after entries expire,
+//every mutating request sent by client drops all entries
+type lookupCache struct {
+	ttl        time.Duration
+	mu         sync.Mutex
+	entries    map[string]cacheEntry
+	generation uint64
+	stats      CacheStats
+}
+
+//cacheEntry holds JSON encoded object so every hit returns its own copy
+type cacheEntry struct {
+	value   []byte
+	found   bool
+	expires time.Time
+}
+
+//newLookupCache returns cache with provided ttl, nil when ttl disables caching
+func newLookupCache(ttl time.Duration) *lookupCache {
+	if ttl <= 0 {
+		return nil
+	}
+	return &lookupCache{ttl: ttl, entries: map[string]cacheEntry{}}
+}
+
+func (lc *lookupCache) get(key string) (value []byte, found bool, ok bool) {
+
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	entry, ok := lc.entries[key]
+	if ok && time.Now().After(entry.expires) {
+		delete(lc.entries, key)
+		ok = false
+	}
+
+	switch {
+	case !ok:
+		lc.stats.Misses++
+	case !entry.found:
+		lc.stats.NegativeHits++
+	default:
+		lc.stats.Hits++
+	}
+
+	return entry.value, entry.found, ok
+}
+
+//begin returns generation lookup result is put with
+func (lc *lookupCache) begin() uint64 {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	return lc.generation
+}
+
+//put stores lookup result unless cache was invalidated since lookup began at generation
+func (lc *lookupCache) put(key string, value []byte, found bool, generation uint64) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	if generation != lc.generation {
+		return
+	}
+	lc.entries[key] = cacheEntry{value: value, found: found, expires: time.Now().Add(lc.ttl)}
+}
+
+//invalidate drops every entry, lookups in flight do not store their results
+func (lc *lookupCache) invalidate() {
+
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	lc.generation++
+	lc.stats.Invalidations += uint64(len(lc.entries))
+	lc.entries = map[string]cacheEntry{}
+}
+
+//cacheKey returns lookup cache key of name in collection, tenants are looked up across tenants
+func (c *Client) cacheKey(collection string, name string) string {
+	if collection == cacheTenants {
+		return collection + "//" + name
+	}
+	return collection + "/" + c.config.tenant + "/" + name
+}
+
+//cachedLookup returns copy of object cached by name in collection, on cache miss it calls lookup and caches its result
+func cachedLookup[T any](c *Client, collection string, name string, lookup func() (*T, bool, error)) (*T, bool, error) {
+
+	if c.cache == nil {
+		return lookup()
+	}
+
+	key := c.cacheKey(collection, name)
+
+	if value, found, ok := c.cache.get(key); ok {
+		if !found {
+			return nil, false, nil
+		}
+		var object T
+		if err := json.Unmarshal(value, &object); err == nil {
+			return &object, true, nil
+		}
+	}
+
+	generation := c.cache.begin()
+
+	object, found, err := lookup()
+	if err != nil {
+		return nil, false, err
+	}
+
+	if !found {
+		c.cache.put(key, nil, false, generation)
+		return nil, false, nil
+	}
+
+	if value, err := json.Marshal(object); err == nil {
+		c.cache.put(key, value, true, generation)
+	}
+
+	return object, true, nil
+}
+
+//cacheInvalidatingTransport drops cached lookups after every mutating request, requests which
+//failed are included as IBOX may have applied them
+type cacheInvalidatingTransport struct {
+	base  http.RoundTripper
+	cache *lookupCache
+}
+
+func (t *cacheInvalidatingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+
+	resp, err := t.base.RoundTrip(req)
+
+	if isMutating(req.Method, parseEndpoint(req.URL.String()).template) {
+		t.cache.invalidate()
+	}
+
+	return resp, err
+}
+
+//CacheStats returns name lookup cache counters, zero stats when cache is disabled
+func (c *Client) CacheStats() CacheStats {
+
+	if c.cache == nil {
+		return CacheStats{}
+	}
+
+	c.cache.mu.Lock()
+	defer c.cache.mu.Unlock()
+
+	stats := c.cache.stats
+	stats.Entries = len(c.cache.entries)
+
+	return stats
+}
+
+//FlushCache drops every cached name lookup, changes made outside of client are seen by next lookups
+func (c *Client) FlushCache() {
+	if c.cache != nil {
+		c.cache.invalidate()
+	}
+}
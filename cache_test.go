@@ -0,0 +1,158 @@
+package infinibox
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+//volumesIBOX serves volume lookups by name and renames from volumes keyed by ID
+func volumesIBOX(t *testing.T, volumes map[int64]*Volume) *testIBOX {
+
+	var mu sync.Mutex
+	ibox := newTestIBOX(t)
+
+	ibox.handle(http.MethodGet, "/api/rest/volumes", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		name := strings.TrimPrefix(r.URL.Query().Get("name"), "eq:")
+		found := []Volume{}
+		for _, volume := range volumes {
+			if volume.Name == name {
+				found = append(found, *volume)
+			}
+		}
+		writeResult(w, found)
+	})
+	ibox.handle(http.MethodPut, "/api/rest/volumes/1", func(w http.ResponseWriter, r *http.Request) {
+		body := readBody(t, r)
+		mu.Lock()
+		defer mu.Unlock()
+		volumes[1].Name = body["name"].(string)
+		writeResult(w, *volumes[1])
+	})
+
+	return ibox
+}
+
+func TestCachedLookup(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		ttl      time.Duration
+		between  func(t *testing.T, client *Client, volumes map[int64]*Volume, volume *Volume)
+		lookup   string
+		found    bool
+		requests int
+		stats    CacheStats
+	}{
+		{
+			name:     "hit is served without request",
+			ttl:      time.Minute,
+			between:  func(*testing.T, *Client, map[int64]*Volume, *Volume) {},
+			lookup:   "a",
+			found:    true,
+			requests: 1,
+			stats:    CacheStats{Hits: 1, Misses: 1, Entries: 1},
+		},
+		{
+			name:     "expired entry is looked up again",
+			ttl:      20 * time.Millisecond,
+			between:  func(*testing.T, *Client, map[int64]*Volume, *Volume) { time.Sleep(50 * time.Millisecond) },
+			lookup:   "a",
+			found:    true,
+			requests: 2,
+			stats:    CacheStats{Misses: 2, Entries: 1},
+		},
+		{
+			name: "rename by client invalidates cached name",
+			ttl:  time.Minute,
+			between: func(t *testing.T, client *Client, volumes map[int64]*Volume, volume *Volume) {
+				if err := volume.UpdateName(client, "b"); err != nil {
+					t.Fatal(err)
+				}
+			},
+			lookup:   "a",
+			found:    false,
+			requests: 2,
+			stats:    CacheStats{Misses: 2, Invalidations: 1, Entries: 1},
+		},
+		{
+			name: "renamed volume is found by new name",
+			ttl:  time.Minute,
+			between: func(t *testing.T, client *Client, volumes map[int64]*Volume, volume *Volume) {
+				if err := volume.UpdateName(client, "b"); err != nil {
+					t.Fatal(err)
+				}
+			},
+			lookup:   "b",
+			found:    true,
+			requests: 2,
+			stats:    CacheStats{Misses: 2, Invalidations: 1, Entries: 1},
+		},
+		{
+			name: "external rename is seen after flush",
+			ttl:  time.Minute,
+			between: func(t *testing.T, client *Client, volumes map[int64]*Volume, volume *Volume) {
+				volumes[1].Name = "b"
+				client.FlushCache()
+			},
+			lookup:   "a",
+			found:    false,
+			requests: 2,
+			stats:    CacheStats{Misses: 2, Invalidations: 1, Entries: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			volumes := map[int64]*Volume{1: {ID: 1, Name: "a", Size: 1 << 30}}
+			ibox := volumesIBOX(t, volumes)
+			client := ibox.client(t, Config{LookupCacheTTL: tt.ttl})
+
+			volume, err := client.GetVolumeByName("a")
+			if err != nil {
+				t.Fatal(err)
+			}
+			//callers own returned objects, changing them must not change cached copy
+			volume.Size = 0
+
+			tt.between(t, client, volumes, volume)
+
+			looked, err := client.GetVolumeByName(tt.lookup)
+			if tt.found {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if looked.Name != tt.lookup || looked.Size != 1<<30 {
+					t.Fatalf("got volume %s of size %d, want %s of size %d", looked.Name, looked.Size, tt.lookup, 1<<30)
+				}
+			} else if err == nil {
+				t.Fatalf("got volume %s, want not found", looked.Name)
+			}
+
+			if n := ibox.count(http.MethodGet, "/api/rest/volumes"); n != tt.requests {
+				t.Fatalf("sent %d lookups, want %d", n, tt.requests)
+			}
+			if stats := client.CacheStats(); stats != tt.stats {
+				t.Fatalf("stats %+v, want %+v", stats, tt.stats)
+			}
+		})
+	}
+}
+
+func TestCachedLookupSkipsResultOfLookupInvalidatedInFlight(t *testing.T) {
+
+	cache := newLookupCache(time.Minute)
+
+	generation := cache.begin()
+	cache.invalidate()
+	cache.put("volumes//a", []byte(`{"id":1}`), true, generation)
+
+	if _, _, ok := cache.get("volumes//a"); ok {
+		t.Fatal("lookup begun before invalidation was cached")
+	}
+}
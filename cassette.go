@@ -0,0 +1,366 @@
+package infinibox
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+//CassetteMode selects whether cassette records or replays interactions
+type CassetteMode int
+
+//Cassette modes
+const (
+	//CassetteRecord sends requests to IBOX and records interactions
+	CassetteRecord CassetteMode = iota
+	//CassetteReplay answers requests from recorded interactions without contacting IBOX
+	CassetteReplay
+)
+
+//CassetteVolatileFields are scrubbed from recorded bodies and ignored in query when matching requests
+var CassetteVolatileFields = map[string]bool{
+	"created_at":                 true,
+	"updated_at":                 true,
+	"timestamp":                  true,
+	"time":                       true,
+	"uptime":                     true,
+	"last_heartbeat":             true,
+	"lock_expires_at":            true,
+	"end_timestamp_milliseconds": true,
+}
+
+//CassetteVolatilePatterns match values generated per run in request path, query and body, like uuid
+//suffixed auto snapshot names, matched values are normalized so replayed requests match recorded ones
+var CassetteVolatilePatterns = []*regexp.Regexp{
+	regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`),
+}
+
+//cassetteVolatileValue replaces values matched by CassetteVolatilePatterns
+const cassetteVolatileValue = "{volatile}"
+
+//cassetteScrubbedHeaders are not recorded from responses
+var cassetteScrubbedHeaders = map[string]bool{
+	"Set-Cookie":     true,
+	"Date":           true,
+	"Content-Length": true,
+}
+
+//CassetteRequest represents recorded request matched on method, path, query and body
+type CassetteRequest struct {
+	Method string          `json:"method"`
+	Path   string          `json:"path"`
+	Query  string          `json:"query,omitempty"`
+	Body   json.RawMessage `json:"body,omitempty"`
+}
+
+//CassetteResponse represents recorded response
+type CassetteResponse struct {
+	Status int                 `json:"status"`
+	Header map[string][]string `json:"header,omitempty"`
+	Body   json.RawMessage     `json:"body,omitempty"`
+}
+
+//Interaction represents recorded request and response pair
+type Interaction struct {
+	Request  CassetteRequest  `json:"request"`
+	Response CassetteResponse `json:"response"`
+}
+
+//Cassette holds interactions recorded against IBOX for replaying them offline
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+
+	path string
+	mode CassetteMode
+	mu   sync.Mutex
+	used []bool
+}
+
+//NewCassette returns cassette stored at path, cassette is loaded from path in replay mode
+func NewCassette(path string, mode CassetteMode) (*Cassette, error) {
+
+	cassette := &Cassette{path: path, mode: mode}
+	if mode != CassetteReplay {
+		return cassette, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read cassette %s, %s", path, err.Error())
+	}
+
+	if err := json.Unmarshal(data, cassette); err != nil {
+		return nil, fmt.Errorf("unable to decode cassette %s, %s", path, err.Error())
+	}
+	cassette.used = make([]bool, len(cassette.Interactions))
+
+	for i, interaction := range cassette.Interactions {
+		var compacted bytes.Buffer
+		if json.Compact(&compacted, interaction.Request.Body) == nil {
+			interaction.Request.Body = compacted.Bytes()
+		}
+		cassette.Interactions[i].Request = interaction.Request.normalized()
+	}
+
+	return cassette, nil
+}
+
+//Save writes recorded interactions to cassette path
+func (c *Cassette) Save() error {
+
+	c.mu.Lock()
+	data, err := json.MarshalIndent(c, "", "  ")
+	c.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(c.path, append(data, '\n'), 0600)
+}
+
+//record appends interaction
+func (c *Cassette) record(interaction Interaction) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Interactions = append(c.Interactions, interaction)
+	c.used = append(c.used, true)
+}
+
+//match returns first unused interaction matching request, interaction matched last is
+//returned again when every matching interaction was used
+func (c *Cassette) match(request CassetteRequest) (Interaction, bool) {
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	last := -1
+	for i, interaction := range c.Interactions {
+		if !interaction.Request.matches(request) {
+			continue
+		}
+		if !c.used[i] {
+			c.used[i] = true
+			return interaction, true
+		}
+		last = i
+	}
+
+	if last < 0 {
+		return Interaction{}, false
+	}
+	return c.Interactions[last], true
+}
+
+func (r CassetteRequest) matches(other CassetteRequest) bool {
+	return r.Method == other.Method && r.Path == other.Path && r.Query == other.Query && bytes.Equal(r.Body, other.Body)
+}
+
+//normalized returns request with per run values replaced, requests are recorded and matched normalized
+func (r CassetteRequest) normalized() CassetteRequest {
+
+	r.Path = normalizeVolatile(r.Path)
+
+	if query, err := url.ParseQuery(r.Query); err == nil {
+		for key, values := range query {
+			for i, value := range values {
+				values[i] = normalizeVolatile(value)
+			}
+			query[key] = values
+		}
+		r.Query = query.Encode()
+	}
+
+	var document interface{}
+	decoder := json.NewDecoder(bytes.NewReader(r.Body))
+	decoder.UseNumber()
+	if len(r.Body) > 0 && decoder.Decode(&document) == nil {
+		if body, err := json.Marshal(normalizeValue(document)); err == nil {
+			r.Body = body
+		}
+	}
+
+	return r
+}
+
+//normalizeValue replaces per run values in strings of decoded JSON document
+func normalizeValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return normalizeVolatile(v)
+	case map[string]interface{}:
+		for key, field := range v {
+			v[key] = normalizeValue(field)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = normalizeValue(item)
+		}
+	}
+	return value
+}
+
+//normalizeVolatile replaces values matched by CassetteVolatilePatterns in s
+func normalizeVolatile(s string) string {
+	for _, pattern := range CassetteVolatilePatterns {
+		s = pattern.ReplaceAllString(s, cassetteVolatileValue)
+	}
+	return s
+}
+
+//cassetteTransport records interactions of RestClient or replays them from cassette
+type cassetteTransport struct {
+	base     http.RoundTripper
+	cassette *Cassette
+}
+
+func (t *cassetteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	request := CassetteRequest{
+		Method: req.Method,
+		Path:   "/" + strings.TrimLeft(req.URL.Path, "/"),
+		Query:  scrubQuery(req.URL.Query()),
+		Body:   scrubBody(body),
+	}.normalized()
+
+	if t.cassette.mode == CassetteReplay {
+		interaction, ok := t.cassette.match(request)
+		if !ok {
+			return nil, fmt.Errorf("cassette %s has no interaction for %s %s?%s", t.cassette.path, request.Method, request.Path, request.Query)
+		}
+		return interaction.Response.httpResponse(req), nil
+	}
+
+	req = req.Clone(req.Context())
+	req.Body = io.NopCloser(bytes.NewReader(body))
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	header := map[string][]string{}
+	for key, values := range resp.Header {
+		if !cassetteScrubbedHeaders[http.CanonicalHeaderKey(key)] {
+			header[key] = values
+		}
+	}
+
+	t.cassette.record(Interaction{
+		Request:  request,
+		Response: CassetteResponse{Status: resp.StatusCode, Header: header, Body: scrubBody(respBody)},
+	})
+
+	return resp, nil
+}
+
+//httpResponse builds response to req from recorded response
+func (r CassetteResponse) httpResponse(req *http.Request) *http.Response {
+
+	header := http.Header{}
+	for key, values := range r.Header {
+		header[key] = append([]string{}, values...)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", r.Status, http.StatusText(r.Status)),
+		StatusCode:    r.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(r.Body)),
+		ContentLength: int64(len(r.Body)),
+		Request:       req,
+	}
+}
+
+//scrubQuery returns encoded query without volatile parameters, parameters are sorted by name
+func scrubQuery(query url.Values) string {
+	for key := range query {
+		if CassetteVolatileFields[key] {
+			query.Del(key)
+		}
+	}
+	return query.Encode()
+}
+
+//scrubBody returns JSON body with secrets redacted and volatile fields zeroed, keys are sorted
+//so equal documents are recorded equally, non JSON bodies are kept as JSON string
+func scrubBody(body []byte) json.RawMessage {
+
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+
+	var document interface{}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&document); err != nil {
+		quoted, _ := json.Marshal(string(body))
+		return quoted
+	}
+
+	scrubbed, err := json.Marshal(scrubValue(document))
+	if err != nil {
+		return nil
+	}
+
+	return redactJSON(scrubbed)
+}
+
+//scrubValue zeroes volatile fields of decoded JSON document
+func scrubValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if CassetteVolatileFields[key] {
+				v[key] = zeroValue(field)
+			} else {
+				v[key] = scrubValue(field)
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = scrubValue(item)
+		}
+	}
+	return value
+}
+
+//zeroValue returns zero value of JSON value type
+func zeroValue(value interface{}) interface{} {
+	switch value.(type) {
+	case json.Number:
+		return json.Number("0")
+	case string:
+		return ""
+	case bool:
+		return false
+	default:
+		return nil
+	}
+}
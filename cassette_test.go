@@ -0,0 +1,136 @@
+package infinibox
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+//snapshotCassette is synthetic, it was written by hand in IBOX response format and not recorded from IBOX,
+//TestCassetteRecordReplay covers cassettes recorded by client
+const snapshotCassette = "testdata/snapshot.json"
+
+//snapshotVolume logs in, looks volume up and snapshots it with generated name as examples/cassette does
+func snapshotVolume(client *Client, volumename string) (*Volume, error) {
+
+	if err := client.Login(); err != nil {
+		return nil, err
+	}
+
+	volume, err := client.GetVolumeByName(volumename)
+	if err != nil {
+		return nil, err
+	}
+
+	return volume.Snapshot(client, "")
+}
+
+func TestCassetteReplay(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		volume   string
+		snapshot int64
+		wantErr  bool
+	}{
+		{name: "snapshot with generated name", volume: "example-volume", snapshot: 1002},
+		{name: "request missing from cassette", volume: "other-volume", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			//every run generates different snapshot name, replay must match it with recorded one
+			for run := 0; run < 2; run++ {
+				cassette, err := NewCassette(snapshotCassette, CassetteReplay)
+				if err != nil {
+					t.Fatal(err)
+				}
+				client, err := NewClient(&Config{URL: "https://ibox.example.com", Username: "admin", Password: "other", Cassette: cassette})
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				snapshot, err := snapshotVolume(client, tt.volume)
+				if tt.wantErr {
+					if err == nil {
+						t.Fatalf("replayed %s not in cassette", tt.volume)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				if snapshot.ID != tt.snapshot || !strings.HasPrefix(snapshot.Name, "auto-snapshot-") {
+					t.Fatalf("snapshot %d %s, want %d with generated name", snapshot.ID, snapshot.Name, tt.snapshot)
+				}
+			}
+		})
+	}
+}
+
+func TestCassetteRecordReplay(t *testing.T) {
+
+	ibox := newTestIBOX(t)
+	ibox.handle(http.MethodPost, "/api/rest/users/login", func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "JSESSIONID", Value: "session"})
+		writeResult(w, map[string]interface{}{"id": 1, "name": "admin"})
+	})
+	ibox.handle(http.MethodGet, "/api/rest/volumes", func(w http.ResponseWriter, r *http.Request) {
+		writeResult(w, []Volume{{ID: 11, Name: "data", Size: 1 << 30}})
+	})
+	ibox.handle(http.MethodPost, "/api/rest/volumes", func(w http.ResponseWriter, r *http.Request) {
+		body := readBody(t, r)
+		writeResult(w, Volume{ID: 12, Name: body["name"].(string), ParentID: 11, Type: "SNAPSHOT"})
+	})
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	recording, err := NewCassette(path, CassetteRecord)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorded, err := snapshotVolume(ibox.client(t, Config{Username: "admin", Password: "s3cret", Cassette: recording}), "data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := recording.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, leaked := range []string{"s3cret", "JSESSIONID"} {
+		if strings.Contains(string(data), leaked) {
+			t.Errorf("cassette contains %s", leaked)
+		}
+	}
+	//generated name is normalized in recorded request, response keeps name IBOX returned
+	generated := strings.TrimPrefix(recorded.Name, "auto-snapshot-")
+	if !strings.Contains(string(data), `"name": "auto-snapshot-`+cassetteVolatileValue+`"`) || strings.Count(string(data), generated) != 1 {
+		t.Errorf("generated snapshot name %s is not normalized in recorded request", recorded.Name)
+	}
+
+	replaying, err := NewCassette(path, CassetteReplay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewClient(&Config{URL: "https://ibox.example.com", Username: "admin", Password: "s3cret", Cassette: replaying})
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayed, err := snapshotVolume(client, "data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replayed.ID != 12 || replayed.ParentID != 11 {
+		t.Fatalf("replayed snapshot %d of %d, want 12 of 11", replayed.ID, replayed.ParentID)
+	}
+	if n := len(ibox.sent()); n != 3 {
+		t.Fatalf("IBOX received %d requests, want 3 recorded and none replayed", n)
+	}
+}
@@ -0,0 +1,227 @@
+package infinibox
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+//Host security methods supported by IBOX
+const (
+	SecurityMethodNone       = "NONE"
+	SecurityMethodChap       = "CHAP"
+	SecurityMethodMutualChap = "MUTUAL_CHAP"
+)
+
+//CHAP secret length limits and charset accepted by IBOX
+const (
+	ChapSecretMinLength = 12
+	ChapSecretMaxLength = 16
+	chapSecretCharset   = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+)
+
+//ChapCredentials represents host inbound and outbound CHAP credentials
+type ChapCredentials struct {
+	InboundUsername  string
+	InboundSecret    string
+	OutboundUsername string
+	OutboundSecret   string
+}
+
+//ChapRotateFunc is called with new credentials before they are applied on IBOX so node can
+//accept them while old secret is still valid, returning error aborts rotation
+type ChapRotateFunc func(host *Host, credentials ChapCredentials) error
+
+//ValidateSecurityMethod checks host security_method value
+func ValidateSecurityMethod(method string) error {
+	switch strings.ToUpper(method) {
+	case SecurityMethodNone, SecurityMethodChap, SecurityMethodMutualChap:
+		return nil
+	}
+	return fmt.Errorf("invalid security method %q, expected one of %s, %s, %s", method, SecurityMethodNone, SecurityMethodChap, SecurityMethodMutualChap)
+}
+
+//ValidateChapSecret checks CHAP secret length and charset
+func ValidateChapSecret(secret string) error {
+	if len(secret) < ChapSecretMinLength || len(secret) > ChapSecretMaxLength {
+		return fmt.Errorf("chap secret length must be between %d and %d characters", ChapSecretMinLength, ChapSecretMaxLength)
+	}
+	for _, r := range secret {
+		if !strings.ContainsRune(chapSecretCharset, r) {
+			return fmt.Errorf("chap secret contains unsupported character %q", r)
+		}
+	}
+	return nil
+}
+
+//GenerateChapSecret generates random CHAP secret of provided length
+func GenerateChapSecret(length int) (string, error) {
+	if length < ChapSecretMinLength || length > ChapSecretMaxLength {
+		return "", fmt.Errorf("chap secret length must be between %d and %d characters", ChapSecretMinLength, ChapSecretMaxLength)
+	}
+
+	max := big.NewInt(int64(len(chapSecretCharset)))
+	secret := make([]byte, length)
+	for i := range secret {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", fmt.Errorf("unable to generate chap secret, %s", err.Error())
+		}
+		secret[i] = chapSecretCharset[n.Int64()]
+	}
+
+	return string(secret), nil
+}
+
+//Validate checks CHAP credentials, outbound credentials are optional
+func (cc *ChapCredentials) Validate() error {
+	if cc.InboundUsername == "" {
+		return fmt.Errorf("chap inbound username is required")
+	}
+	if err := ValidateChapSecret(cc.InboundSecret); err != nil {
+		return fmt.Errorf("invalid chap inbound secret, %s", err.Error())
+	}
+	if cc.OutboundUsername == "" && cc.OutboundSecret == "" {
+		return nil
+	}
+	if cc.OutboundUsername == "" {
+		return fmt.Errorf("chap outbound username is required")
+	}
+	if err := ValidateChapSecret(cc.OutboundSecret); err != nil {
+		return fmt.Errorf("invalid chap outbound secret, %s", err.Error())
+	}
+	if cc.OutboundSecret == cc.InboundSecret {
+		return fmt.Errorf("chap inbound and outbound secrets must differ")
+	}
+	return nil
+}
+
+//SecurityMethod returns security method matching provided credentials
+func (cc *ChapCredentials) SecurityMethod() string {
+	if cc.OutboundUsername != "" {
+		return SecurityMethodMutualChap
+	}
+	return SecurityMethodChap
+}
+
+//chapAttributes builds host security attributes request body
+func (h *Host) chapAttributes() (map[string]interface{}, error) {
+
+	body := map[string]interface{}{}
+
+	if h.SecurityMethod != "" {
+		if err := ValidateSecurityMethod(h.SecurityMethod); err != nil {
+			return nil, err
+		}
+		body["security_method"] = strings.ToUpper(h.SecurityMethod)
+	}
+	if h.SecurityChapInboundUsername != "" {
+		body["security_chap_inbound_username"] = h.SecurityChapInboundUsername
+	}
+	if h.SecurityChapInboundSecret != "" {
+		body["security_chap_inbound_secret"] = h.SecurityChapInboundSecret
+	}
+	if h.SecurityChapOutboundUsername != "" {
+		body["security_chap_outbound_username"] = h.SecurityChapOutboundUsername
+	}
+	if h.SecurityChapOutboundSecret != "" {
+		body["security_chap_outbound_secret"] = h.SecurityChapOutboundSecret
+	}
+
+	return body, nil
+}
+
+//SetChap sets host CHAP or mutual CHAP credentials in single update
+func (h *Host) SetChap(client *Client, credentials ChapCredentials) (err error) {
+
+	client.debugf("Setting CHAP credentials for host %s", h.Name)
+
+	err = credentials.Validate()
+	if err != nil {
+		return fmt.Errorf("failed to set CHAP for host %s, %s", h.Name, err.Error())
+	}
+
+	body := map[string]interface{}{
+		"security_method":                credentials.SecurityMethod(),
+		"security_chap_inbound_username": credentials.InboundUsername,
+		"security_chap_inbound_secret":   credentials.InboundSecret,
+	}
+	if credentials.OutboundUsername != "" {
+		body["security_chap_outbound_username"] = credentials.OutboundUsername
+		body["security_chap_outbound_secret"] = credentials.OutboundSecret
+	}
+
+	err = h.updateAttributes(client, body)
+	if err != nil {
+		return fmt.Errorf("failed to set CHAP for host %s, %s", h.Name, err.Error())
+	}
+
+	client.debugf("Succesfully set %s for host %s", h.SecurityMethod, h.Name)
+
+	return nil
+}
+
+//ClearChap disables CHAP authentication for host
+func (h *Host) ClearChap(client *Client) (err error) {
+
+	client.debugf("Clearing CHAP for host %s", h.Name)
+
+	err = h.updateAttributes(client, map[string]interface{}{"security_method": SecurityMethodNone})
+	if err != nil {
+		return fmt.Errorf("failed to clear CHAP for host %s, %s", h.Name, err.Error())
+	}
+
+	client.debugf("Succesfully cleared CHAP for host %s", h.Name)
+
+	return nil
+}
+
+//RotateChap generates new secrets for host current CHAP usernames, calls onRotate so node iscsid
+//configuration can be updated while IBOX still accepts old secret and only then applies them on IBOX,
+//credentials are returned with error when applying fails so caller can roll node back
+func (h *Host) RotateChap(client *Client, onRotate ChapRotateFunc) (credentials *ChapCredentials, err error) {
+
+	client.debugf("Rotating CHAP secrets for host %s", h.Name)
+
+	current, err := h.Get(client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rotate CHAP for host %s, %s", h.Name, err.Error())
+	}
+
+	method := strings.ToUpper(current.SecurityMethod)
+	if method != SecurityMethodChap && method != SecurityMethodMutualChap {
+		return nil, fmt.Errorf("failed to rotate CHAP for host %s, security method is %s", h.Name, current.SecurityMethod)
+	}
+
+	credentials = &ChapCredentials{InboundUsername: current.SecurityChapInboundUsername}
+	credentials.InboundSecret, err = GenerateChapSecret(ChapSecretMaxLength)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rotate CHAP for host %s, %s", h.Name, err.Error())
+	}
+
+	if method == SecurityMethodMutualChap {
+		credentials.OutboundUsername = current.SecurityChapOutboundUsername
+		credentials.OutboundSecret, err = GenerateChapSecret(ChapSecretMaxLength)
+		if err != nil {
+			return nil, fmt.Errorf("failed to rotate CHAP for host %s, %s", h.Name, err.Error())
+		}
+	}
+
+	if onRotate != nil {
+		err = onRotate(h, *credentials)
+		if err != nil {
+			return nil, fmt.Errorf("failed to rotate CHAP for host %s, node update failed, %s", h.Name, err.Error())
+		}
+	}
+
+	err = h.SetChap(client, *credentials)
+	if err != nil {
+		client.errorf("CHAP secrets for host %s were updated on node but not on IBOX, %s", h.Name, err.Error())
+		return credentials, err
+	}
+
+	client.debugf("Succesfully rotated CHAP secrets for host %s", h.Name)
+
+	return credentials, nil
+}
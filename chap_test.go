@@ -0,0 +1,71 @@
+package infinibox
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestRotateChap(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		onRotate error
+		apply    bool
+		steps    []string
+		wantErr  bool
+	}{
+		{name: "node is updated before IBOX", apply: true, steps: []string{"node", "ibox"}},
+		{name: "failed node update leaves IBOX secret", onRotate: errors.New("iscsid"), steps: []string{"node"}, wantErr: true},
+		{name: "failed IBOX update returns credentials for rollback", apply: false, steps: []string{"node", "ibox"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			var mu sync.Mutex
+			steps := []string{}
+			step := func(name string) {
+				mu.Lock()
+				defer mu.Unlock()
+				steps = append(steps, name)
+			}
+
+			ibox := newTestIBOX(t)
+			ibox.handle(http.MethodGet, "/api/rest/hosts/7", func(w http.ResponseWriter, r *http.Request) {
+				writeResult(w, Host{ID: 7, Name: "node-1", SecurityMethod: SecurityMethodChap, SecurityChapInboundUsername: "node-1"})
+			})
+			ibox.handle(http.MethodPut, "/api/rest/hosts/7", func(w http.ResponseWriter, r *http.Request) {
+				step("ibox")
+				body := readBody(t, r)
+				if !tt.apply && tt.onRotate == nil {
+					writeAPIError(w, http.StatusBadRequest, "BAD_REQUEST", "rejected")
+					return
+				}
+				writeResult(w, Host{ID: 7, Name: "node-1", SecurityMethod: body["security_method"].(string)})
+			})
+			client := ibox.client(t, Config{})
+
+			host := &Host{ID: 7, Name: "node-1"}
+			credentials, err := host.RotateChap(client, func(host *Host, credentials ChapCredentials) error {
+				step("node")
+				if err := credentials.Validate(); err != nil {
+					t.Errorf("invalid rotated credentials, %s", err.Error())
+				}
+				return tt.onRotate
+			})
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RotateChap error %v, want error %t", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(steps, tt.steps) {
+				t.Fatalf("steps %v, want %v", steps, tt.steps)
+			}
+			if tt.onRotate == nil && (credentials == nil || credentials.InboundUsername != "node-1") {
+				t.Fatalf("credentials %+v, want credentials of node-1", credentials)
+			}
+		})
+	}
+}
@@ -1,12 +1,18 @@
 package infinibox
 
 import (
+	"context"
 	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"github.com/go-resty/resty"
-	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
 	"net/url"
+	"regexp"
+	"strconv"
+	"sync"
 	"time"
 )
 
@@ -15,8 +21,44 @@ type Config struct {
 	Username string
 	Password string
 	URL      string
+	TenantID int64
 	tenant   string
 	Debug    bool
+	Logger   Logger
+
+	//SystemSerial is serial number of expected system, Login fails when system reports other serial, zero skips check
+	SystemSerial int64
+
+	TracerProvider trace.TracerProvider
+	MeterProvider  metric.MeterProvider
+
+	//RateLimit caps requests per second with token bucket of RateBurst tokens, zero disables limiting
+	RateLimit float64
+	RateBurst int
+	//MaxInFlight caps concurrent requests per endpoint class
+	MaxInFlight map[EndpointClass]int
+
+	//LookupCacheTTL enables caching of objects found by volume, pool, host and tenant name lookups,
+	//cached objects may be stale by up to ttl when changed outside of client, every mutating request
+	//sent by client flushes cache, zero disables caching
+	LookupCacheTTL time.Duration
+
+	//DryRun records mutating requests into client plan instead of sending them
+	DryRun bool
+
+	//Approval decides operations IBOX asks to confirm, deletion of objects matching
+	//ProtectedNames or ProtectedPatterns is refused regardless of approval
+	Approval          ApprovalPolicy
+	ProtectedNames    []string
+	ProtectedPatterns []*regexp.Regexp
+
+	//Audit receives record of every mutating request, AuditCaptureBefore fetches
+	//updated and deleted objects before request is sent
+	Audit              AuditSink
+	AuditCaptureBefore bool
+
+	//Cassette records interactions with IBOX or replays recorded interactions
+	Cassette *Cassette
 }
 
 //APIError represents IBOX API response error struct
@@ -49,22 +91,68 @@ type APIResponse struct {
 type Client struct {
 	RestClient *resty.Client
 	config     *Config
+	lunLocks   *sync.Map
+	logger     Logger
+	telemetry  *telemetry
+	ctx        context.Context
+	cache      *lookupCache
+	plan       *Plan
 }
 
 //NewClient function generates new client instance
 func NewClient(config *Config) (*Client, error) {
-	restClient, err := restyBasicClient(config)
+	c := &Client{config: config, lunLocks: &sync.Map{}, logger: config.Logger, telemetry: newTelemetry(config), cache: newLookupCache(config.LookupCacheTTL)}
+	if config.DryRun {
+		c.plan = &Plan{}
+	}
+
+	restClient, err := restyBasicClient(config, c.newTransport())
 	if err != nil {
 		return nil, err
 	}
 	if restClient == nil {
 		return nil, err
 	}
-	c := &Client{RestClient: restClient, config: config}
+	if config.TenantID != 0 && config.tenant == "" {
+		config.tenant = fmt.Sprintf("%d", config.TenantID)
+	}
+	c.RestClient = restClient
+
+	restClient.SetLogger(&redactingWriter{client: c})
+	restClient.OnBeforeRequest(c.logRequest)
+	restClient.OnAfterResponse(c.logResponse)
+
+	c.debugf("Succesfully initialized infinibox client")
+
 	return c, nil
 }
 
-func restyBasicClient(config *Config) (*resty.Client, error) {
+//newTransport wraps tls transport of RestClient into cassette, tracing, limits, audit, cache invalidation and dry run plan recording
+func (c *Client) newTransport() http.RoundTripper {
+
+	base := http.DefaultTransport.(*http.Transport).Clone()
+	base.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+
+	var transport http.RoundTripper = base
+	if c.config.Cassette != nil {
+		transport = &cassetteTransport{base: transport, cassette: c.config.Cassette}
+	}
+	transport = &tracingTransport{base: transport, telemetry: c.telemetry}
+	transport = newLimitingTransport(transport, c.config, c.telemetry)
+	if c.config.Audit != nil {
+		transport = &auditTransport{base: transport, client: c}
+	}
+	if c.cache != nil {
+		transport = &cacheInvalidatingTransport{base: transport, cache: c.cache}
+	}
+	if c.plan != nil {
+		transport = &planTransport{base: transport, plan: c.plan}
+	}
+
+	return transport
+}
+
+func restyBasicClient(config *Config, transport http.RoundTripper) (*resty.Client, error) {
 
 	restclient := resty.New()
 	restclient.SetHeaders(map[string]string{
@@ -72,7 +160,7 @@ func restyBasicClient(config *Config) (*resty.Client, error) {
 		"User-Agent":   "go-client",
 	})
 
-	restclient.SetTLSClientConfig(&tls.Config{InsecureSkipVerify: true})
+	restclient.SetTransport(transport)
 	restclient.SetHostURL(config.URL)
 	restclient.SetDisableWarn(true)
 	if config.Debug {
@@ -85,15 +173,13 @@ func restyBasicClient(config *Config) (*resty.Client, error) {
 		return nil, err
 	}
 
-	log.Debug("Succesfully initialized infinibox client")
-
 	return restclient, nil
 }
 
 //Login provides client login method
 func (c *Client) Login() error {
 
-	log.Debug("Logging into infinibox")
+	c.debugf("Logging into infinibox")
 
 	url := "api/rest/users/login"
 	body := map[string]string{"username": c.config.Username, "password": c.config.Password}
@@ -107,36 +193,105 @@ func (c *Client) Login() error {
 	c.RestClient.SetTimeout(time.Duration(5 * time.Second))
 	c.RestClient.SetRetryCount(3)
 
-	log.Debug("Logged-in succesfully")
+	if c.config.SystemSerial != 0 {
+		system, err := c.GetSystem()
+		if err != nil {
+			return err
+		}
+		if system.SerialNumber != c.config.SystemSerial {
+			return fmt.Errorf("logged into system serial %d, expected system serial %d", system.SerialNumber, c.config.SystemSerial)
+		}
+	}
+
+	c.debugf("Logged-in succesfully")
 
 	return nil
 }
 
 //SetTenant client method sets tenant id for provided tenant
+//
+//Deprecated: SetTenant changes tenant for every goroutine sharing the client, use WithTenant instead
 func (c *Client) SetTenant(tenantname string) error {
 
-	log.Debugf("Setting tenant: %s", tenantname)
+	c.debugf("Setting tenant: %s", tenantname)
 
 	var tenant *Tenant
 
 	tenant, err := c.GetTenantByName(tenantname)
 	if err != nil {
-		log.Error(err.Error())
+		c.errorf("%s", err.Error())
 		return err
 	}
 	if tenant != nil {
-		log.Debugf("Setting tenant id to: %d", tenant.ID)
+		c.debugf("Setting tenant id to: %d", tenant.ID)
 		c.config.tenant = fmt.Sprintf("%d", tenant.ID)
 	}
 	return nil
 
 }
 
+//WithTenant returns client scoped to tenant provided by name or numeric id,
+//scoped client shares connection with parent client which is left unchanged
+func (c *Client) WithTenant(tenant string) (*Client, error) {
+
+	if _, err := strconv.ParseInt(tenant, 10, 64); err == nil {
+		return c.withTenantID(tenant), nil
+	}
+
+	t, err := c.GetTenantByName(tenant)
+	if err != nil {
+		return nil, fmt.Errorf("unable to scope client to tenant %s, %s", tenant, err.Error())
+	}
+
+	return c.WithTenantID(t.ID), nil
+}
+
+//WithTenantID returns client scoped to tenant id
+func (c *Client) WithTenantID(tenantID int64) *Client {
+	return c.withTenantID(fmt.Sprintf("%d", tenantID))
+}
+
+func (c *Client) withTenantID(tenantID string) *Client {
+
+	c.debugf("Scoping client to tenant id: %s", tenantID)
+
+	config := *c.config
+	config.tenant = tenantID
+
+	scoped := *c
+	scoped.config = &config
+
+	return &scoped
+}
+
+//request returns new request carrying client tenant header
+func (c *Client) request() *resty.Request {
+
+	request := c.RestClient.R()
+
+	ctx := c.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	request.SetContext(withAttemptCounter(ctx))
+	if c.config.tenant != "" {
+		request.SetHeader("X-INFINIDAT-TENANT-ID", c.config.tenant)
+	}
+
+	return request
+}
+
+//lunLock returns mutex serializing LUN allocation for provided host or host cluster key
+func (c *Client) lunLock(key string) *sync.Mutex {
+	mu, _ := c.lunLocks.LoadOrStore(key, &sync.Mutex{})
+	return mu.(*sync.Mutex)
+}
+
 //CheckAPIResponse parses API response for error and result
 func CheckAPIResponse(res *resty.Response, err error) (apiresponse *APIResponse, er error) {
 	defer func() {
 		if recovered := recover(); recovered != nil && er == nil {
-			er = fmt.Errorf("panic occured while parsing management api response " + fmt.Sprint(recovered) + "for request " + res.Request.URL)
+			er = fmt.Errorf("panic occured while parsing management api response %v for request %s", recovered, res.Request.URL)
 		}
 	}()
 
@@ -145,27 +300,29 @@ func CheckAPIResponse(res *resty.Response, err error) (apiresponse *APIResponse,
 	}
 
 	if res.StatusCode() == 500 {
-		return nil, fmt.Errorf(res.Status())
+		return nil, fmt.Errorf("%s", res.Status())
 	}
 
 	if er := json.Unmarshal(res.Body(), &apiresponse); er != nil {
-		log.Error("error unmarshalling response body to API RESPONSE type")
-		return nil, er
+		return nil, fmt.Errorf("error unmarshalling response body to API RESPONSE type, %w", er)
 	}
 
 	if apiresponse.APIError != nil {
-		code := ""
-		message := ""
-
-		if _, ok := apiresponse.APIError["code"].(string); ok {
-			code = apiresponse.APIError["code"].(string)
-		}
-		if _, ok := apiresponse.APIError["message"].(string); ok {
-			message = apiresponse.APIError["message"].(string)
-		}
+		code, message := apiresponse.apiError()
 		return nil, fmt.Errorf("{API ERRROR CODE: %s}, {API ERROR MESSAGE: %s}", code, message)
 	}
 
 	return apiresponse, nil
 
 }
+
+//apiError returns code and message of API response error
+func (r *APIResponse) apiError() (code string, message string) {
+	if _, ok := r.APIError["code"].(string); ok {
+		code = r.APIError["code"].(string)
+	}
+	if _, ok := r.APIError["message"].(string); ok {
+		message = r.APIError["message"].(string)
+	}
+	return code, message
+}
@@ -0,0 +1,112 @@
+package infinibox
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+//testIBOX serves management api from handlers registered by method and path and records requests
+type testIBOX struct {
+	server   *httptest.Server
+	mu       sync.Mutex
+	handlers map[string]http.HandlerFunc
+	requests []string
+}
+
+//newTestIBOX starts test management api server closed when test ends
+func newTestIBOX(t *testing.T) *testIBOX {
+	ibox := &testIBOX{handlers: map[string]http.HandlerFunc{}}
+	ibox.server = httptest.NewServer(http.HandlerFunc(ibox.serve))
+	t.Cleanup(ibox.server.Close)
+	return ibox
+}
+
+func (ibox *testIBOX) serve(w http.ResponseWriter, r *http.Request) {
+
+	key := r.Method + " " + r.URL.Path
+
+	ibox.mu.Lock()
+	ibox.requests = append(ibox.requests, key)
+	handler, ok := ibox.handlers[key]
+	ibox.mu.Unlock()
+
+	if !ok {
+		writeAPIError(w, http.StatusNotFound, "NOT_FOUND", "no handler for "+key)
+		return
+	}
+	handler(w, r)
+}
+
+//handle registers handler of method and path, path starts with /api/rest
+func (ibox *testIBOX) handle(method string, path string, handler http.HandlerFunc) {
+	ibox.mu.Lock()
+	defer ibox.mu.Unlock()
+	ibox.handlers[method+" "+path] = handler
+}
+
+//count returns number of requests sent to method and path
+func (ibox *testIBOX) count(method string, path string) int {
+	ibox.mu.Lock()
+	defer ibox.mu.Unlock()
+	n := 0
+	for _, request := range ibox.requests {
+		if request == method+" "+path {
+			n++
+		}
+	}
+	return n
+}
+
+//sent returns requests sent to server in order as method and path
+func (ibox *testIBOX) sent() []string {
+	ibox.mu.Lock()
+	defer ibox.mu.Unlock()
+	return append([]string{}, ibox.requests...)
+}
+
+//client returns client of test server, config URL is set to server URL
+func (ibox *testIBOX) client(t *testing.T, config Config) *Client {
+	config.URL = ibox.server.URL
+	client, err := NewClient(&config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+//writeResult writes management api response carrying result
+func writeResult(w http.ResponseWriter, result interface{}) {
+	writeResponse(w, http.StatusOK, map[string]interface{}{
+		"result":   result,
+		"error":    nil,
+		"metadata": map[string]interface{}{"ready": true, "page": 1, "pages_total": 1},
+	})
+}
+
+//writeAPIError writes management api response carrying error
+func writeAPIError(w http.ResponseWriter, status int, code string, message string) {
+	writeResponse(w, status, map[string]interface{}{
+		"result":   nil,
+		"error":    map[string]interface{}{"code": code, "message": message},
+		"metadata": map[string]interface{}{"ready": true},
+	})
+}
+
+func writeResponse(w http.ResponseWriter, status int, response interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
+
+//readBody decodes JSON request body
+func readBody(t *testing.T, r *http.Request) map[string]interface{} {
+	var body map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil && !strings.Contains(err.Error(), "EOF") {
+		t.Errorf("decoding %s %s body, %s", r.Method, r.URL.Path, err.Error())
+	}
+	return body
+}
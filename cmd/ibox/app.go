@@ -0,0 +1,187 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/devnal/infinibox-go-client"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+//app holds logged in client and output settings shared by commands
+type app struct {
+	client *infinibox.Client
+	opts   options
+}
+
+//newApp logs into IBOX, scoped selects tenant from flags, saved state or config
+func newApp(opts options, scoped bool) (*app, error) {
+
+	config, err := infinibox.LoadConfigProfile(opts.config, opts.profile)
+	if err != nil {
+		return nil, err
+	}
+
+	tenant := opts.tenant
+	if tenant == "" {
+		tenant = currentTenant()
+	}
+	if !scoped || tenant != "" {
+		config.TenantID = 0
+	}
+	config.DryRun = opts.dryRun
+
+	client, err := infinibox.NewClient(config)
+	if err != nil {
+		return nil, err
+	}
+
+	err = client.Login()
+	if err != nil {
+		return nil, fmt.Errorf("login failed, %s", err.Error())
+	}
+
+	if scoped && tenant != "" {
+		client, err = client.WithTenant(tenant)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &app{client: client, opts: opts}, nil
+}
+
+//mutate runs fn and prints its result with columns, in dry run mode requests fn would send are printed instead of its result
+func (a *app) mutate(operation string, details interface{}, columns []column, fn func() (interface{}, error)) error {
+
+	result, err := fn()
+	if err != nil {
+		return err
+	}
+
+	if a.opts.dryRun {
+		if a.opts.output == "json" {
+			return a.print(map[string]interface{}{"dry_run": true, "operation": operation, "details": details, "plan": a.client.Plan()}, nil)
+		}
+		rows := []interface{}{}
+		for _, step := range a.client.Plan().Steps() {
+			rows = append(rows, step)
+		}
+		return a.print(rows, planColumns)
+	}
+
+	if result == nil {
+		return nil
+	}
+	return a.print(result, columns)
+}
+
+//column renders single table column of object
+type column struct {
+	header string
+	value  func(interface{}) string
+}
+
+//planColumns renders dry run plan steps
+var planColumns = []column{
+	{"METHOD", func(o interface{}) string { return o.(infinibox.PlanStep).Method }},
+	{"URL", func(o interface{}) string { return o.(infinibox.PlanStep).URL }},
+	{"TENANT", func(o interface{}) string { return o.(infinibox.PlanStep).Tenant }},
+	{"APPROVED", func(o interface{}) string { return strconv.FormatBool(o.(infinibox.PlanStep).Approved) }},
+}
+
+//print writes object as JSON or table, slices are printed row per element, table output requires columns
+func (a *app) print(object interface{}, columns []column) error {
+
+	if a.opts.output == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(object)
+	}
+
+	if a.opts.output != "table" {
+		return fmt.Errorf("unknown output format %s", a.opts.output)
+	}
+	if columns == nil {
+		return fmt.Errorf("table output is not supported by this command, use -output json")
+	}
+
+	rows := []interface{}{}
+	switch objects := object.(type) {
+	case []interface{}:
+		rows = objects
+	default:
+		rows = append(rows, object)
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	headers := []string{}
+	for _, c := range columns {
+		headers = append(headers, c.header)
+	}
+	fmt.Fprintln(writer, strings.Join(headers, "\t"))
+
+	for _, row := range rows {
+		values := []string{}
+		for _, c := range columns {
+			values = append(values, c.value(row))
+		}
+		fmt.Fprintln(writer, strings.Join(values, "\t"))
+	}
+
+	return writer.Flush()
+}
+
+//newFlags returns flag set for command action
+func newFlags(name string) *flag.FlagSet {
+	return flag.NewFlagSet(name, flag.ContinueOnError)
+}
+
+//flagValue pairs flag name with its parsed value
+type flagValue struct {
+	name  string
+	value string
+}
+
+//required returns error naming first empty flag in order flags are passed
+func required(flags ...flagValue) error {
+	for _, flag := range flags {
+		if flag.value == "" {
+			return fmt.Errorf("flag -%s is required", flag.name)
+		}
+	}
+	return nil
+}
+
+//parseSize parses size in bytes with optional decimal (KB, MB, GB, TB) or binary (KiB, MiB, GiB, TiB) unit
+func parseSize(size string) (uint64, error) {
+
+	units := []struct {
+		suffix     string
+		multiplier uint64
+	}{
+		{"KIB", 1 << 10}, {"MIB", 1 << 20}, {"GIB", 1 << 30}, {"TIB", 1 << 40},
+		{"KB", 1000}, {"MB", 1000 * 1000}, {"GB", 1000 * 1000 * 1000}, {"TB", 1000 * 1000 * 1000 * 1000},
+		{"B", 1},
+	}
+
+	value := strings.ToUpper(strings.TrimSpace(size))
+	multiplier := uint64(1)
+	for _, unit := range units {
+		if strings.HasSuffix(value, unit.suffix) {
+			value = strings.TrimSpace(strings.TrimSuffix(value, unit.suffix))
+			multiplier = unit.multiplier
+			break
+		}
+	}
+
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %s", size)
+	}
+
+	return uint64(n * float64(multiplier)), nil
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestRequiredReportsFirstMissingFlagInOrder(t *testing.T) {
+
+	tests := []struct {
+		flags []flagValue
+		want  string
+	}{
+		{flags: []flagValue{{"name", ""}, {"pool", ""}, {"size", ""}}, want: "flag -name is required"},
+		{flags: []flagValue{{"name", "v1"}, {"pool", ""}, {"size", ""}}, want: "flag -pool is required"},
+		{flags: []flagValue{{"name", "v1"}, {"pool", "p1"}, {"size", ""}}, want: "flag -size is required"},
+		{flags: []flagValue{{"name", "v1"}, {"pool", "p1"}, {"size", "1GB"}}},
+	}
+
+	for _, tt := range tests {
+		//map iteration order made the reported flag random, repeat to catch it
+		for run := 0; run < 20; run++ {
+			err := required(tt.flags...)
+			if (err == nil && tt.want != "") || (err != nil && err.Error() != tt.want) {
+				t.Fatalf("required(%v) = %v, want %q", tt.flags, err, tt.want)
+			}
+		}
+	}
+}
+
+func TestPrintRejectsTableWithoutColumns(t *testing.T) {
+
+	a := &app{opts: options{output: "table"}}
+	if err := a.print(map[string]interface{}{"id": 1}, nil); err == nil {
+		t.Fatal("table output without columns printed, want error")
+	}
+}
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"fmt"
+	"github.com/devnal/infinibox-go-client"
+)
+
+var hostColumns = []column{
+	{"ID", func(o interface{}) string { return fmt.Sprintf("%d", o.(infinibox.Host).ID) }},
+	{"NAME", func(o interface{}) string { return o.(infinibox.Host).Name }},
+	{"SECURITY", func(o interface{}) string { return o.(infinibox.Host).SecurityMethod }},
+	{"CLUSTER_ID", func(o interface{}) string { return fmt.Sprintf("%d", o.(infinibox.Host).HostClusterID) }},
+	{"PORTS", func(o interface{}) string { return fmt.Sprintf("%d", len(o.(infinibox.Host).Ports)) }},
+	{"LUNS", func(o interface{}) string { return fmt.Sprintf("%d", len(o.(infinibox.Host).Luns)) }},
+}
+
+var clusterColumns = []column{
+	{"ID", func(o interface{}) string { return fmt.Sprintf("%d", o.(*infinibox.HostCluster).ID) }},
+	{"NAME", func(o interface{}) string { return o.(*infinibox.HostCluster).Name }},
+	{"HOSTS", func(o interface{}) string { return fmt.Sprintf("%d", len(o.(*infinibox.HostCluster).Hosts)) }},
+	{"LUNS", func(o interface{}) string { return fmt.Sprintf("%d", len(o.(*infinibox.HostCluster).Luns)) }},
+}
+
+var hostCommands = map[string]command{
+	"list":     hostList,
+	"create":   hostCreate,
+	"rename":   hostRename,
+	"add-port": hostAddPort,
+	"delete":   hostDelete,
+}
+
+var clusterCommands = map[string]command{
+	"list":     clusterList,
+	"create":   clusterCreate,
+	"rename":   clusterRename,
+	"add-host": clusterAddHost,
+	"delete":   clusterDelete,
+}
+
+func hostList(a *app, args []string) error {
+
+	hosts, err := a.client.GetAllHosts()
+	if err != nil {
+		return err
+	}
+
+	rows := []interface{}{}
+	if hosts != nil {
+		for _, host := range *hosts {
+			rows = append(rows, host)
+		}
+	}
+
+	return a.print(rows, hostColumns)
+}
+
+func hostCreate(a *app, args []string) error {
+
+	flags := newFlags("host create")
+	name := flags.String("name", "", "host name")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if err := required(flagValue{"name", *name}); err != nil {
+		return err
+	}
+
+	host := &infinibox.Host{Name: *name}
+
+	return a.mutate("host create", host, hostColumns, func() (interface{}, error) {
+		err := host.Create(a.client)
+		return *host, err
+	})
+}
+
+func hostRename(a *app, args []string) error {
+
+	flags := newFlags("host rename")
+	name := flags.String("name", "", "host name")
+	newName := flags.String("new-name", "", "new host name")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if err := required(flagValue{"name", *name}, flagValue{"new-name", *newName}); err != nil {
+		return err
+	}
+
+	host, err := a.client.GetHostByName(*name)
+	if err != nil {
+		return err
+	}
+
+	return a.mutate("host rename", map[string]interface{}{"host": host.Name, "new_name": *newName}, hostColumns, func() (interface{}, error) {
+		err := host.UpdateName(a.client, *newName)
+		return *host, err
+	})
+}
+
+func hostAddPort(a *app, args []string) error {
+
+	flags := newFlags("host add-port")
+	name := flags.String("name", "", "host name")
+	portType := flags.String("type", "ISCSI", "port type, ISCSI or FC")
+	address := flags.String("address", "", "initiator IQN or WWPN")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if err := required(flagValue{"name", *name}, flagValue{"address", *address}); err != nil {
+		return err
+	}
+
+	host, err := a.client.GetHostByName(*name)
+	if err != nil {
+		return err
+	}
+
+	port := &infinibox.Port{Type: *portType, Address: *address}
+
+	return a.mutate("host add-port", map[string]interface{}{"host": host.Name, "port": port}, nil, func() (interface{}, error) {
+		return nil, host.AddPort(a.client, port)
+	})
+}
+
+func hostDelete(a *app, args []string) error {
+
+	flags := newFlags("host delete")
+	name := flags.String("name", "", "host name")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if err := required(flagValue{"name", *name}); err != nil {
+		return err
+	}
+
+	host, err := a.client.GetHostByName(*name)
+	if err != nil {
+		return err
+	}
+
+	return a.mutate("host delete", map[string]interface{}{"host": host.Name}, nil, func() (interface{}, error) {
+		return nil, host.Delete(a.client)
+	})
+}
+
+func clusterList(a *app, args []string) error {
+
+	clusters, err := a.client.GetAllHostClusters()
+	if err != nil {
+		return err
+	}
+
+	rows := []interface{}{}
+	if clusters != nil {
+		for i := range *clusters {
+			rows = append(rows, &(*clusters)[i])
+		}
+	}
+
+	return a.print(rows, clusterColumns)
+}
+
+func clusterCreate(a *app, args []string) error {
+
+	flags := newFlags("cluster create")
+	name := flags.String("name", "", "host cluster name")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if err := required(flagValue{"name", *name}); err != nil {
+		return err
+	}
+
+	cluster := &infinibox.HostCluster{Name: *name}
+
+	return a.mutate("cluster create", map[string]interface{}{"cluster": *name}, clusterColumns, func() (interface{}, error) {
+		err := cluster.Create(a.client)
+		return cluster, err
+	})
+}
+
+func clusterRename(a *app, args []string) error {
+
+	flags := newFlags("cluster rename")
+	name := flags.String("name", "", "host cluster name")
+	newName := flags.String("new-name", "", "new host cluster name")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if err := required(flagValue{"name", *name}, flagValue{"new-name", *newName}); err != nil {
+		return err
+	}
+
+	cluster, err := a.client.GetHostClusterByName(*name)
+	if err != nil {
+		return err
+	}
+
+	return a.mutate("cluster rename", map[string]interface{}{"cluster": cluster.Name, "new_name": *newName}, clusterColumns, func() (interface{}, error) {
+		err := cluster.UpdateName(a.client, *newName)
+		return cluster, err
+	})
+}
+
+func clusterAddHost(a *app, args []string) error {
+
+	flags := newFlags("cluster add-host")
+	name := flags.String("name", "", "host cluster name")
+	hostName := flags.String("host", "", "host name")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if err := required(flagValue{"name", *name}, flagValue{"host", *hostName}); err != nil {
+		return err
+	}
+
+	cluster, err := a.client.GetHostClusterByName(*name)
+	if err != nil {
+		return err
+	}
+	host, err := a.client.GetHostByName(*hostName)
+	if err != nil {
+		return err
+	}
+
+	return a.mutate("cluster add-host", map[string]interface{}{"cluster": cluster.Name, "host": host.Name}, nil, func() (interface{}, error) {
+		return nil, cluster.AddHost(a.client, uint64(host.ID))
+	})
+}
+
+func clusterDelete(a *app, args []string) error {
+
+	flags := newFlags("cluster delete")
+	name := flags.String("name", "", "host cluster name")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if err := required(flagValue{"name", *name}); err != nil {
+		return err
+	}
+
+	cluster, err := a.client.GetHostClusterByName(*name)
+	if err != nil {
+		return err
+	}
+
+	return a.mutate("cluster delete", map[string]interface{}{"cluster": cluster.Name}, nil, func() (interface{}, error) {
+		return nil, cluster.Delete(a.client)
+	})
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/devnal/infinibox-go-client"
+	log "github.com/sirupsen/logrus"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const usage = `Usage: ibox [global flags] <command> <action> [flags]
+
+Commands:
+  volume    list | create | resize | snapshot | restore | map | unmap
+  pool      list | create | rename | delete
+  host      list | create | rename | add-port | delete
+  cluster   list | create | rename | add-host | delete
+  metadata  get | set
+  tenant    list | switch | current
+
+Global flags:
+`
+
+//options represents global command line flags
+type options struct {
+	config  string
+	profile string
+	tenant  string
+	output  string
+	dryRun  bool
+	debug   bool
+}
+
+//command is single resource action handler
+type command func(app *app, args []string) error
+
+var commands = map[string]map[string]command{
+	"volume":   volumeCommands,
+	"pool":     poolCommands,
+	"host":     hostCommands,
+	"cluster":  clusterCommands,
+	"metadata": metadataCommands,
+	"tenant":   tenantCommands,
+}
+
+func main() {
+
+	opts := options{}
+	global := flag.NewFlagSet("ibox", flag.ExitOnError)
+	global.StringVar(&opts.config, "config", "", "config file in ibox_config.json format, defaults to IBOX_CONFIG, IBOX_* environment variables take precedence")
+	global.StringVar(&opts.profile, "profile", os.Getenv(infinibox.EnvProfile), "config file profile")
+	global.StringVar(&opts.tenant, "tenant", "", "tenant name or id, defaults to tenant set by 'ibox tenant switch'")
+	global.StringVar(&opts.output, "output", "table", "output format: table or json")
+	global.BoolVar(&opts.dryRun, "dry-run", false, "print requests of mutating operations instead of sending them")
+	global.BoolVar(&opts.debug, "debug", false, "enable debug logging")
+	global.Usage = func() {
+		fmt.Fprint(os.Stderr, usage)
+		global.PrintDefaults()
+	}
+	global.Parse(os.Args[1:])
+
+	if opts.debug {
+		log.SetLevel(log.DebugLevel)
+	} else {
+		log.SetLevel(log.WarnLevel)
+	}
+
+	args := global.Args()
+	if len(args) < 2 {
+		global.Usage()
+		os.Exit(2)
+	}
+
+	actions, ok := commands[args[0]]
+	if !ok {
+		fail(fmt.Errorf("unknown command %s", args[0]))
+	}
+	action, ok := actions[args[1]]
+	if !ok {
+		fail(fmt.Errorf("unknown %s action %s", args[0], args[1]))
+	}
+
+	app, err := newApp(opts, args[0] != "tenant" || args[1] != "switch")
+	if err != nil {
+		fail(err)
+	}
+
+	err = action(app, args[2:])
+	if err != nil {
+		fail(err)
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintf(os.Stderr, "ibox: %s\n", err.Error())
+	os.Exit(1)
+}
+
+//tenantStatePath returns file storing tenant selected by 'ibox tenant switch'
+func tenantStatePath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "ibox", "tenant")
+}
+
+func currentTenant() string {
+	data, err := os.ReadFile(tenantStatePath())
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"github.com/devnal/infinibox-go-client"
+	"strconv"
+	"strings"
+)
+
+var metadataColumns = []column{
+	{"OBJECT_ID", func(o interface{}) string { return fmt.Sprintf("%d", o.(infinibox.Metadata).ObjectID) }},
+	{"OBJECT_TYPE", func(o interface{}) string { return o.(infinibox.Metadata).ObjectType }},
+	{"KEY", func(o interface{}) string { return o.(infinibox.Metadata).Key }},
+	{"VALUE", func(o interface{}) string { return fmt.Sprint(o.(infinibox.Metadata).Value) }},
+}
+
+var metadataCommands = map[string]command{
+	"get": metadataGet,
+	"set": metadataSet,
+}
+
+func metadataGet(a *app, args []string) error {
+
+	flags := newFlags("metadata get")
+	objectID := flags.Int64("id", 0, "object id")
+	key := flags.String("key", "", "metadata key, all keys when empty")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *objectID == 0 {
+		return fmt.Errorf("flag -id is required")
+	}
+
+	rows := []interface{}{}
+
+	if *key != "" {
+		metadata, err := a.client.GetMetadataByObjectAndKey(*objectID, *key)
+		if err != nil {
+			return err
+		}
+		rows = append(rows, *metadata)
+		return a.print(rows, metadataColumns)
+	}
+
+	metadata, err := a.client.GetMetadataByObject(*objectID)
+	if err != nil {
+		return err
+	}
+	if metadata != nil {
+		for _, entry := range *metadata {
+			rows = append(rows, entry)
+		}
+	}
+
+	return a.print(rows, metadataColumns)
+}
+
+func metadataSet(a *app, args []string) error {
+
+	flags := newFlags("metadata set")
+	objectID := flags.Int64("id", 0, "object id")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *objectID == 0 {
+		return fmt.Errorf("flag -id is required")
+	}
+	if flags.NArg() == 0 {
+		return fmt.Errorf("expected key=value arguments")
+	}
+
+	values := map[string]interface{}{}
+	for _, arg := range flags.Args() {
+		parts := strings.SplitN(arg, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return fmt.Errorf("invalid metadata %s, expected key=value", arg)
+		}
+		values[parts[0]] = parts[1]
+	}
+
+	return a.mutate("metadata set", map[string]interface{}{"object_id": strconv.FormatInt(*objectID, 10), "values": values}, nil, func() (interface{}, error) {
+		return nil, a.client.AddMetadataMap(*objectID, values)
+	})
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"github.com/devnal/infinibox-go-client"
+)
+
+var poolColumns = []column{
+	{"ID", func(o interface{}) string { return fmt.Sprintf("%d", o.(infinibox.Pool).ID) }},
+	{"NAME", func(o interface{}) string { return o.(infinibox.Pool).Name }},
+	{"STATE", func(o interface{}) string { return o.(infinibox.Pool).State }},
+	{"PHYSICAL", func(o interface{}) string { return fmt.Sprintf("%d", o.(infinibox.Pool).PhysicalCapacity) }},
+	{"FREE_PHYSICAL", func(o interface{}) string { return fmt.Sprintf("%d", o.(infinibox.Pool).FreePhysicalSpace) }},
+	{"VIRTUAL", func(o interface{}) string { return fmt.Sprintf("%d", o.(infinibox.Pool).VirtualCapacity) }},
+	{"FREE_VIRTUAL", func(o interface{}) string { return fmt.Sprintf("%d", o.(infinibox.Pool).FreeVirtualSpace) }},
+	{"VOLUMES", func(o interface{}) string { return fmt.Sprintf("%d", o.(infinibox.Pool).VolumesCount) }},
+}
+
+var poolCommands = map[string]command{
+	"list":   poolList,
+	"create": poolCreate,
+	"rename": poolRename,
+	"delete": poolDelete,
+}
+
+func poolList(a *app, args []string) error {
+
+	pools, err := a.client.GetAllPools()
+	if err != nil {
+		return err
+	}
+
+	rows := []interface{}{}
+	if pools != nil {
+		for _, pool := range *pools {
+			rows = append(rows, pool)
+		}
+	}
+
+	return a.print(rows, poolColumns)
+}
+
+func poolCreate(a *app, args []string) error {
+
+	flags := newFlags("pool create")
+	name := flags.String("name", "", "pool name")
+	physical := flags.String("physical", "", "physical capacity, e.g. 10TB")
+	virtual := flags.String("virtual", "", "virtual capacity, defaults to physical capacity")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if err := required(flagValue{"name", *name}, flagValue{"physical", *physical}); err != nil {
+		return err
+	}
+	if *virtual == "" {
+		*virtual = *physical
+	}
+
+	physicalBytes, err := parseSize(*physical)
+	if err != nil {
+		return err
+	}
+	virtualBytes, err := parseSize(*virtual)
+	if err != nil {
+		return err
+	}
+
+	pool := &infinibox.Pool{Name: *name, PhysicalCapacity: physicalBytes, VirtualCapacity: virtualBytes}
+
+	return a.mutate("pool create", pool, poolColumns, func() (interface{}, error) {
+		err := pool.Create(a.client)
+		return *pool, err
+	})
+}
+
+func poolRename(a *app, args []string) error {
+
+	flags := newFlags("pool rename")
+	name := flags.String("name", "", "pool name")
+	newName := flags.String("new-name", "", "new pool name")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if err := required(flagValue{"name", *name}, flagValue{"new-name", *newName}); err != nil {
+		return err
+	}
+
+	pool, err := a.client.GetPoolByName(*name)
+	if err != nil {
+		return err
+	}
+
+	return a.mutate("pool rename", map[string]interface{}{"pool": pool.Name, "new_name": *newName}, poolColumns, func() (interface{}, error) {
+		err := pool.UpdateName(a.client, *newName)
+		return *pool, err
+	})
+}
+
+func poolDelete(a *app, args []string) error {
+
+	flags := newFlags("pool delete")
+	name := flags.String("name", "", "pool name")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if err := required(flagValue{"name", *name}); err != nil {
+		return err
+	}
+
+	pool, err := a.client.GetPoolByName(*name)
+	if err != nil {
+		return err
+	}
+
+	return a.mutate("pool delete", map[string]interface{}{"pool": pool.Name}, poolColumns, func() (interface{}, error) {
+		deleted, err := pool.Delete(a.client)
+		if err != nil || deleted == nil {
+			return nil, err
+		}
+		return *deleted, nil
+	})
+}
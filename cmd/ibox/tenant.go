@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"github.com/devnal/infinibox-go-client"
+	"os"
+	"path/filepath"
+)
+
+var tenantColumns = []column{
+	{"ID", func(o interface{}) string { return fmt.Sprintf("%d", o.(infinibox.Tenant).ID) }},
+	{"NAME", func(o interface{}) string { return o.(infinibox.Tenant).Name }},
+	{"POOLS", func(o interface{}) string { return fmt.Sprintf("%d", o.(infinibox.Tenant).EntityCounts.Pools) }},
+	{"HOSTS", func(o interface{}) string { return fmt.Sprintf("%d", o.(infinibox.Tenant).EntityCounts.Hosts) }},
+	{"CLUSTERS", func(o interface{}) string { return fmt.Sprintf("%d", o.(infinibox.Tenant).EntityCounts.Clusters) }},
+}
+
+var tenantCommands = map[string]command{
+	"list":    tenantList,
+	"switch":  tenantSwitch,
+	"current": tenantCurrent,
+}
+
+func tenantList(a *app, args []string) error {
+
+	tenants, err := a.client.GetAllTenants()
+	if err != nil {
+		return err
+	}
+
+	rows := []interface{}{}
+	if tenants != nil {
+		for _, tenant := range *tenants {
+			rows = append(rows, tenant)
+		}
+	}
+
+	return a.print(rows, tenantColumns)
+}
+
+//tenantSwitch verifies tenant exists and saves it as default for following commands
+func tenantSwitch(a *app, args []string) error {
+
+	if len(args) != 1 {
+		return fmt.Errorf("expected tenant name")
+	}
+
+	tenant, err := a.client.GetTenantByName(args[0])
+	if err != nil {
+		return err
+	}
+
+	if a.opts.dryRun {
+		if a.opts.output == "json" {
+			return a.print(map[string]interface{}{"dry_run": true, "operation": "tenant switch", "details": tenant.Name}, nil)
+		}
+		fmt.Printf("dry run, would switch to tenant %s (ID %d)\n", tenant.Name, tenant.ID)
+		return nil
+	}
+
+	path := tenantStatePath()
+	err = os.MkdirAll(filepath.Dir(path), 0700)
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(path, []byte(tenant.Name+"\n"), 0600)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("switched to tenant %s (ID %d)\n", tenant.Name, tenant.ID)
+
+	return nil
+}
+
+func tenantCurrent(a *app, args []string) error {
+
+	tenant := a.opts.tenant
+	if tenant == "" {
+		tenant = currentTenant()
+	}
+	if tenant == "" {
+		tenant = "(none)"
+	}
+
+	fmt.Println(tenant)
+
+	return nil
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"fmt"
+	"github.com/devnal/infinibox-go-client"
+	"strconv"
+)
+
+var volumeColumns = []column{
+	{"ID", func(o interface{}) string { return fmt.Sprintf("%d", o.(infinibox.Volume).ID) }},
+	{"NAME", func(o interface{}) string { return o.(infinibox.Volume).Name }},
+	{"TYPE", func(o interface{}) string { return o.(infinibox.Volume).Type }},
+	{"POOL", func(o interface{}) string { return o.(infinibox.Volume).PoolName }},
+	{"SIZE", func(o interface{}) string { return fmt.Sprintf("%d", o.(infinibox.Volume).Size) }},
+	{"USED", func(o interface{}) string { return fmt.Sprintf("%d", o.(infinibox.Volume).Used) }},
+	{"PROVTYPE", func(o interface{}) string { return o.(infinibox.Volume).Provtype }},
+	{"MAPPED", func(o interface{}) string { return strconv.FormatBool(o.(infinibox.Volume).Mapped) }},
+}
+
+var lunColumns = []column{
+	{"LUN", func(o interface{}) string { return fmt.Sprintf("%d", o.(infinibox.Lun).Lun) }},
+	{"VOLUME_ID", func(o interface{}) string { return fmt.Sprintf("%d", o.(infinibox.Lun).VolumeID) }},
+	{"HOST_ID", func(o interface{}) string { return fmt.Sprintf("%d", o.(infinibox.Lun).HostID) }},
+	{"CLUSTER_ID", func(o interface{}) string { return fmt.Sprintf("%d", o.(infinibox.Lun).HostClusterID) }},
+	{"CLUSTERED", func(o interface{}) string { return strconv.FormatBool(o.(infinibox.Lun).Clustered) }},
+}
+
+var unMapDeleteColumns = []column{
+	{"VOLUME_ID", func(o interface{}) string { return fmt.Sprintf("%d", o.(infinibox.UnMapDeleteReport).VolumeID) }},
+	{"VOLUME", func(o interface{}) string { return o.(infinibox.UnMapDeleteReport).VolumeName }},
+	{"UNMAPPED", func(o interface{}) string { return fmt.Sprintf("%d", len(o.(infinibox.UnMapDeleteReport).Unmapped)) }},
+	{"DELETED", func(o interface{}) string { return strconv.FormatBool(o.(infinibox.UnMapDeleteReport).Deleted) }},
+	{"ROLLED_BACK", func(o interface{}) string { return strconv.FormatBool(o.(infinibox.UnMapDeleteReport).RolledBack) }},
+}
+
+var volumeCommands = map[string]command{
+	"list":     volumeList,
+	"create":   volumeCreate,
+	"resize":   volumeResize,
+	"snapshot": volumeSnapshot,
+	"restore":  volumeRestore,
+	"map":      volumeMap,
+	"unmap":    volumeUnMap,
+}
+
+func volumeRows(volumes *[]infinibox.Volume) []interface{} {
+	rows := []interface{}{}
+	if volumes != nil {
+		for _, volume := range *volumes {
+			rows = append(rows, volume)
+		}
+	}
+	return rows
+}
+
+//lunRow returns mapped LUN as lunColumns row
+func lunRow(lun *infinibox.Lun, err error) (interface{}, error) {
+	if err != nil {
+		return nil, err
+	}
+	return *lun, nil
+}
+
+func volumeList(a *app, args []string) error {
+
+	volumes, err := a.client.GetAllVolumes()
+	if err != nil {
+		return err
+	}
+
+	return a.print(volumeRows(volumes), volumeColumns)
+}
+
+func volumeCreate(a *app, args []string) error {
+
+	flags := newFlags("volume create")
+	name := flags.String("name", "", "volume name")
+	poolName := flags.String("pool", "", "pool name")
+	size := flags.String("size", "", "volume size, e.g. 10GiB")
+	thick := flags.Bool("thick", false, "create thick provisioned volume")
+	ssd := flags.Bool("ssd", false, "enable SSD cache")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if err := required(flagValue{"name", *name}, flagValue{"pool", *poolName}, flagValue{"size", *size}); err != nil {
+		return err
+	}
+
+	bytes, err := parseSize(*size)
+	if err != nil {
+		return err
+	}
+
+	pool, err := a.client.GetPoolByName(*poolName)
+	if err != nil {
+		return err
+	}
+
+	volume := &infinibox.Volume{Name: *name, PoolID: pool.ID, Size: bytes, SsdEnabled: *ssd}
+	if *thick {
+		volume.Provtype = "THICK"
+	}
+
+	return a.mutate("volume create", volume, volumeColumns, func() (interface{}, error) {
+		err := volume.Create(a.client)
+		return *volume, err
+	})
+}
+
+func volumeResize(a *app, args []string) error {
+
+	flags := newFlags("volume resize")
+	name := flags.String("name", "", "volume name")
+	size := flags.String("size", "", "new volume size, e.g. 20GiB")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if err := required(flagValue{"name", *name}, flagValue{"size", *size}); err != nil {
+		return err
+	}
+
+	bytes, err := parseSize(*size)
+	if err != nil {
+		return err
+	}
+
+	volume, err := a.client.GetVolumeByName(*name)
+	if err != nil {
+		return err
+	}
+
+	return a.mutate("volume resize", map[string]interface{}{"volume": volume.Name, "size": bytes}, volumeColumns, func() (interface{}, error) {
+		err := volume.UpdateSize(a.client, bytes)
+		return *volume, err
+	})
+}
+
+func volumeSnapshot(a *app, args []string) error {
+
+	flags := newFlags("volume snapshot")
+	name := flags.String("name", "", "volume name")
+	snapshotName := flags.String("snapshot-name", "", "snapshot name, generated when empty")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if err := required(flagValue{"name", *name}); err != nil {
+		return err
+	}
+
+	volume, err := a.client.GetVolumeByName(*name)
+	if err != nil {
+		return err
+	}
+
+	return a.mutate("volume snapshot", map[string]interface{}{"volume": volume.Name, "snapshot": *snapshotName}, volumeColumns, func() (interface{}, error) {
+		snapshot, err := volume.Snapshot(a.client, *snapshotName)
+		if err != nil {
+			return nil, err
+		}
+		return *snapshot, nil
+	})
+}
+
+func volumeRestore(a *app, args []string) error {
+
+	flags := newFlags("volume restore")
+	name := flags.String("name", "", "volume name")
+	snapshotName := flags.String("snapshot", "", "snapshot name to restore from")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if err := required(flagValue{"name", *name}, flagValue{"snapshot", *snapshotName}); err != nil {
+		return err
+	}
+
+	volume, err := a.client.GetVolumeByName(*name)
+	if err != nil {
+		return err
+	}
+	snapshot, err := a.client.GetVolumeByName(*snapshotName)
+	if err != nil {
+		return err
+	}
+
+	return a.mutate("volume restore", map[string]interface{}{"volume": volume.Name, "snapshot": snapshot.Name}, nil, func() (interface{}, error) {
+		return nil, volume.Restore(a.client, uint64(snapshot.ID))
+	})
+}
+
+func volumeMap(a *app, args []string) error {
+
+	flags := newFlags("volume map")
+	name := flags.String("name", "", "volume name")
+	hostName := flags.String("host", "", "host to map volume to")
+	clusterName := flags.String("cluster", "", "host cluster to map volume to")
+	lun := flags.Int("lun", 1, "first LUN number to consider")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if err := required(flagValue{"name", *name}); err != nil {
+		return err
+	}
+	if (*hostName == "") == (*clusterName == "") {
+		return fmt.Errorf("exactly one of -host and -cluster is required")
+	}
+
+	volume, err := a.client.GetVolumeByName(*name)
+	if err != nil {
+		return err
+	}
+
+	details := map[string]interface{}{"volume": volume.Name, "host": *hostName, "cluster": *clusterName, "lun": *lun}
+
+	if *hostName != "" {
+		host, err := a.client.GetHostByName(*hostName)
+		if err != nil {
+			return err
+		}
+		return a.mutate("volume map", details, lunColumns, func() (interface{}, error) {
+			return lunRow(volume.MapToHost(a.client, host, *lun))
+		})
+	}
+
+	cluster, err := a.client.GetHostClusterByName(*clusterName)
+	if err != nil {
+		return err
+	}
+	return a.mutate("volume map", details, lunColumns, func() (interface{}, error) {
+		return lunRow(volume.MapToCluster(a.client, cluster, *lun))
+	})
+}
+
+func volumeUnMap(a *app, args []string) error {
+
+	flags := newFlags("volume unmap")
+	name := flags.String("name", "", "volume name")
+	deleteVolume := flags.Bool("delete", false, "delete volume after unmapping, mappings are restored on failure")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if err := required(flagValue{"name", *name}); err != nil {
+		return err
+	}
+
+	volume, err := a.client.GetVolumeByName(*name)
+	if err != nil {
+		return err
+	}
+
+	if *deleteVolume {
+		return a.mutate("volume unmap and delete", map[string]interface{}{"volume": volume.Name}, unMapDeleteColumns, func() (interface{}, error) {
+			report, err := volume.UnMapAndDelete(a.client)
+			if report == nil {
+				return nil, err
+			}
+			return *report, err
+		})
+	}
+
+	return a.mutate("volume unmap", map[string]interface{}{"volume": volume.Name}, nil, func() (interface{}, error) {
+		return nil, volume.UnMap(a.client)
+	})
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"fmt"
+	"github.com/devnal/infinibox-go-client"
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	"sync"
+	"time"
+)
+
+const namespace = "infinibox"
+
+//arrayTarget represents scraped array, scrapes of single array are serialized,
+//tenant scopes scrape to tenant with provided name
+type arrayTarget struct {
+	name     string
+	tenant   string
+	client   *infinibox.Client
+	mu       sync.Mutex
+	loggedIn bool
+}
+
+//exporter collects metrics of all arrays concurrently
+type exporter struct {
+	targets []*arrayTarget
+
+	scrapeError    *prometheus.Desc
+	scrapeDuration *prometheus.Desc
+
+	poolPhysicalCapacity  *prometheus.Desc
+	poolVirtualCapacity   *prometheus.Desc
+	poolFreePhysicalSpace *prometheus.Desc
+	poolFreeVirtualSpace  *prometheus.Desc
+	poolAllocatedPhysical *prometheus.Desc
+
+	volumeSize      *prometheus.Desc
+	volumeUsed      *prometheus.Desc
+	volumeAllocated *prometheus.Desc
+
+	tenantTotalPhysical     *prometheus.Desc
+	tenantAllocatedPhysical *prometheus.Desc
+	tenantTotalVirtual      *prometheus.Desc
+	tenantAllocatedVirtual  *prometheus.Desc
+
+	pluginHeartbeatValid *prometheus.Desc
+
+	hosts        *prometheus.Desc
+	hostClusters *prometheus.Desc
+	hostLuns     *prometheus.Desc
+}
+
+func newDesc(name string, help string, labels ...string) *prometheus.Desc {
+	return prometheus.NewDesc(prometheus.BuildFQName(namespace, "", name), help, append([]string{"array"}, labels...), nil)
+}
+
+func newExporter(targets []*arrayTarget) *exporter {
+	return &exporter{
+		targets: targets,
+
+		scrapeError:    newDesc("scrape_error", "1 if the last scrape of the array failed, 0 otherwise"),
+		scrapeDuration: newDesc("scrape_duration_seconds", "Duration of the last scrape of the array"),
+
+		poolPhysicalCapacity:  newDesc("pool_physical_capacity_bytes", "Pool physical capacity", "pool"),
+		poolVirtualCapacity:   newDesc("pool_virtual_capacity_bytes", "Pool virtual capacity", "pool"),
+		poolFreePhysicalSpace: newDesc("pool_free_physical_space_bytes", "Pool free physical space", "pool"),
+		poolFreeVirtualSpace:  newDesc("pool_free_virtual_space_bytes", "Pool free virtual space", "pool"),
+		poolAllocatedPhysical: newDesc("pool_allocated_physical_space_bytes", "Pool allocated physical space", "pool"),
+
+		volumeSize:      newDesc("volume_size_bytes", "Volume size", "volume", "pool", "type"),
+		volumeUsed:      newDesc("volume_used_bytes", "Volume used space", "volume", "pool", "type"),
+		volumeAllocated: newDesc("volume_allocated_bytes", "Volume allocated space", "volume", "pool", "type"),
+
+		tenantTotalPhysical:     newDesc("tenant_physical_capacity_bytes", "Tenant total physical capacity", "tenant"),
+		tenantAllocatedPhysical: newDesc("tenant_allocated_physical_space_bytes", "Tenant allocated physical space", "tenant"),
+		tenantTotalVirtual:      newDesc("tenant_virtual_capacity_bytes", "Tenant total virtual capacity", "tenant"),
+		tenantAllocatedVirtual:  newDesc("tenant_allocated_virtual_space_bytes", "Tenant allocated virtual space", "tenant"),
+
+		pluginHeartbeatValid: newDesc("plugin_heartbeat_valid", "1 if plugin heartbeat is valid, 0 otherwise", "plugin"),
+
+		hosts:        newDesc("hosts", "Number of hosts"),
+		hostClusters: newDesc("host_clusters", "Number of host clusters"),
+		hostLuns:     newDesc("host_luns", "Number of LUNs mapped to host", "host"),
+	}
+}
+
+//Describe implements prometheus.Collector
+func (e *exporter) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range []*prometheus.Desc{
+		e.scrapeError, e.scrapeDuration,
+		e.poolPhysicalCapacity, e.poolVirtualCapacity, e.poolFreePhysicalSpace, e.poolFreeVirtualSpace, e.poolAllocatedPhysical,
+		e.volumeSize, e.volumeUsed, e.volumeAllocated,
+		e.tenantTotalPhysical, e.tenantAllocatedPhysical, e.tenantTotalVirtual, e.tenantAllocatedVirtual,
+		e.pluginHeartbeatValid,
+		e.hosts, e.hostClusters, e.hostLuns,
+	} {
+		ch <- desc
+	}
+}
+
+//Collect implements prometheus.Collector, arrays are scraped concurrently
+func (e *exporter) Collect(ch chan<- prometheus.Metric) {
+
+	var wg sync.WaitGroup
+
+	for _, target := range e.targets {
+		wg.Add(1)
+		go func(target *arrayTarget) {
+			defer wg.Done()
+			e.collectTarget(target, ch)
+		}(target)
+	}
+
+	wg.Wait()
+}
+
+func (e *exporter) collectTarget(target *arrayTarget, ch chan<- prometheus.Metric) {
+
+	target.mu.Lock()
+	defer target.mu.Unlock()
+
+	start := time.Now()
+
+	scrapeError := 0.0
+	err := e.scrape(target, ch)
+	if err != nil {
+		log.Errorf("scrape of array %s failed, %s", target.name, err.Error())
+		target.loggedIn = false
+		scrapeError = 1
+	}
+
+	ch <- prometheus.MustNewConstMetric(e.scrapeError, prometheus.GaugeValue, scrapeError, target.name)
+	ch <- prometheus.MustNewConstMetric(e.scrapeDuration, prometheus.GaugeValue, time.Since(start).Seconds(), target.name)
+}
+
+func (e *exporter) scrape(target *arrayTarget, ch chan<- prometheus.Metric) error {
+
+	if !target.loggedIn {
+		err := target.client.Login()
+		if err != nil {
+			return fmt.Errorf("login failed, %s", err.Error())
+		}
+		target.loggedIn = true
+	}
+
+	client := target.client
+	if target.tenant != "" {
+		scoped, err := client.WithTenant(target.tenant)
+		if err != nil {
+			return err
+		}
+		client = scoped
+	}
+
+	name := target.name
+	gauge := func(desc *prometheus.Desc, value float64, labels ...string) {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, value, append([]string{name}, labels...)...)
+	}
+
+	pools, err := client.GetAllPools()
+	if err != nil {
+		return err
+	}
+	if pools != nil {
+		for _, pool := range *pools {
+			gauge(e.poolPhysicalCapacity, float64(pool.PhysicalCapacity), pool.Name)
+			gauge(e.poolVirtualCapacity, float64(pool.VirtualCapacity), pool.Name)
+			gauge(e.poolFreePhysicalSpace, float64(pool.FreePhysicalSpace), pool.Name)
+			gauge(e.poolFreeVirtualSpace, float64(pool.FreeVirtualSpace), pool.Name)
+			gauge(e.poolAllocatedPhysical, float64(pool.AllocatedPhysicalSpace), pool.Name)
+		}
+	}
+
+	volumes, err := client.GetAllVolumes()
+	if err != nil {
+		return err
+	}
+	if volumes != nil {
+		for _, volume := range *volumes {
+			gauge(e.volumeSize, float64(volume.Size), volume.Name, volume.PoolName, volume.Type)
+			gauge(e.volumeUsed, float64(volume.Used), volume.Name, volume.PoolName, volume.Type)
+			gauge(e.volumeAllocated, float64(volume.Allocated), volume.Name, volume.PoolName, volume.Type)
+		}
+	}
+
+	tenants, err := client.GetAllTenants()
+	if err != nil {
+		return err
+	}
+	if tenants != nil {
+		for _, tenant := range *tenants {
+			gauge(e.tenantTotalPhysical, float64(tenant.Capacity.TotalPhysicalCapacity), tenant.Name)
+			gauge(e.tenantAllocatedPhysical, float64(tenant.Capacity.AllocatedPhysicalSpace), tenant.Name)
+			gauge(e.tenantTotalVirtual, float64(tenant.Capacity.TotalVirtualCapacity), tenant.Name)
+			gauge(e.tenantAllocatedVirtual, float64(tenant.Capacity.AllocatedVirtualSpace), tenant.Name)
+		}
+	}
+
+	plugins, err := client.GetAllPlugins()
+	if err != nil {
+		return err
+	}
+	if plugins != nil {
+		for _, plugin := range *plugins {
+			valid := 0.0
+			if plugin.HeartbeatValid {
+				valid = 1
+			}
+			gauge(e.pluginHeartbeatValid, valid, plugin.Name)
+		}
+	}
+
+	hosts, err := client.GetAllHosts()
+	if err != nil {
+		return err
+	}
+	hostCount := 0
+	if hosts != nil {
+		hostCount = len(*hosts)
+		for _, host := range *hosts {
+			gauge(e.hostLuns, float64(len(host.Luns)), host.Name)
+		}
+	}
+	gauge(e.hosts, float64(hostCount))
+
+	clusters, err := client.GetAllHostClusters()
+	if err != nil {
+		return err
+	}
+	clusterCount := 0
+	if clusters != nil {
+		clusterCount = len(*clusters)
+	}
+	gauge(e.hostClusters, float64(clusterCount))
+
+	return nil
+}
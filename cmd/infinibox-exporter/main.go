@@ -0,0 +1,45 @@
+package main
+
+import (
+	"flag"
+	"github.com/devnal/infinibox-go-client"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	log "github.com/sirupsen/logrus"
+	"net/http"
+)
+
+func main() {
+
+	configPath := flag.String("config", "infinibox-exporter.json", "config file with array profiles, see infinibox.LoadProfiles")
+	listenAddress := flag.String("listen", ":9601", "address to expose metrics on")
+	debug := flag.Bool("debug", false, "enable debug logging")
+	flag.Parse()
+
+	if *debug {
+		log.SetLevel(log.DebugLevel)
+	}
+
+	profiles, err := infinibox.LoadProfiles(*configPath)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
+	targets := []*arrayTarget{}
+	for _, profile := range profiles {
+		client, err := infinibox.NewClient(profile.Config())
+		if err != nil {
+			log.Fatalf("unable to create client for array %s, %s", profile.Name, err.Error())
+		}
+		targets = append(targets, &arrayTarget{name: profile.Name, tenant: profile.Tenant, client: client})
+	}
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(newExporter(targets))
+
+	http.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+
+	log.Infof("Exporting metrics of %d arrays on %s/metrics", len(targets), *listenAddress)
+
+	log.Fatal(http.ListenAndServe(*listenAddress, nil))
+}
@@ -0,0 +1,267 @@
+package infinibox
+
+import (
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//Environment variables overriding configuration file values
+const (
+	EnvConfig          = "IBOX_CONFIG"
+	EnvProfile         = "IBOX_PROFILE"
+	EnvURL             = "IBOX_URL"
+	EnvUsername        = "IBOX_USERNAME"
+	EnvPassword        = "IBOX_PASSWORD"
+	EnvPasswordFile    = "IBOX_PASSWORD_FILE"
+	EnvPasswordCommand = "IBOX_PASSWORD_COMMAND"
+	EnvTenantID        = "IBOX_TENANT_ID"
+	EnvDebug           = "IBOX_DEBUG"
+)
+
+//Profile represents single array configuration in ibox_config.json shape
+type Profile struct {
+	Name            string   `json:"name,omitempty" yaml:"name,omitempty"`
+	Host            string   `json:"host" yaml:"host"`
+	URL             string   `json:"url,omitempty" yaml:"url,omitempty"`
+	Username        string   `json:"username" yaml:"username"`
+	Password        string   `json:"password,omitempty" yaml:"password,omitempty"`
+	PasswordFile    string   `json:"password_file,omitempty" yaml:"password_file,omitempty"`
+	PasswordCommand string   `json:"password_command,omitempty" yaml:"password_command,omitempty"`
+	TenantID        int64    `json:"tenant_id,omitempty" yaml:"tenant_id,omitempty"`
+	Tenant          string   `json:"tenant,omitempty" yaml:"tenant,omitempty"`
+	IscsiTargets1   []string `json:"iscsi_targets_1,omitempty" yaml:"iscsi_targets_1,omitempty"`
+	IscsiTargets2   []string `json:"iscsi_targets_2,omitempty" yaml:"iscsi_targets_2,omitempty"`
+	SystemSerial    int64    `json:"system_serial,omitempty" yaml:"system_serial,omitempty"`
+	PluginID        int64    `json:"plugin_id,omitempty" yaml:"plugin_id,omitempty"`
+	Debug           bool     `json:"debug,omitempty" yaml:"debug,omitempty"`
+}
+
+//profilesFile represents configuration file holding several named profiles
+type profilesFile struct {
+	DefaultProfile string             `json:"default_profile" yaml:"default_profile"`
+	Profiles       map[string]Profile `json:"profiles" yaml:"profiles"`
+}
+
+//LoadConfig loads default profile from path, IBOX_CONFIG when path is empty,
+//applies environment overrides and returns client configuration
+func LoadConfig(path string) (*Config, error) {
+	return LoadConfigProfile(path, os.Getenv(EnvProfile))
+}
+
+//LoadConfigProfile loads named profile from path and returns client configuration
+func LoadConfigProfile(path string, name string) (*Config, error) {
+
+	profile, err := LoadProfile(path, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return profile.Config(), nil
+}
+
+//LoadProfile loads named profile, or default profile for empty name, from JSON or YAML file,
+//applies environment overrides and resolves password, file is optional when environment
+//provides connection settings
+func LoadProfile(path string, name string) (*Profile, error) {
+
+	if path == "" {
+		path = os.Getenv(EnvConfig)
+	}
+
+	profile := &Profile{Name: name}
+
+	if path != "" {
+		profiles, defaultProfile, err := readProfiles(path)
+		if err != nil {
+			return nil, err
+		}
+		if name == "" {
+			name = defaultProfile
+		}
+		loaded, ok := profiles[name]
+		if !ok {
+			return nil, fmt.Errorf("profile %s not found in config %s", name, path)
+		}
+		profile = &loaded
+		profile.Name = name
+	}
+
+	err := profile.applyEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	err = profile.resolvePassword()
+	if err != nil {
+		return nil, err
+	}
+
+	if profile.URL == "" || profile.Username == "" {
+		return nil, fmt.Errorf("profile %s defines no host or username", profile.Name)
+	}
+
+	return profile, nil
+}
+
+//LoadProfiles loads all profiles from file, environment overrides are not applied
+func LoadProfiles(path string) ([]Profile, error) {
+
+	profiles, _, err := readProfiles(path)
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	loaded := []Profile{}
+	for _, name := range names {
+		profile := profiles[name]
+		profile.Name = name
+		profile.normalizeURL()
+		err = profile.resolvePassword()
+		if err != nil {
+			return nil, err
+		}
+		loaded = append(loaded, profile)
+	}
+
+	return loaded, nil
+}
+
+//readProfiles reads profiles file, single profile files are returned as "default" profile
+func readProfiles(path string) (map[string]Profile, string, error) {
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("unable to read config %s, %s", path, err.Error())
+	}
+
+	unmarshal := json.Unmarshal
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		unmarshal = yaml.Unmarshal
+	}
+
+	var file profilesFile
+	err = unmarshal(data, &file)
+	if err != nil {
+		return nil, "", fmt.Errorf("unable to parse config %s, %s", path, err.Error())
+	}
+
+	if len(file.Profiles) > 0 {
+		defaultProfile := file.DefaultProfile
+		if defaultProfile == "" && len(file.Profiles) == 1 {
+			for name := range file.Profiles {
+				defaultProfile = name
+			}
+		}
+		return file.Profiles, defaultProfile, nil
+	}
+
+	var profile Profile
+	err = unmarshal(data, &profile)
+	if err != nil {
+		return nil, "", fmt.Errorf("unable to parse config %s, %s", path, err.Error())
+	}
+
+	return map[string]Profile{"default": profile}, "default", nil
+}
+
+//applyEnv overrides profile values with IBOX_* environment variables
+func (p *Profile) applyEnv() error {
+
+	if value := os.Getenv(EnvURL); value != "" {
+		p.URL = value
+	}
+	if value := os.Getenv(EnvUsername); value != "" {
+		p.Username = value
+	}
+	if value := os.Getenv(EnvPassword); value != "" {
+		p.Password = value
+		p.PasswordFile = ""
+		p.PasswordCommand = ""
+	}
+	if value := os.Getenv(EnvPasswordFile); value != "" {
+		p.PasswordFile = value
+		p.PasswordCommand = ""
+	}
+	if value := os.Getenv(EnvPasswordCommand); value != "" {
+		p.PasswordCommand = value
+		p.PasswordFile = ""
+	}
+	if value := os.Getenv(EnvTenantID); value != "" {
+		tenantID, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid %s value %s", EnvTenantID, value)
+		}
+		p.TenantID = tenantID
+	}
+	if value := os.Getenv(EnvDebug); value != "" {
+		debug, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid %s value %s", EnvDebug, value)
+		}
+		p.Debug = debug
+	}
+
+	p.normalizeURL()
+
+	return nil
+}
+
+//normalizeURL builds URL from host, hosts without scheme are reached over https
+func (p *Profile) normalizeURL() {
+
+	if p.URL == "" {
+		p.URL = p.Host
+	}
+	if p.URL != "" && !strings.Contains(p.URL, "://") {
+		p.URL = "https://" + p.URL
+	}
+}
+
+//resolvePassword reads password from password file or command output when set
+func (p *Profile) resolvePassword() error {
+
+	switch {
+	case p.PasswordFile != "":
+		data, err := os.ReadFile(p.PasswordFile)
+		if err != nil {
+			return fmt.Errorf("unable to read password file for profile %s, %s", p.Name, err.Error())
+		}
+		p.Password = strings.TrimRight(string(data), "\r\n")
+
+	case p.PasswordCommand != "":
+		output, err := exec.Command("sh", "-c", p.PasswordCommand).Output()
+		if err != nil {
+			return fmt.Errorf("password command for profile %s failed, %s", p.Name, err.Error())
+		}
+		p.Password = strings.TrimRight(string(output), "\r\n")
+	}
+
+	return nil
+}
+
+//Config returns client configuration for profile
+func (p *Profile) Config() *Config {
+	return &Config{
+		URL:      p.URL,
+		Username: p.Username,
+		Password: p.Password,
+		TenantID: p.TenantID,
+		Debug:    p.Debug,
+
+		SystemSerial: p.SystemSerial,
+	}
+}
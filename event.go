@@ -0,0 +1,328 @@
+package infinibox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+//Event levels reported by IBOX
+const (
+	EventLevelInfo     = "INFO"
+	EventLevelWarning  = "WARNING"
+	EventLevelError    = "ERROR"
+	EventLevelCritical = "CRITICAL"
+)
+
+//Custom event codes accepted by IBOX for events created by clients
+const (
+	CustomEventInfo     = "CUSTOM_INFO_EVENT"
+	CustomEventWarning  = "CUSTOM_WARNING_EVENT"
+	CustomEventError    = "CUSTOM_ERROR_EVENT"
+	CustomEventCritical = "CUSTOM_CRITICAL_EVENT"
+)
+
+const (
+	eventsPageSize           = 1000
+	defaultEventPollInterval = 5 * time.Second
+	maxEventPollBackoff      = time.Minute
+)
+
+//EventData represents IBOX event data entry struct
+type EventData struct {
+	Name  string      `json:"name"`
+	Type  string      `json:"type"`
+	Value interface{} `json:"value"`
+}
+
+//Event represents IBOX event struct
+type Event struct {
+	ID               int64       `json:"id"`
+	Code             string      `json:"code"`
+	Level            string      `json:"level"`
+	Description      string      `json:"description"`
+	Timestamp        int64       `json:"timestamp"`
+	Reporter         string      `json:"reporter"`
+	Visibility       string      `json:"visibility"`
+	Username         string      `json:"username"`
+	SystemVersion    string      `json:"system_version"`
+	SeqNum           int64       `json:"seq_num"`
+	AffectedEntityID int64       `json:"affected_entity_id"`
+	SourceNodeID     int64       `json:"source_node_id"`
+	TenantID         int64       `json:"tenant_id,omitempty"`
+	Data             []EventData `json:"data"`
+}
+
+//Time returns event timestamp, IBOX reports timestamps in milliseconds
+func (e *Event) Time() time.Time {
+	return time.Unix(0, e.Timestamp*int64(time.Millisecond))
+}
+
+//EventFilter represents events query, zero value fields are not filtered on
+type EventFilter struct {
+	Level            string
+	Code             string
+	Since            time.Time
+	Until            time.Time
+	AffectedEntityID int64
+	AfterID          int64
+	//Limit caps number of events returned by GetEvents, zero returns all matching events,
+	//SubscribeEvents uses it as page size of single poll
+	Limit int
+	//PollInterval used by SubscribeEvents between polls, defaults to 5 seconds
+	PollInterval time.Duration
+}
+
+//pageSize returns events page size limited to IBOX maximum
+func (f EventFilter) pageSize() int {
+	if f.Limit <= 0 || f.Limit > eventsPageSize {
+		return eventsPageSize
+	}
+	return f.Limit
+}
+
+//query returns query parameters of filter
+func (f EventFilter) query() url.Values {
+
+	query := url.Values{}
+
+	if f.Level != "" {
+		query.Add("level", "eq:"+f.Level)
+	}
+	if f.Code != "" {
+		query.Add("code", "eq:"+f.Code)
+	}
+	if !f.Since.IsZero() {
+		query.Add("timestamp", fmt.Sprintf("ge:%d", f.Since.UnixNano()/int64(time.Millisecond)))
+	}
+	if !f.Until.IsZero() {
+		query.Add("timestamp", fmt.Sprintf("le:%d", f.Until.UnixNano()/int64(time.Millisecond)))
+	}
+	if f.AffectedEntityID != 0 {
+		query.Add("affected_entity_id", fmt.Sprintf("eq:%d", f.AffectedEntityID))
+	}
+	if f.AfterID != 0 {
+		query.Add("id", fmt.Sprintf("gt:%d", f.AfterID))
+	}
+
+	return query
+}
+
+//GetEvents returns events matching filter ordered by ID, pages are read until filter.Limit
+//events are collected or the last page is reached
+func (c *Client) GetEvents(filter EventFilter) (*[]Event, error) {
+
+	c.debugf("Getting events with filter %+v", filter)
+
+	url := "api/rest/events"
+	events := []Event{}
+
+	for page := 1; ; page++ {
+
+		request := c.request().SetMultiValueQueryParams(filter.query())
+		request.SetQueryParam("page", strconv.Itoa(page))
+		request.SetQueryParam("page_size", strconv.Itoa(filter.pageSize()))
+		request.SetQueryParam("sort", "id")
+
+		response, err := request.Get(url)
+
+		result, err := CheckAPIResponse(response, err)
+		if err != nil {
+			return nil, fmt.Errorf("error getting events, %s", err.Error())
+		}
+
+		if result.APIResult != nil {
+			var pageEvents []Event
+			err = json.Unmarshal(*result.APIResult, &pageEvents)
+			if err != nil {
+				return nil, fmt.Errorf("error getting events page %d, %s", page, err.Error())
+			}
+			events = append(events, pageEvents...)
+		}
+
+		if filter.Limit > 0 && len(events) >= filter.Limit {
+			events = events[:filter.Limit]
+			break
+		}
+
+		pagesTotal, _ := result.APIMetadata["pages_total"].(float64)
+		if page >= int(pagesTotal) {
+			break
+		}
+	}
+
+	c.debugf("Got %d events", len(events))
+
+	return &events, nil
+}
+
+//latestEventID returns ID of newest event, zero when there are no events
+func (c *Client) latestEventID() (int64, error) {
+
+	request := c.request()
+	request.SetQueryParam("page_size", "1")
+	request.SetQueryParam("sort", "-id")
+
+	url := "api/rest/events"
+	response, err := request.Get(url)
+
+	result, err := CheckAPIResponse(response, err)
+	if err != nil {
+		return 0, fmt.Errorf("error getting latest event, %s", err.Error())
+	}
+
+	events := []Event{}
+	if result.APIResult != nil {
+		err = json.Unmarshal(*result.APIResult, &events)
+		if err != nil {
+			return 0, fmt.Errorf("error getting latest event, %s", err.Error())
+		}
+	}
+
+	if len(events) == 0 {
+		return 0, nil
+	}
+	return events[0].ID, nil
+}
+
+//GetEvent returns event by ID
+func (c *Client) GetEvent(eventID int64) (*Event, error) {
+
+	c.debugf("Getting event ID: %d", eventID)
+
+	url := fmt.Sprintf("api/rest/events/%d", eventID)
+	response, err := c.request().Get(url)
+
+	result, err := CheckAPIResponse(response, err)
+	if err != nil {
+		return nil, fmt.Errorf("error getting event %d, %s", eventID, err.Error())
+	}
+
+	var event Event
+	err = json.Unmarshal(*result.APIResult, &event)
+	if err != nil {
+		return nil, fmt.Errorf("error getting event %d, %s", eventID, err.Error())
+	}
+
+	return &event, nil
+}
+
+//CreateCustomEvent creates custom event, code is one of CustomEvent codes
+func (c *Client) CreateCustomEvent(code string, description string, data map[string]string) (*Event, error) {
+
+	c.debugf("Creating custom event %s", code)
+
+	body := map[string]interface{}{
+		"code":        code,
+		"description": description,
+	}
+	if len(data) > 0 {
+		entries := []EventData{}
+		for name, value := range data {
+			entries = append(entries, EventData{Name: name, Type: "string", Value: value})
+		}
+		body["data"] = entries
+	}
+
+	url := "api/rest/events/custom"
+	response, err := c.request().SetBody(body).Post(url)
+
+	result, err := CheckAPIResponse(response, err)
+	if err != nil {
+		return nil, fmt.Errorf("error creating custom event %s, %s", code, err.Error())
+	}
+
+	var event Event
+	err = json.Unmarshal(*result.APIResult, &event)
+	if err != nil {
+		return nil, fmt.Errorf("error creating custom event %s, %s", code, err.Error())
+	}
+
+	c.debugf("Created custom event %s with ID %d", code, event.ID)
+
+	return &event, nil
+}
+
+//SubscribeEvents polls events matching filter newer than filter.AfterID and sends them
+//to returned channel in ID order, zero filter.AfterID subscribes to events newer than the
+//latest event when subscribing, polling errors are retried with exponential backoff,
+//channel is closed when ctx is cancelled
+func (c *Client) SubscribeEvents(ctx context.Context, filter EventFilter) <-chan Event {
+
+	events := make(chan Event)
+
+	interval := filter.PollInterval
+	if interval <= 0 {
+		interval = defaultEventPollInterval
+	}
+
+	scoped := c.WithContext(ctx)
+
+	//latest event is read before returning so events created after subscribing are not skipped,
+	//failed read is retried by polling goroutine
+	started := filter.AfterID != 0
+	if !started {
+		latest, err := scoped.latestEventID()
+		if err == nil {
+			filter.AfterID = latest
+			started = true
+		}
+	}
+
+	poll := filter
+	poll.Limit = filter.pageSize()
+
+	go func() {
+		defer close(events)
+
+		wait := time.Duration(0)
+		backoff := interval
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(wait):
+			}
+
+			var page *[]Event
+			var err error
+			if started {
+				page, err = scoped.GetEvents(poll)
+			} else {
+				poll.AfterID, err = scoped.latestEventID()
+				started = err == nil
+				page = &[]Event{}
+			}
+			if err != nil {
+				c.warnf("polling events after ID %d failed, retrying in %s, %s", poll.AfterID, backoff, err.Error())
+				wait = backoff
+				backoff *= 2
+				if backoff > maxEventPollBackoff {
+					backoff = maxEventPollBackoff
+				}
+				continue
+			}
+			backoff = interval
+
+			for _, event := range *page {
+				select {
+				case events <- event:
+					poll.AfterID = event.ID
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			wait = interval
+			if len(*page) >= poll.Limit {
+				wait = 0
+			}
+		}
+	}()
+
+	return events
+}
@@ -0,0 +1,108 @@
+package infinibox
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+//writeEventsPage writes events page of total pages
+func writeEventsPage(w http.ResponseWriter, events []Event, page int, pagesTotal int) {
+	writeResponse(w, http.StatusOK, map[string]interface{}{
+		"result":   events,
+		"error":    nil,
+		"metadata": map[string]interface{}{"ready": true, "page": page, "pages_total": pagesTotal},
+	})
+}
+
+func TestGetEventsPages(t *testing.T) {
+
+	pages := [][]Event{{{ID: 1}, {ID: 2}}, {{ID: 3}, {ID: 4}}, {{ID: 5}}}
+
+	tests := []struct {
+		name     string
+		limit    int
+		want     []int64
+		requests int
+	}{
+		{name: "all pages are read without limit", limit: 0, want: []int64{1, 2, 3, 4, 5}, requests: 3},
+		{name: "reading stops at limit", limit: 2, want: []int64{1, 2}, requests: 1},
+		{name: "limit spanning pages", limit: 3, want: []int64{1, 2, 3}, requests: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			ibox := newTestIBOX(t)
+			ibox.handle(http.MethodGet, "/api/rest/events", func(w http.ResponseWriter, r *http.Request) {
+				page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+				if page < 1 || page > len(pages) {
+					writeAPIError(w, http.StatusBadRequest, "BAD_PAGE", "page out of range")
+					return
+				}
+				if r.URL.Query().Get("level") != "eq:"+EventLevelError {
+					t.Errorf("page %d query %s misses level filter", page, r.URL.RawQuery)
+				}
+				writeEventsPage(w, pages[page-1], page, len(pages))
+			})
+			client := ibox.client(t, Config{})
+
+			events, err := client.GetEvents(EventFilter{Level: EventLevelError, Limit: tt.limit})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ids := []int64{}
+			for _, event := range *events {
+				ids = append(ids, event.ID)
+			}
+			if len(ids) != len(tt.want) {
+				t.Fatalf("got events %v, want %v", ids, tt.want)
+			}
+			for i := range ids {
+				if ids[i] != tt.want[i] {
+					t.Fatalf("got events %v, want %v", ids, tt.want)
+				}
+			}
+			if n := ibox.count(http.MethodGet, "/api/rest/events"); n != tt.requests {
+				t.Fatalf("sent %d requests, want %d", n, tt.requests)
+			}
+		})
+	}
+}
+
+func TestSubscribeEventsStartsAfterLatestEvent(t *testing.T) {
+
+	ibox := newTestIBOX(t)
+	ibox.handle(http.MethodGet, "/api/rest/events", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		switch {
+		case query.Get("sort") == "-id":
+			writeEventsPage(w, []Event{{ID: 41}}, 1, 41)
+		case query.Get("id") == "gt:41":
+			writeEventsPage(w, []Event{{ID: 42}}, 1, 1)
+		case query.Get("id") == "gt:42":
+			writeEventsPage(w, []Event{}, 1, 1)
+		default:
+			t.Errorf("events polled with query %s", r.URL.RawQuery)
+			writeEventsPage(w, []Event{{ID: 1}}, 1, 1)
+		}
+	})
+	client := ibox.client(t, Config{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events := client.SubscribeEvents(ctx, EventFilter{PollInterval: 10 * time.Millisecond})
+
+	select {
+	case event := <-events:
+		if event.ID != 42 {
+			t.Fatalf("got event %d, want 42", event.ID)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no event received")
+	}
+}
@@ -0,0 +1,76 @@
+//Command cassette shows recording interactions with IBOX into cassette and replaying them offline.
+//
+//Record against IBOX:
+//
+//	go run ./examples/cassette -record -url https://ibox.example.com -username admin -password secret
+//
+//Replay recorded cassette without IBOX, auto generated snapshot name still matches recorded request:
+//
+//	go run ./examples/cassette
+//
+//Default cassette testdata/snapshot.json is synthetic, it was written by hand in IBOX response format
+//and not recorded from IBOX, record it again against IBOX to replay real traffic.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/devnal/infinibox-go-client"
+	"os"
+)
+
+func main() {
+
+	record := flag.Bool("record", false, "record interactions with IBOX instead of replaying them")
+	path := flag.String("cassette", "testdata/snapshot.json", "cassette file")
+	url := flag.String("url", "https://ibox.example.com", "IBOX management url")
+	username := flag.String("username", "admin", "IBOX username")
+	password := flag.String("password", "", "IBOX password")
+	volumename := flag.String("volume", "example-volume", "volume to snapshot")
+	flag.Parse()
+
+	if err := run(*record, *path, *url, *username, *password, *volumename); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(record bool, path string, url string, username string, password string, volumename string) error {
+
+	mode := infinibox.CassetteReplay
+	if record {
+		mode = infinibox.CassetteRecord
+	}
+
+	cassette, err := infinibox.NewCassette(path, mode)
+	if err != nil {
+		return err
+	}
+
+	client, err := infinibox.NewClient(&infinibox.Config{URL: url, Username: username, Password: password, Cassette: cassette})
+	if err != nil {
+		return err
+	}
+
+	if err := client.Login(); err != nil {
+		return err
+	}
+
+	volume, err := client.GetVolumeByName(volumename)
+	if err != nil {
+		return err
+	}
+
+	snapshot, err := volume.Snapshot(client, "")
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("volume %s snapshot %s id %d\n", volume.Name, snapshot.Name, snapshot.ID)
+
+	if record {
+		return cassette.Save()
+	}
+
+	return nil
+}
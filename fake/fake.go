@@ -0,0 +1,2545 @@
+//Package fake provides in-memory implementation of infinibox API for testing code depending on it
+package fake
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/devnal/infinibox-go-client"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+//Client keeps IBOX objects in memory and implements infinibox API, objects are stored as
+//copies so changes made by callers are visible only through client methods
+type Client struct {
+	mu     sync.Mutex
+	nextID int64
+	calls  []string
+	errors map[string]error
+
+	volumes    map[int64]infinibox.Volume
+	pools      map[int64]infinibox.Pool
+	hosts      map[int64]infinibox.Host
+	clusters   map[int64]*infinibox.HostCluster
+	tenants    map[int64]infinibox.Tenant
+	plugins    map[int64]infinibox.Plugin
+	luns       []infinibox.Lun
+	metadata   map[int64]map[string]infinibox.Metadata
+	initiators map[string]infinibox.Initiator
+	heartbeats map[int64][]infinibox.Heartbeat
+	chap       map[int64]infinibox.ChapCredentials
+	system     infinibox.System
+	features   map[string]bool
+	events     []infinibox.Event
+
+	protectedNames    []string
+	protectedPatterns []*regexp.Regexp
+	approval          infinibox.ApprovalPolicy
+	confirmations     map[string]string
+}
+
+var _ infinibox.API = (*Client)(nil)
+
+//NewClient returns empty fake client
+func NewClient() *Client {
+	return &Client{
+		nextID:     1,
+		errors:     map[string]error{},
+		volumes:    map[int64]infinibox.Volume{},
+		pools:      map[int64]infinibox.Pool{},
+		hosts:      map[int64]infinibox.Host{},
+		clusters:   map[int64]*infinibox.HostCluster{},
+		tenants:    map[int64]infinibox.Tenant{},
+		plugins:    map[int64]infinibox.Plugin{},
+		metadata:   map[int64]map[string]infinibox.Metadata{},
+		initiators: map[string]infinibox.Initiator{},
+		heartbeats: map[int64][]infinibox.Heartbeat{},
+		chap:       map[int64]infinibox.ChapCredentials{},
+		system: infinibox.System{
+			Name:             "fake",
+			SerialNumber:     1,
+			ModelName:        "InfiniBox",
+			Version:          "7.3",
+			OperationalState: infinibox.SystemOperationalState{State: "ACTIVE"},
+			APIReady:         true,
+		},
+		features:      map[string]bool{},
+		confirmations: map[string]string{},
+	}
+}
+
+//SetError makes method return err instead of being performed, nil err restores method
+func (c *Client) SetError(method string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err == nil {
+		delete(c.errors, method)
+		return
+	}
+	c.errors[method] = err
+}
+
+//Calls returns names of called methods in order they were called
+func (c *Client) Calls() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string{}, c.calls...)
+}
+
+//AddInitiator adds initiator seen by IBOX
+func (c *Client) AddInitiator(initiator infinibox.Initiator) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.initiators[initiator.Address] = initiator
+}
+
+//Heartbeats returns heartbeats sent by plugin
+func (c *Client) Heartbeats(pluginID int64) []infinibox.Heartbeat {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]infinibox.Heartbeat{}, c.heartbeats[pluginID]...)
+}
+
+//HostChap returns CHAP credentials set for host, IBOX does not return secrets so they are
+//kept apart from stored host
+func (c *Client) HostChap(hostID int64) (infinibox.ChapCredentials, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	credentials, ok := c.chap[hostID]
+	return credentials, ok
+}
+
+//SetSystem replaces system returned by GetSystem
+func (c *Client) SetSystem(system infinibox.System) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.system = system
+}
+
+//SetFeature makes Supports report feature as supported or not regardless of system version
+func (c *Client) SetFeature(feature string, supported bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.features[feature] = supported
+}
+
+//AddEvent adds event reported by IBOX and sets its id
+func (c *Client) AddEvent(event *infinibox.Event) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	event.ID = c.newID()
+	c.events = append(c.events, *event)
+}
+
+//Protect refuses deletion of objects with names or names matching patterns as client configured with
+//ProtectedNames and ProtectedPatterns does, current names of stored objects are checked
+func (c *Client) Protect(names []string, patterns []*regexp.Regexp) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.protectedNames = append([]string{}, names...)
+	c.protectedPatterns = append([]*regexp.Regexp{}, patterns...)
+}
+
+//SetApproval sets policy deciding operations which require approval, zero policy approves always
+func (c *Client) SetApproval(policy infinibox.ApprovalPolicy) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.approval = policy
+}
+
+//RequireApproval makes operation, one of infinibox Operation constants, ask for approval with IBOX
+//confirmation message, approval callback is called while client is locked and must not call client
+func (c *Client) RequireApproval(operation string, message string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.confirmations[operation] = message
+}
+
+//isProtected reports whether deletion of object named name is refused
+func (c *Client) isProtected(name string) bool {
+	for _, protected := range c.protectedNames {
+		if name == protected {
+			return true
+		}
+	}
+	for _, pattern := range c.protectedPatterns {
+		if pattern != nil && pattern.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+//approve refuses deletion of protected objects and applies approval policy to operations requiring
+//approval as infinibox client does, objectName is name of stored object
+func (c *Client) approve(operation string, objectType string, objectName string) error {
+
+	if operation == infinibox.OperationDelete && c.isProtected(objectName) {
+		return fmt.Errorf("refusing to %s %s %s, %w", operation, objectType, objectName, infinibox.ErrProtectedObject)
+	}
+
+	message, ok := c.confirmations[operation]
+	if !ok || c.approval.Mode == infinibox.ApproveAlways {
+		return nil
+	}
+
+	request := infinibox.ApprovalRequest{Operation: operation, ObjectType: objectType, ObjectName: objectName, Message: message}
+	if c.approval.Mode == infinibox.ApproveCallback && c.approval.Approve != nil && c.approval.Approve(request) {
+		return nil
+	}
+
+	return fmt.Errorf("%s of %s %s requires approval: %s, %w", operation, objectType, objectName, message, infinibox.ErrApprovalDenied)
+}
+
+//call records method call and returns error set for method
+func (c *Client) call(method string) error {
+	c.calls = append(c.calls, method)
+	return c.errors[method]
+}
+
+//newID returns next object id
+func (c *Client) newID() int64 {
+	id := c.nextID
+	c.nextID++
+	return id
+}
+
+//sorted returns objects ordered by id
+func sorted[T any](objects map[int64]T) *[]T {
+	ids := make([]int64, 0, len(objects))
+	for id := range objects {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	result := make([]T, 0, len(ids))
+	for _, id := range ids {
+		result = append(result, objects[id])
+	}
+	return &result
+}
+
+//findByName returns object with name, name is read by name function
+func findByName[T any](objects map[int64]T, name string, nameOf func(T) string) (T, bool) {
+	for _, object := range objects {
+		if nameOf(object) == name {
+			return object, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+func volumeName(v infinibox.Volume) string         { return v.Name }
+func poolName(p infinibox.Pool) string             { return p.Name }
+func hostName(h infinibox.Host) string             { return h.Name }
+func clusterName(hc *infinibox.HostCluster) string { return hc.Name }
+func tenantName(t infinibox.Tenant) string         { return t.Name }
+func pluginName(p infinibox.Plugin) string         { return p.Name }
+
+//volume returns stored volume with mapped flag set
+func (c *Client) volume(volumeID int64) (infinibox.Volume, bool) {
+	v, ok := c.volumes[volumeID]
+	if !ok {
+		return v, false
+	}
+	v.Mapped = len(c.volumeLuns(volumeID)) > 0
+	return v, true
+}
+
+//host returns stored host with its luns
+func (c *Client) host(hostID int64) (infinibox.Host, bool) {
+	h, ok := c.hosts[hostID]
+	if !ok {
+		return h, false
+	}
+	h.Luns = c.hostLuns(h)
+	h.Ports = append([]infinibox.Port{}, h.Ports...)
+	return h, true
+}
+
+//cluster returns copy of stored host cluster with its hosts and luns, HostCluster
+//holds lock so it is copied field by field
+func (c *Client) cluster(clusterID int64) *infinibox.HostCluster {
+	hc := c.clusters[clusterID]
+	return &infinibox.HostCluster{
+		Luns:          c.clusterLuns(clusterID),
+		Name:          hc.Name,
+		CreatedAt:     hc.CreatedAt,
+		HostType:      hc.HostType,
+		UpdatedAt:     hc.UpdatedAt,
+		SanClientType: hc.SanClientType,
+		Hosts:         c.clusterHosts(clusterID),
+		ID:            hc.ID,
+		TenantID:      hc.TenantID,
+	}
+}
+
+func (c *Client) volumeLuns(volumeID int64) []infinibox.Lun {
+	luns := []infinibox.Lun{}
+	for _, lun := range c.luns {
+		if lun.VolumeID == volumeID {
+			luns = append(luns, lun)
+		}
+	}
+	return luns
+}
+
+//hostLuns returns luns mapped to host directly or through its host cluster
+func (c *Client) hostLuns(h infinibox.Host) []infinibox.Lun {
+	luns := []infinibox.Lun{}
+	for _, lun := range c.luns {
+		if lun.Clustered && h.HostClusterID != 0 && lun.HostClusterID == int64(h.HostClusterID) {
+			lun.HostID = h.ID
+			luns = append(luns, lun)
+		} else if !lun.Clustered && lun.HostID == h.ID {
+			luns = append(luns, lun)
+		}
+	}
+	return luns
+}
+
+func (c *Client) clusterLuns(clusterID int64) []infinibox.Lun {
+	luns := []infinibox.Lun{}
+	for _, lun := range c.luns {
+		if lun.Clustered && lun.HostClusterID == clusterID {
+			luns = append(luns, lun)
+		}
+	}
+	return luns
+}
+
+func (c *Client) clusterHosts(clusterID int64) []infinibox.Host {
+	hosts := []infinibox.Host{}
+	for _, h := range *sorted(c.hosts) {
+		if int64(h.HostClusterID) == clusterID {
+			hosts = append(hosts, h)
+		}
+	}
+	return hosts
+}
+
+//deleteLuns removes luns matching keep returning false
+func (c *Client) deleteLuns(keep func(infinibox.Lun) bool) {
+	luns := c.luns[:0]
+	for _, lun := range c.luns {
+		if keep(lun) {
+			luns = append(luns, lun)
+		}
+	}
+	c.luns = luns
+}
+
+//firstAssignableLun lowest LUN number assigned by MapVolumeToHost and MapVolumeToCluster as by infinibox client
+const firstAssignableLun = 1
+
+//freeLun returns first lun number starting from startLun not used by luns
+func freeLun(luns []infinibox.Lun, startLun int) int {
+	if startLun < firstAssignableLun {
+		startLun = firstAssignableLun
+	}
+	used := map[int]bool{}
+	for _, lun := range luns {
+		used[lun.Lun] = true
+	}
+	for used[startLun] {
+		startLun++
+	}
+	return startLun
+}
+
+//objectType returns metadata object type of object with id
+func (c *Client) objectType(objectID int64) string {
+	switch {
+	case hasKey(c.volumes, objectID):
+		return "volume"
+	case hasKey(c.pools, objectID):
+		return "pool"
+	case hasKey(c.hosts, objectID):
+		return "host"
+	case hasKey(c.clusters, objectID):
+		return "cluster"
+	case hasKey(c.plugins, objectID):
+		return "plugin"
+	case hasKey(c.tenants, objectID):
+		return "tenant"
+	}
+	return ""
+}
+
+func hasKey[T any](objects map[int64]T, id int64) bool {
+	_, ok := objects[id]
+	return ok
+}
+
+//encodeValue converts metadata value to string representation stored by IBOX
+func encodeValue(value interface{}) (string, error) {
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
+
+//GetVolume returns volume by id
+func (c *Client) GetVolume(volumeID int64) (*infinibox.Volume, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("GetVolume"); err != nil {
+		return nil, err
+	}
+
+	v, ok := c.volume(volumeID)
+	if !ok {
+		return nil, fmt.Errorf("volume ID %d not found", volumeID)
+	}
+	return &v, nil
+}
+
+//GetVolumeByName returns volume by name
+func (c *Client) GetVolumeByName(volumename string) (*infinibox.Volume, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("GetVolumeByName"); err != nil {
+		return nil, err
+	}
+
+	v, ok := findByName(c.volumes, volumename, volumeName)
+	if !ok {
+		return nil, fmt.Errorf("volume %s not found", volumename)
+	}
+	v, _ = c.volume(v.ID)
+	return &v, nil
+}
+
+//GetAllVolumes returns all volumes
+func (c *Client) GetAllVolumes() (*[]infinibox.Volume, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("GetAllVolumes"); err != nil {
+		return nil, err
+	}
+
+	volumes := sorted(c.volumes)
+	for i := range *volumes {
+		(*volumes)[i], _ = c.volume((*volumes)[i].ID)
+	}
+	return volumes, nil
+}
+
+//CreateVolume stores volume and sets its id
+func (c *Client) CreateVolume(volume *infinibox.Volume) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("CreateVolume"); err != nil {
+		return err
+	}
+
+	if _, exists := findByName(c.volumes, volume.Name, volumeName); exists {
+		return fmt.Errorf("error creating volume: %s, volume already exists", volume.Name)
+	}
+	pool, ok := c.pools[volume.PoolID]
+	if !ok {
+		return fmt.Errorf("error creating volume: %s, pool ID %d not found", volume.Name, volume.PoolID)
+	}
+
+	if volume.Provtype == "" {
+		volume.Provtype = "THIN"
+	}
+	if volume.Type == "" {
+		volume.Type = "MASTER"
+	}
+	volume.ID = c.newID()
+	volume.PoolName = pool.Name
+	c.volumes[volume.ID] = *volume
+
+	return nil
+}
+
+//DeleteVolume deletes unmapped volume
+func (c *Client) DeleteVolume(volume *infinibox.Volume) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("DeleteVolume"); err != nil {
+		return err
+	}
+
+	stored, ok := c.volumes[volume.ID]
+	if !ok {
+		return fmt.Errorf("error deleting volume: %s, volume ID %d not found", volume.Name, volume.ID)
+	}
+	if err := c.approve(infinibox.OperationDelete, "volume", stored.Name); err != nil {
+		return fmt.Errorf("error deleting volume: %s, %w", volume.Name, err)
+	}
+	if len(c.volumeLuns(volume.ID)) > 0 {
+		return fmt.Errorf("error deleting volume: %s, volume is mapped", volume.Name)
+	}
+
+	delete(c.volumes, volume.ID)
+	delete(c.metadata, volume.ID)
+
+	return nil
+}
+
+//RenameVolume renames volume
+func (c *Client) RenameVolume(volume *infinibox.Volume, name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("RenameVolume"); err != nil {
+		return err
+	}
+
+	stored, ok := c.volumes[volume.ID]
+	if !ok {
+		return fmt.Errorf("failed to rename volume %s, volume ID %d not found", volume.Name, volume.ID)
+	}
+	if other, exists := findByName(c.volumes, name, volumeName); exists && other.ID != volume.ID {
+		return fmt.Errorf("failed to rename volume %s, volume %s already exists", volume.Name, name)
+	}
+	if err := c.approve(infinibox.OperationUpdate, "volume", stored.Name); err != nil {
+		return fmt.Errorf("failed to rename volume %s, %w", volume.Name, err)
+	}
+
+	stored.Name = name
+	c.volumes[volume.ID] = stored
+	volume.Name = name
+
+	return nil
+}
+
+//ResizeVolume sets volume size
+func (c *Client) ResizeVolume(volume *infinibox.Volume, size uint64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("ResizeVolume"); err != nil {
+		return err
+	}
+
+	stored, ok := c.volumes[volume.ID]
+	if !ok {
+		return fmt.Errorf("failed to update size of volume %s, volume ID %d not found", volume.Name, volume.ID)
+	}
+	if err := c.approve(infinibox.OperationUpdate, "volume", stored.Name); err != nil {
+		return fmt.Errorf("failed to update size of volume %s, %w", volume.Name, err)
+	}
+
+	stored.Size = size
+	c.volumes[volume.ID] = stored
+	volume.Size = size
+
+	return nil
+}
+
+//UpdateVolumeProvisioning sets volume provisioning type, THIN or THICK
+func (c *Client) UpdateVolumeProvisioning(volume *infinibox.Volume, provtype string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("UpdateVolumeProvisioning"); err != nil {
+		return err
+	}
+
+	stored, ok := c.volumes[volume.ID]
+	if !ok {
+		return fmt.Errorf("failed to update provisioning type %s, volume ID %d not found", volume.Name, volume.ID)
+	}
+	if provtype != "THIN" && provtype != "THICK" {
+		return fmt.Errorf("failed to update provisioning type %s, invalid provisioning type %s", volume.Name, provtype)
+	}
+	if err := c.approve(infinibox.OperationUpdate, "volume", stored.Name); err != nil {
+		return fmt.Errorf("failed to update provisioning type %s, %w", volume.Name, err)
+	}
+
+	stored.Provtype = provtype
+	c.volumes[volume.ID] = stored
+	volume.Provtype = provtype
+
+	return nil
+}
+
+//SnapshotVolume creates volume snapshot
+func (c *Client) SnapshotVolume(volume *infinibox.Volume, name string) (*infinibox.Volume, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("SnapshotVolume"); err != nil {
+		return nil, err
+	}
+
+	parent, ok := c.volumes[volume.ID]
+	if !ok {
+		return nil, fmt.Errorf("error creating snapshot of volume: %s, volume ID %d not found", volume.Name, volume.ID)
+	}
+
+	id := c.newID()
+	if name == "" {
+		name = fmt.Sprintf("auto-snapshot-%d", id)
+	}
+	if _, exists := findByName(c.volumes, name, volumeName); exists {
+		return nil, fmt.Errorf("error creating snapshot of volume: %s, volume %s already exists", volume.Name, name)
+	}
+
+	snapshot := parent
+	snapshot.ID = id
+	snapshot.Name = name
+	snapshot.ParentID = parent.ID
+	snapshot.Type = "SNAPSHOT"
+	snapshot.Depth = parent.Depth + 1
+	snapshot.HasChildren = false
+	c.volumes[id] = snapshot
+
+	parent.HasChildren = true
+	c.volumes[parent.ID] = parent
+
+	return &snapshot, nil
+}
+
+//RestoreVolume restores volume from its snapshot
+func (c *Client) RestoreVolume(volume *infinibox.Volume, snapshotID uint64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("RestoreVolume"); err != nil {
+		return err
+	}
+
+	stored, ok := c.volumes[volume.ID]
+	if !ok {
+		return fmt.Errorf("error restoring volume: %s, volume ID %d not found", volume.Name, volume.ID)
+	}
+	snapshot, ok := c.volumes[int64(snapshotID)]
+	if !ok || snapshot.ParentID != volume.ID {
+		return fmt.Errorf("error restoring volume: %s, snapshot ID %d of volume not found", volume.Name, snapshotID)
+	}
+	if err := c.approve(infinibox.OperationRestore, "volume", stored.Name); err != nil {
+		return fmt.Errorf("error restoring volume: %s from snapshot ID %d, %w", volume.Name, snapshotID, err)
+	}
+
+	stored.Size = snapshot.Size
+	c.volumes[volume.ID] = stored
+
+	return nil
+}
+
+//RefreshVolume refreshes volume snapshot from volume
+func (c *Client) RefreshVolume(volume *infinibox.Volume, snapshotID uint64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("RefreshVolume"); err != nil {
+		return err
+	}
+
+	stored, ok := c.volumes[volume.ID]
+	if !ok {
+		return fmt.Errorf("error refreshing volume: %s to snapshot ID %d, volume ID %d not found", volume.Name, snapshotID, volume.ID)
+	}
+	snapshot, ok := c.volumes[int64(snapshotID)]
+	if !ok || snapshot.ParentID != volume.ID {
+		return fmt.Errorf("error refreshing volume: %s to snapshot ID %d, snapshot of volume not found", volume.Name, snapshotID)
+	}
+	if err := c.approve(infinibox.OperationRefresh, "volume", stored.Name); err != nil {
+		return fmt.Errorf("error refreshing volume: %s to snapshot ID %d, %w", volume.Name, snapshotID, err)
+	}
+
+	snapshot.Size = stored.Size
+	c.volumes[snapshot.ID] = snapshot
+
+	return nil
+}
+
+//MapVolumeToHost maps volume to host using first free LUN starting from startLun
+func (c *Client) MapVolumeToHost(volume *infinibox.Volume, host *infinibox.Host, startLun int) (*infinibox.Lun, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("MapVolumeToHost"); err != nil {
+		return nil, err
+	}
+
+	stored, ok := c.volumes[volume.ID]
+	if !ok {
+		return nil, fmt.Errorf("error mapping volume %s to host %s, volume ID %d not found", volume.Name, host.Name, volume.ID)
+	}
+	h, ok := c.hosts[host.ID]
+	if !ok {
+		return nil, fmt.Errorf("error mapping volume %s to host %s, host ID %d not found", volume.Name, host.Name, host.ID)
+	}
+
+	luns := c.hostLuns(h)
+	for _, lun := range luns {
+		if lun.VolumeID == volume.ID {
+			return &lun, nil
+		}
+	}
+
+	if err := c.approve(infinibox.OperationMap, "volume", stored.Name); err != nil {
+		return nil, fmt.Errorf("error mapping volume %s to host %s, %w", volume.Name, host.Name, err)
+	}
+
+	lun := infinibox.Lun{ID: c.newID(), Lun: freeLun(luns, startLun), VolumeID: volume.ID, HostID: host.ID}
+	c.luns = append(c.luns, lun)
+
+	return &lun, nil
+}
+
+//MapVolumeToCluster maps volume to host cluster using first LUN starting from startLun
+//which is free on the cluster and all of its hosts
+func (c *Client) MapVolumeToCluster(volume *infinibox.Volume, cluster *infinibox.HostCluster, startLun int) (*infinibox.Lun, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("MapVolumeToCluster"); err != nil {
+		return nil, err
+	}
+
+	stored, ok := c.volumes[volume.ID]
+	if !ok {
+		return nil, fmt.Errorf("error mapping volume %s to host cluster %s, volume ID %d not found", volume.Name, cluster.Name, volume.ID)
+	}
+	if _, ok := c.clusters[cluster.ID]; !ok {
+		return nil, fmt.Errorf("error mapping volume %s to host cluster %s, host cluster ID %d not found", volume.Name, cluster.Name, cluster.ID)
+	}
+
+	luns := c.clusterLuns(cluster.ID)
+	for _, lun := range luns {
+		if lun.VolumeID == volume.ID {
+			return &lun, nil
+		}
+	}
+	for _, h := range c.clusterHosts(cluster.ID) {
+		luns = append(luns, c.hostLuns(h)...)
+	}
+
+	if err := c.approve(infinibox.OperationMap, "volume", stored.Name); err != nil {
+		return nil, fmt.Errorf("error mapping volume %s to host cluster %s, %w", volume.Name, cluster.Name, err)
+	}
+
+	lun := infinibox.Lun{ID: c.newID(), Lun: freeLun(luns, startLun), VolumeID: volume.ID, Clustered: true, HostClusterID: cluster.ID}
+	c.luns = append(c.luns, lun)
+
+	return &lun, nil
+}
+
+//UnMapVolume unmaps volume from all hosts and host clusters
+func (c *Client) UnMapVolume(volume *infinibox.Volume) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("UnMapVolume"); err != nil {
+		return err
+	}
+
+	stored, ok := c.volumes[volume.ID]
+	if !ok {
+		return fmt.Errorf("error unmapping volume %s luns, volume ID %d not found", volume.Name, volume.ID)
+	}
+	if len(c.volumeLuns(volume.ID)) > 0 {
+		if err := c.approve(infinibox.OperationUnmap, "volume", stored.Name); err != nil {
+			return fmt.Errorf("error unmapping volume %s luns, %w", volume.Name, err)
+		}
+	}
+
+	c.deleteLuns(func(lun infinibox.Lun) bool { return lun.VolumeID != volume.ID })
+
+	return nil
+}
+
+//UnMapAndDeleteVolume unmaps volume from all hosts and host clusters and deletes it
+func (c *Client) UnMapAndDeleteVolume(volume *infinibox.Volume) (*infinibox.UnMapDeleteReport, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	report := &infinibox.UnMapDeleteReport{VolumeID: volume.ID, VolumeName: volume.Name}
+	if err := c.call("UnMapAndDeleteVolume"); err != nil {
+		return report, err
+	}
+
+	stored, ok := c.volumes[volume.ID]
+	if !ok {
+		return report, fmt.Errorf("error unmapping and deleting volume %s, volume ID %d not found", volume.Name, volume.ID)
+	}
+	if err := c.approve(infinibox.OperationDelete, "volume", stored.Name); err != nil {
+		return report, fmt.Errorf("error unmapping and deleting volume %s, %w", volume.Name, err)
+	}
+
+	report.Mappings = c.volumeLuns(volume.ID)
+	if len(report.Mappings) > 0 {
+		if err := c.approve(infinibox.OperationUnmap, "volume", stored.Name); err != nil {
+			return report, fmt.Errorf("error unmapping and deleting volume %s, %w", volume.Name, err)
+		}
+	}
+	report.Unmapped = append([]infinibox.Lun{}, report.Mappings...)
+	c.deleteLuns(func(lun infinibox.Lun) bool { return lun.VolumeID != volume.ID })
+
+	delete(c.volumes, volume.ID)
+	delete(c.metadata, volume.ID)
+	report.Deleted = true
+
+	return report, nil
+}
+
+//GetVolumeLUNs returns volume LUNs
+func (c *Client) GetVolumeLUNs(volume *infinibox.Volume) (*[]infinibox.Lun, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("GetVolumeLUNs"); err != nil {
+		return nil, err
+	}
+
+	if _, ok := c.volumes[volume.ID]; !ok {
+		return nil, fmt.Errorf("error getting volume %s luns, volume ID %d not found", volume.Name, volume.ID)
+	}
+
+	luns := c.volumeLuns(volume.ID)
+	return &luns, nil
+}
+
+//GetPool returns pool by id
+func (c *Client) GetPool(poolID int64) (*infinibox.Pool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("GetPool"); err != nil {
+		return nil, err
+	}
+
+	p, ok := c.pools[poolID]
+	if !ok {
+		return nil, fmt.Errorf("pool ID %d not found", poolID)
+	}
+	return &p, nil
+}
+
+//GetPoolByName returns pool by name
+func (c *Client) GetPoolByName(poolname string) (*infinibox.Pool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("GetPoolByName"); err != nil {
+		return nil, err
+	}
+
+	p, ok := findByName(c.pools, poolname, poolName)
+	if !ok {
+		return nil, fmt.Errorf("pool %s not found", poolname)
+	}
+	return &p, nil
+}
+
+//GetAllPools returns all pools
+func (c *Client) GetAllPools() (*[]infinibox.Pool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("GetAllPools"); err != nil {
+		return nil, err
+	}
+	return sorted(c.pools), nil
+}
+
+//CreatePool stores pool and sets its id
+func (c *Client) CreatePool(pool *infinibox.Pool) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("CreatePool"); err != nil {
+		return err
+	}
+
+	if _, exists := findByName(c.pools, pool.Name, poolName); exists {
+		return fmt.Errorf("error creating pool: %s, pool already exists", pool.Name)
+	}
+
+	pool.ID = c.newID()
+	c.pools[pool.ID] = *pool
+
+	return nil
+}
+
+//DeletePool deletes pool without volumes
+func (c *Client) DeletePool(pool *infinibox.Pool) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("DeletePool"); err != nil {
+		return err
+	}
+
+	stored, ok := c.pools[pool.ID]
+	if !ok {
+		return fmt.Errorf("error deleting pool: %s, pool ID %d not found", pool.Name, pool.ID)
+	}
+	if err := c.approve(infinibox.OperationDelete, "pool", stored.Name); err != nil {
+		return fmt.Errorf("error deleting pool: %s, %w", pool.Name, err)
+	}
+	for _, v := range c.volumes {
+		if v.PoolID == pool.ID {
+			return fmt.Errorf("error deleting pool: %s, pool contains volume %s", pool.Name, v.Name)
+		}
+	}
+
+	delete(c.pools, pool.ID)
+	delete(c.metadata, pool.ID)
+
+	return nil
+}
+
+//RenamePool renames pool
+func (c *Client) RenamePool(pool *infinibox.Pool, name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("RenamePool"); err != nil {
+		return err
+	}
+
+	stored, ok := c.pools[pool.ID]
+	if !ok {
+		return fmt.Errorf("failed to rename pool %s, pool ID %d not found", pool.Name, pool.ID)
+	}
+	if other, exists := findByName(c.pools, name, poolName); exists && other.ID != pool.ID {
+		return fmt.Errorf("failed to rename pool %s, pool %s already exists", pool.Name, name)
+	}
+	if err := c.approve(infinibox.OperationUpdate, "pool", stored.Name); err != nil {
+		return fmt.Errorf("failed to rename pool %s, %w", pool.Name, err)
+	}
+
+	stored.Name = name
+	c.pools[pool.ID] = stored
+	pool.Name = name
+
+	for id, v := range c.volumes {
+		if v.PoolID == pool.ID {
+			v.PoolName = name
+			c.volumes[id] = v
+		}
+	}
+
+	return nil
+}
+
+//UpdatePoolPhysicalCapacity sets pool physical capacity
+func (c *Client) UpdatePoolPhysicalCapacity(pool *infinibox.Pool, capacity uint64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("UpdatePoolPhysicalCapacity"); err != nil {
+		return err
+	}
+
+	stored, ok := c.pools[pool.ID]
+	if !ok {
+		return fmt.Errorf("failed to update pool %s PhysicalCapacity, pool ID %d not found", pool.Name, pool.ID)
+	}
+	if err := c.approve(infinibox.OperationUpdate, "pool", stored.Name); err != nil {
+		return fmt.Errorf("failed to update pool %s PhysicalCapacity, %w", pool.Name, err)
+	}
+
+	stored.PhysicalCapacity = capacity
+	c.pools[pool.ID] = stored
+	pool.PhysicalCapacity = capacity
+
+	return nil
+}
+
+//UpdatePoolVirtualCapacity sets pool virtual capacity
+func (c *Client) UpdatePoolVirtualCapacity(pool *infinibox.Pool, capacity uint64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("UpdatePoolVirtualCapacity"); err != nil {
+		return err
+	}
+
+	stored, ok := c.pools[pool.ID]
+	if !ok {
+		return fmt.Errorf("failed to update pool %s VirtualCapacity, pool ID %d not found", pool.Name, pool.ID)
+	}
+	if err := c.approve(infinibox.OperationUpdate, "pool", stored.Name); err != nil {
+		return fmt.Errorf("failed to update pool %s VirtualCapacity, %w", pool.Name, err)
+	}
+
+	stored.VirtualCapacity = capacity
+	c.pools[pool.ID] = stored
+	pool.VirtualCapacity = capacity
+
+	return nil
+}
+
+//GetHost returns host by id
+func (c *Client) GetHost(hostID int64) (*infinibox.Host, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("GetHost"); err != nil {
+		return nil, err
+	}
+
+	h, ok := c.host(hostID)
+	if !ok {
+		return nil, fmt.Errorf("host ID %d not found", hostID)
+	}
+	return &h, nil
+}
+
+//GetHostByName returns host by name
+func (c *Client) GetHostByName(hostname string) (*infinibox.Host, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("GetHostByName"); err != nil {
+		return nil, err
+	}
+
+	h, ok := findByName(c.hosts, hostname, hostName)
+	if !ok {
+		return nil, fmt.Errorf("host %s not found", hostname)
+	}
+	h, _ = c.host(h.ID)
+	return &h, nil
+}
+
+//GetAllHosts returns all hosts
+func (c *Client) GetAllHosts() (*[]infinibox.Host, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("GetAllHosts"); err != nil {
+		return nil, err
+	}
+
+	hosts := sorted(c.hosts)
+	for i := range *hosts {
+		(*hosts)[i], _ = c.host((*hosts)[i].ID)
+	}
+	return hosts, nil
+}
+
+//CreateHost stores host and sets its id
+func (c *Client) CreateHost(host *infinibox.Host) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("CreateHost"); err != nil {
+		return err
+	}
+
+	if _, exists := findByName(c.hosts, host.Name, hostName); exists {
+		return fmt.Errorf("error creating host: %s, host already exists", host.Name)
+	}
+
+	host.ID = c.newID()
+	stored := *host
+	stored.Luns = nil
+	stored.Ports = nil
+	c.hosts[host.ID] = stored
+
+	return nil
+}
+
+//DeleteHost deletes host without mapped LUNs, LUNs of its host cluster do not prevent deletion
+func (c *Client) DeleteHost(host *infinibox.Host) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("DeleteHost"); err != nil {
+		return err
+	}
+
+	stored, ok := c.hosts[host.ID]
+	if !ok {
+		return fmt.Errorf("error deleting host: %s, host ID %d not found", host.Name, host.ID)
+	}
+	if err := c.approve(infinibox.OperationDelete, "host", stored.Name); err != nil {
+		return fmt.Errorf("error deleting host: %s, %w", host.Name, err)
+	}
+	for _, lun := range c.luns {
+		if !lun.Clustered && lun.HostID == host.ID {
+			return fmt.Errorf("error deleting host: %s, volume ID %d is mapped to host", host.Name, lun.VolumeID)
+		}
+	}
+
+	delete(c.hosts, host.ID)
+	delete(c.metadata, host.ID)
+	delete(c.chap, host.ID)
+
+	return nil
+}
+
+//UpdateHost updates host name and security attributes set on host, secrets are stored as
+//CHAP credentials of host
+func (c *Client) UpdateHost(host *infinibox.Host) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("UpdateHost"); err != nil {
+		return err
+	}
+
+	stored, ok := c.hosts[host.ID]
+	if !ok {
+		return fmt.Errorf("host update failed, error: host ID %d not found", host.ID)
+	}
+	if other, exists := findByName(c.hosts, host.Name, hostName); exists && other.ID != host.ID {
+		return fmt.Errorf("error updating host: %s, host already exists", host.Name)
+	}
+	if host.SecurityMethod != "" {
+		if err := infinibox.ValidateSecurityMethod(host.SecurityMethod); err != nil {
+			return fmt.Errorf("host update failed, error: %s", err.Error())
+		}
+	}
+	if err := c.approve(infinibox.OperationUpdate, "host", stored.Name); err != nil {
+		return fmt.Errorf("error updating host: %s, %w", host.Name, err)
+	}
+
+	credentials := c.chap[host.ID]
+	stored.Name = host.Name
+	if host.SecurityMethod != "" {
+		stored.SecurityMethod = strings.ToUpper(host.SecurityMethod)
+	}
+	if host.SecurityChapInboundUsername != "" {
+		stored.SecurityChapInboundUsername = host.SecurityChapInboundUsername
+		credentials.InboundUsername = host.SecurityChapInboundUsername
+	}
+	if host.SecurityChapInboundSecret != "" {
+		stored.SecurityChapHasInboundSecret = true
+		credentials.InboundSecret = host.SecurityChapInboundSecret
+	}
+	if host.SecurityChapOutboundUsername != "" {
+		stored.SecurityChapOutboundUsername = host.SecurityChapOutboundUsername
+		credentials.OutboundUsername = host.SecurityChapOutboundUsername
+	}
+	if host.SecurityChapOutboundSecret != "" {
+		stored.SecurityChapHasOutboundSecret = true
+		credentials.OutboundSecret = host.SecurityChapOutboundSecret
+	}
+	c.hosts[host.ID] = stored
+	if credentials != (infinibox.ChapCredentials{}) {
+		c.chap[host.ID] = credentials
+	}
+
+	*host, _ = c.host(host.ID)
+
+	return nil
+}
+
+//RenameHost renames host
+func (c *Client) RenameHost(host *infinibox.Host, name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("RenameHost"); err != nil {
+		return err
+	}
+
+	stored, ok := c.hosts[host.ID]
+	if !ok {
+		return fmt.Errorf("failed to rename host %s, host ID %d not found", host.Name, host.ID)
+	}
+	if other, exists := findByName(c.hosts, name, hostName); exists && other.ID != host.ID {
+		return fmt.Errorf("failed to rename host %s, host %s already exists", host.Name, name)
+	}
+	if err := c.approve(infinibox.OperationUpdate, "host", stored.Name); err != nil {
+		return fmt.Errorf("failed to rename host %s, %w", host.Name, err)
+	}
+
+	stored.Name = name
+	c.hosts[host.ID] = stored
+	host.Name = name
+
+	return nil
+}
+
+//AddHostPort adds port to host, port address must not belong to other host
+func (c *Client) AddHostPort(host *infinibox.Host, port *infinibox.Port) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("AddHostPort"); err != nil {
+		return err
+	}
+
+	stored, ok := c.hosts[host.ID]
+	if !ok {
+		return fmt.Errorf("error adding port to host: %s, host ID %d not found", host.Name, host.ID)
+	}
+	if err := c.approve(infinibox.OperationAddPort, "host", stored.Name); err != nil {
+		return fmt.Errorf("error adding port to host: %s, %w", host.Name, err)
+	}
+	for _, h := range c.hosts {
+		for _, p := range h.Ports {
+			if p.Address == port.Address {
+				return fmt.Errorf("error adding port to host: %s, address %s belongs to host %s", host.Name, port.Address, h.Name)
+			}
+		}
+	}
+
+	port.HostID = host.ID
+	stored.Ports = append(append([]infinibox.Port{}, stored.Ports...), *port)
+	c.hosts[host.ID] = stored
+
+	return nil
+}
+
+//GetHostPorts returns host ports
+func (c *Client) GetHostPorts(host *infinibox.Host) (*[]infinibox.Port, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("GetHostPorts"); err != nil {
+		return nil, err
+	}
+
+	h, ok := c.host(host.ID)
+	if !ok {
+		return nil, fmt.Errorf("error getting host %s ports, host ID %d not found", host.Name, host.ID)
+	}
+	return &h.Ports, nil
+}
+
+//AddHostLUN maps lun to host, lun number must be free on host
+func (c *Client) AddHostLUN(host *infinibox.Host, lun *infinibox.Lun) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("AddHostLUN"); err != nil {
+		return err
+	}
+
+	h, ok := c.hosts[host.ID]
+	if !ok {
+		return fmt.Errorf("error adding lun to host: %s, host ID %d not found", host.Name, host.ID)
+	}
+	if _, ok := c.volumes[lun.VolumeID]; !ok {
+		return fmt.Errorf("error adding lun to host: %s, volume ID %d not found", host.Name, lun.VolumeID)
+	}
+	for _, existing := range c.hostLuns(h) {
+		if existing.Lun == lun.Lun {
+			return fmt.Errorf("error adding lun to host: %s, LUN %d is used by volume ID %d", host.Name, lun.Lun, existing.VolumeID)
+		}
+	}
+	if err := c.approve(infinibox.OperationMap, "host", h.Name); err != nil {
+		return fmt.Errorf("error adding lun to host: %s, %w", host.Name, err)
+	}
+
+	lun.ID = c.newID()
+	lun.HostID = host.ID
+	lun.Clustered = false
+	lun.HostClusterID = 0
+	c.luns = append(c.luns, *lun)
+
+	return nil
+}
+
+//DeleteHostLUN unmaps host lun number
+func (c *Client) DeleteHostLUN(host *infinibox.Host, lunID int) (*infinibox.Lun, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("DeleteHostLUN"); err != nil {
+		return nil, err
+	}
+
+	for _, lun := range c.luns {
+		if !lun.Clustered && lun.HostID == host.ID && lun.Lun == lunID {
+			if err := c.approve(infinibox.OperationUnmap, "host", c.hosts[host.ID].Name); err != nil {
+				return nil, fmt.Errorf("error deleting host: %s lun ID %d, %w", host.Name, lunID, err)
+			}
+			c.deleteLuns(func(l infinibox.Lun) bool { return l.ID != lun.ID })
+			return &lun, nil
+		}
+	}
+
+	return nil, fmt.Errorf("error deleting host: %s lun ID %d, lun not found", host.Name, lunID)
+}
+
+//UnMapVolumeFromHost unmaps volume mapped to host directly, LUNs of its host cluster are not unmapped
+func (c *Client) UnMapVolumeFromHost(host *infinibox.Host, volumeID uint64) (*infinibox.Lun, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("UnMapVolumeFromHost"); err != nil {
+		return nil, err
+	}
+
+	for _, lun := range c.luns {
+		if !lun.Clustered && lun.HostID == host.ID && lun.VolumeID == int64(volumeID) {
+			if err := c.approve(infinibox.OperationUnmap, "host", c.hosts[host.ID].Name); err != nil {
+				return nil, fmt.Errorf("error umapping volume ID %d from host: %s, %w", volumeID, host.Name, err)
+			}
+			c.deleteLuns(func(l infinibox.Lun) bool { return l.ID != lun.ID })
+			return &lun, nil
+		}
+	}
+
+	return nil, fmt.Errorf("error umapping volume ID %d from host: %s, lun not found", volumeID, host.Name)
+}
+
+//GetHostLUNs returns host LUNs including LUNs of its host cluster
+func (c *Client) GetHostLUNs(host *infinibox.Host) (*[]infinibox.Lun, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("GetHostLUNs"); err != nil {
+		return nil, err
+	}
+
+	h, ok := c.hosts[host.ID]
+	if !ok {
+		return nil, fmt.Errorf("error getting host %s luns, host ID %d not found", host.Name, host.ID)
+	}
+
+	luns := c.hostLuns(h)
+	return &luns, nil
+}
+
+//setChap stores credentials of host and updates security attributes of stored and provided host
+func (c *Client) setChap(host *infinibox.Host, credentials infinibox.ChapCredentials) error {
+
+	stored, ok := c.hosts[host.ID]
+	if !ok {
+		return fmt.Errorf("failed to set CHAP for host %s, host ID %d not found", host.Name, host.ID)
+	}
+	if err := credentials.Validate(); err != nil {
+		return fmt.Errorf("failed to set CHAP for host %s, %s", host.Name, err.Error())
+	}
+
+	stored.SecurityMethod = credentials.SecurityMethod()
+	stored.SecurityChapInboundUsername = credentials.InboundUsername
+	stored.SecurityChapHasInboundSecret = true
+	stored.SecurityChapOutboundUsername = credentials.OutboundUsername
+	stored.SecurityChapHasOutboundSecret = credentials.OutboundUsername != ""
+	c.hosts[host.ID] = stored
+	c.chap[host.ID] = credentials
+
+	host.SecurityMethod = stored.SecurityMethod
+	host.SecurityChapInboundUsername = stored.SecurityChapInboundUsername
+	host.SecurityChapHasInboundSecret = stored.SecurityChapHasInboundSecret
+	host.SecurityChapOutboundUsername = stored.SecurityChapOutboundUsername
+	host.SecurityChapHasOutboundSecret = stored.SecurityChapHasOutboundSecret
+
+	return nil
+}
+
+//SetHostChap sets host CHAP or mutual CHAP credentials
+func (c *Client) SetHostChap(host *infinibox.Host, credentials infinibox.ChapCredentials) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("SetHostChap"); err != nil {
+		return err
+	}
+	return c.setChap(host, credentials)
+}
+
+//ClearHostChap disables host CHAP authentication
+func (c *Client) ClearHostChap(host *infinibox.Host) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("ClearHostChap"); err != nil {
+		return err
+	}
+
+	stored, ok := c.hosts[host.ID]
+	if !ok {
+		return fmt.Errorf("failed to clear CHAP for host %s, host ID %d not found", host.Name, host.ID)
+	}
+
+	stored.SecurityMethod = infinibox.SecurityMethodNone
+	c.hosts[host.ID] = stored
+	host.SecurityMethod = stored.SecurityMethod
+
+	return nil
+}
+
+//RotateHostChap generates new secrets for host CHAP usernames, calls onRotate with them and stores
+//them only after onRotate succeeds, credentials are returned with error when storing fails
+func (c *Client) RotateHostChap(host *infinibox.Host, onRotate infinibox.ChapRotateFunc) (*infinibox.ChapCredentials, error) {
+
+	credentials, err := c.newChapCredentials(host)
+	if err != nil {
+		return nil, err
+	}
+
+	if onRotate != nil {
+		if err := onRotate(host, *credentials); err != nil {
+			return nil, fmt.Errorf("failed to rotate CHAP for host %s, node update failed, %s", host.Name, err.Error())
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.setChap(host, *credentials); err != nil {
+		return credentials, err
+	}
+
+	return credentials, nil
+}
+
+//newChapCredentials generates new secrets for host CHAP usernames, lock is released before onRotate
+//is called so callback can use client
+func (c *Client) newChapCredentials(host *infinibox.Host) (*infinibox.ChapCredentials, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("RotateHostChap"); err != nil {
+		return nil, err
+	}
+
+	stored, ok := c.hosts[host.ID]
+	if !ok {
+		return nil, fmt.Errorf("failed to rotate CHAP for host %s, host ID %d not found", host.Name, host.ID)
+	}
+	method := strings.ToUpper(stored.SecurityMethod)
+	if method != infinibox.SecurityMethodChap && method != infinibox.SecurityMethodMutualChap {
+		return nil, fmt.Errorf("failed to rotate CHAP for host %s, security method is %s", host.Name, stored.SecurityMethod)
+	}
+
+	credentials := &infinibox.ChapCredentials{InboundUsername: stored.SecurityChapInboundUsername}
+	var err error
+	credentials.InboundSecret, err = infinibox.GenerateChapSecret(infinibox.ChapSecretMaxLength)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rotate CHAP for host %s, %s", host.Name, err.Error())
+	}
+	if method == infinibox.SecurityMethodMutualChap {
+		credentials.OutboundUsername = stored.SecurityChapOutboundUsername
+		credentials.OutboundSecret, err = infinibox.GenerateChapSecret(infinibox.ChapSecretMaxLength)
+		if err != nil {
+			return nil, fmt.Errorf("failed to rotate CHAP for host %s, %s", host.Name, err.Error())
+		}
+	}
+
+	return credentials, nil
+}
+
+//GetHostClusterByName returns host cluster by name
+func (c *Client) GetHostClusterByName(clustername string) (*infinibox.HostCluster, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("GetHostClusterByName"); err != nil {
+		return nil, err
+	}
+
+	hc, ok := findByName(c.clusters, clustername, clusterName)
+	if !ok {
+		return nil, fmt.Errorf("host cluster %s not found", clustername)
+	}
+	return c.cluster(hc.ID), nil
+}
+
+//GetAllHostClusters returns all host clusters
+func (c *Client) GetAllHostClusters() (*[]infinibox.HostCluster, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("GetAllHostClusters"); err != nil {
+		return nil, err
+	}
+
+	clusters := []infinibox.HostCluster{}
+	for _, hc := range *sorted(c.clusters) {
+		clusters = append(clusters, *c.cluster(hc.ID))
+	}
+	return &clusters, nil
+}
+
+//CreateHostCluster stores host cluster and sets its id
+func (c *Client) CreateHostCluster(cluster *infinibox.HostCluster) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("CreateHostCluster"); err != nil {
+		return err
+	}
+
+	if _, exists := findByName(c.clusters, cluster.Name, clusterName); exists {
+		return fmt.Errorf("error creating host cluster: %s, host cluster already exists", cluster.Name)
+	}
+
+	cluster.ID = c.newID()
+	c.clusters[cluster.ID] = &infinibox.HostCluster{
+		Name:          cluster.Name,
+		CreatedAt:     cluster.CreatedAt,
+		HostType:      cluster.HostType,
+		UpdatedAt:     cluster.UpdatedAt,
+		SanClientType: cluster.SanClientType,
+		ID:            cluster.ID,
+		TenantID:      cluster.TenantID,
+	}
+
+	return nil
+}
+
+//DeleteHostCluster deletes host cluster without mapped LUNs, hosts are removed from cluster
+func (c *Client) DeleteHostCluster(cluster *infinibox.HostCluster) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("DeleteHostCluster"); err != nil {
+		return err
+	}
+
+	stored, ok := c.clusters[cluster.ID]
+	if !ok {
+		return fmt.Errorf("error deleting host cluster: %s, host cluster ID %d not found", cluster.Name, cluster.ID)
+	}
+	if err := c.approve(infinibox.OperationDelete, "cluster", stored.Name); err != nil {
+		return fmt.Errorf("error deleting host cluster: %s, %w", cluster.Name, err)
+	}
+	if luns := c.clusterLuns(cluster.ID); len(luns) > 0 {
+		return fmt.Errorf("error deleting host cluster: %s, volume ID %d is mapped to host cluster", cluster.Name, luns[0].VolumeID)
+	}
+
+	for id, h := range c.hosts {
+		if int64(h.HostClusterID) == cluster.ID {
+			h.HostClusterID = 0
+			c.hosts[id] = h
+		}
+	}
+	delete(c.clusters, cluster.ID)
+	delete(c.metadata, cluster.ID)
+
+	return nil
+}
+
+//RenameHostCluster renames host cluster
+func (c *Client) RenameHostCluster(cluster *infinibox.HostCluster, name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("RenameHostCluster"); err != nil {
+		return err
+	}
+
+	stored, ok := c.clusters[cluster.ID]
+	if !ok {
+		return fmt.Errorf("failed to rename host cluster %s, host cluster ID %d not found", cluster.Name, cluster.ID)
+	}
+	if other, exists := findByName(c.clusters, name, clusterName); exists && other.ID != cluster.ID {
+		return fmt.Errorf("failed to rename host cluster %s, host cluster %s already exists", cluster.Name, name)
+	}
+	if err := c.approve(infinibox.OperationUpdate, "cluster", stored.Name); err != nil {
+		return fmt.Errorf("failed to rename host cluster %s, %w", cluster.Name, err)
+	}
+
+	stored.Name = name
+	cluster.Name = name
+
+	return nil
+}
+
+//AddHostToCluster adds host which is not member of other host cluster to host cluster
+func (c *Client) AddHostToCluster(cluster *infinibox.HostCluster, hostID uint64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("AddHostToCluster"); err != nil {
+		return err
+	}
+
+	if _, ok := c.clusters[cluster.ID]; !ok {
+		return fmt.Errorf("error adding host ID %d to host cluster: %s, host cluster ID %d not found", hostID, cluster.Name, cluster.ID)
+	}
+	h, ok := c.hosts[int64(hostID)]
+	if !ok {
+		return fmt.Errorf("error adding host ID %d to host cluster: %s, host not found", hostID, cluster.Name)
+	}
+	if h.HostClusterID != 0 && int64(h.HostClusterID) != cluster.ID {
+		return fmt.Errorf("error adding host ID %d to host cluster: %s, host belongs to host cluster ID %d", hostID, cluster.Name, h.HostClusterID)
+	}
+
+	h.HostClusterID = int(cluster.ID)
+	c.hosts[h.ID] = h
+
+	return nil
+}
+
+//DeleteHostFromCluster removes host from host cluster
+func (c *Client) DeleteHostFromCluster(cluster *infinibox.HostCluster, hostID uint64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("DeleteHostFromCluster"); err != nil {
+		return err
+	}
+
+	h, ok := c.hosts[int64(hostID)]
+	if !ok || int64(h.HostClusterID) != cluster.ID {
+		return fmt.Errorf("error deleting host ID %d from host cluster: %s, host is not member of cluster", hostID, cluster.Name)
+	}
+
+	h.HostClusterID = 0
+	c.hosts[h.ID] = h
+
+	return nil
+}
+
+//GetHostClusterHosts returns hosts of host cluster
+func (c *Client) GetHostClusterHosts(cluster *infinibox.HostCluster) (*[]infinibox.Host, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("GetHostClusterHosts"); err != nil {
+		return nil, err
+	}
+
+	if _, ok := c.clusters[cluster.ID]; !ok {
+		return nil, fmt.Errorf("error getting host cluster %s hosts, host cluster ID %d not found", cluster.Name, cluster.ID)
+	}
+
+	hosts := c.clusterHosts(cluster.ID)
+	for i := range hosts {
+		hosts[i], _ = c.host(hosts[i].ID)
+	}
+	return &hosts, nil
+}
+
+//GetHostClusterLUNs returns host cluster LUNs
+func (c *Client) GetHostClusterLUNs(cluster *infinibox.HostCluster) (*[]infinibox.Lun, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("GetHostClusterLUNs"); err != nil {
+		return nil, err
+	}
+
+	if _, ok := c.clusters[cluster.ID]; !ok {
+		return nil, fmt.Errorf("error getting host cluster %s luns, host cluster ID %d not found", cluster.Name, cluster.ID)
+	}
+
+	luns := c.clusterLuns(cluster.ID)
+	return &luns, nil
+}
+
+//AddHostClusterLUN maps lun to host cluster, lun number must be free on the cluster and all of its
+//hosts, zero lun number maps volume to first free LUN
+func (c *Client) AddHostClusterLUN(cluster *infinibox.HostCluster, lun *infinibox.Lun) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("AddHostClusterLUN"); err != nil {
+		return err
+	}
+
+	stored, ok := c.clusters[cluster.ID]
+	if !ok {
+		return fmt.Errorf("error adding lun to host cluster: %s, host cluster ID %d not found", cluster.Name, cluster.ID)
+	}
+	if _, ok := c.volumes[lun.VolumeID]; !ok {
+		return fmt.Errorf("error adding lun to host cluster: %s, volume ID %d not found", cluster.Name, lun.VolumeID)
+	}
+
+	luns := c.clusterLuns(cluster.ID)
+	for _, h := range c.clusterHosts(cluster.ID) {
+		luns = append(luns, c.hostLuns(h)...)
+	}
+	for _, existing := range luns {
+		if existing.VolumeID == lun.VolumeID && existing.Clustered && existing.HostClusterID == cluster.ID {
+			return fmt.Errorf("error adding lun to host cluster: %s, volume ID %d is mapped to host cluster", cluster.Name, lun.VolumeID)
+		}
+		if lun.Lun > 0 && existing.Lun == lun.Lun {
+			return fmt.Errorf("error adding lun to host cluster: %s, LUN %d is used by volume ID %d", cluster.Name, lun.Lun, existing.VolumeID)
+		}
+	}
+	if err := c.approve(infinibox.OperationMap, "cluster", stored.Name); err != nil {
+		return fmt.Errorf("error adding lun to host cluster: %s, %w", cluster.Name, err)
+	}
+
+	if lun.Lun == 0 {
+		lun.Lun = freeLun(luns, firstAssignableLun)
+	}
+	lun.ID = c.newID()
+	lun.HostID = 0
+	lun.Clustered = true
+	lun.HostClusterID = cluster.ID
+	c.luns = append(c.luns, *lun)
+
+	return nil
+}
+
+//DeleteHostClusterLUN unmaps host cluster lun number
+func (c *Client) DeleteHostClusterLUN(cluster *infinibox.HostCluster, lunID int) (*infinibox.Lun, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("DeleteHostClusterLUN"); err != nil {
+		return nil, err
+	}
+
+	stored, ok := c.clusters[cluster.ID]
+	if !ok {
+		return nil, fmt.Errorf("error deleting host cluster: %s lun ID %d, host cluster ID %d not found", cluster.Name, lunID, cluster.ID)
+	}
+	for _, lun := range c.clusterLuns(cluster.ID) {
+		if lun.Lun == lunID {
+			if err := c.approve(infinibox.OperationUnmap, "cluster", stored.Name); err != nil {
+				return nil, fmt.Errorf("error deleting host cluster: %s lun ID %d, %w", cluster.Name, lunID, err)
+			}
+			c.deleteLuns(func(l infinibox.Lun) bool { return l.ID != lun.ID })
+			return &lun, nil
+		}
+	}
+
+	return nil, fmt.Errorf("error deleting host cluster: %s lun ID %d, lun not found", cluster.Name, lunID)
+}
+
+//GetTenant returns tenant by id
+func (c *Client) GetTenant(tenantID int64) (*infinibox.Tenant, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("GetTenant"); err != nil {
+		return nil, err
+	}
+
+	t, ok := c.tenants[tenantID]
+	if !ok {
+		return nil, fmt.Errorf("tenant ID %d not found", tenantID)
+	}
+	return &t, nil
+}
+
+//GetTenantByName returns tenant by name
+func (c *Client) GetTenantByName(tenantname string) (*infinibox.Tenant, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("GetTenantByName"); err != nil {
+		return nil, err
+	}
+
+	t, ok := findByName(c.tenants, tenantname, tenantName)
+	if !ok {
+		return nil, fmt.Errorf("tenant %s not found", tenantname)
+	}
+	return &t, nil
+}
+
+//GetAllTenants returns all tenants
+func (c *Client) GetAllTenants() (*[]infinibox.Tenant, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("GetAllTenants"); err != nil {
+		return nil, err
+	}
+	return sorted(c.tenants), nil
+}
+
+//CreateTenant stores tenant and sets its id
+func (c *Client) CreateTenant(tenant *infinibox.Tenant) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("CreateTenant"); err != nil {
+		return err
+	}
+
+	if _, exists := findByName(c.tenants, tenant.Name, tenantName); exists {
+		return fmt.Errorf("error creating tenant: %s, tenant already exists", tenant.Name)
+	}
+
+	tenant.ID = c.newID()
+	c.tenants[tenant.ID] = *tenant
+
+	return nil
+}
+
+//DeleteTenant deletes tenant
+func (c *Client) DeleteTenant(tenant *infinibox.Tenant) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("DeleteTenant"); err != nil {
+		return err
+	}
+
+	stored, ok := c.tenants[tenant.ID]
+	if !ok {
+		return fmt.Errorf("error deleting tenant: %s, tenant ID %d not found", tenant.Name, tenant.ID)
+	}
+	if err := c.approve(infinibox.OperationDelete, "tenant", stored.Name); err != nil {
+		return fmt.Errorf("error deleting tenant: %s, %w", tenant.Name, err)
+	}
+
+	delete(c.tenants, tenant.ID)
+
+	return nil
+}
+
+//RenameTenant renames tenant
+func (c *Client) RenameTenant(tenant *infinibox.Tenant, name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("RenameTenant"); err != nil {
+		return err
+	}
+
+	stored, ok := c.tenants[tenant.ID]
+	if !ok {
+		return fmt.Errorf("failed to rename tenant %s, tenant ID %d not found", tenant.Name, tenant.ID)
+	}
+	if other, exists := findByName(c.tenants, name, tenantName); exists && other.ID != tenant.ID {
+		return fmt.Errorf("failed to rename tenant %s, tenant %s already exists", tenant.Name, name)
+	}
+	if err := c.approve(infinibox.OperationUpdate, "tenant", stored.Name); err != nil {
+		return fmt.Errorf("failed to rename tenant %s, %w", tenant.Name, err)
+	}
+
+	stored.Name = name
+	c.tenants[tenant.ID] = stored
+	tenant.Name = name
+
+	return nil
+}
+
+//UpdateTenantVisibleToSysadmin sets whether tenant is visible to sysadmin
+func (c *Client) UpdateTenantVisibleToSysadmin(tenant *infinibox.Tenant, visible bool) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("UpdateTenantVisibleToSysadmin"); err != nil {
+		return err
+	}
+
+	stored, ok := c.tenants[tenant.ID]
+	if !ok {
+		return fmt.Errorf("failed to update tenant %s VisibleToSysadmin, tenant ID %d not found", tenant.Name, tenant.ID)
+	}
+	if err := c.approve(infinibox.OperationUpdate, "tenant", stored.Name); err != nil {
+		return fmt.Errorf("failed to update tenant %s VisibleToSysadmin, %w", tenant.Name, err)
+	}
+
+	stored.VisibleToSysadmin = visible
+	c.tenants[tenant.ID] = stored
+	tenant.VisibleToSysadmin = visible
+
+	return nil
+}
+
+//GetAllMetadata returns metadata of all objects ordered by object id and key
+func (c *Client) GetAllMetadata() (*[]infinibox.Metadata, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("GetAllMetadata"); err != nil {
+		return nil, err
+	}
+
+	all := []infinibox.Metadata{}
+	for objectID := range c.metadata {
+		all = append(all, c.objectMetadata(objectID)...)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].ObjectID != all[j].ObjectID {
+			return all[i].ObjectID < all[j].ObjectID
+		}
+		return all[i].Key < all[j].Key
+	})
+	return &all, nil
+}
+
+//objectMetadata returns object metadata ordered by key
+func (c *Client) objectMetadata(objectID int64) []infinibox.Metadata {
+	entries := []infinibox.Metadata{}
+	for _, entry := range c.metadata[objectID] {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+	return entries
+}
+
+//GetMetadataByObject returns object metadata
+func (c *Client) GetMetadataByObject(objectID int64) (*[]infinibox.Metadata, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("GetMetadataByObject"); err != nil {
+		return nil, err
+	}
+
+	entries := c.objectMetadata(objectID)
+	return &entries, nil
+}
+
+//GetMetadataByObjectAndKey returns object metadata key
+func (c *Client) GetMetadataByObjectAndKey(objectID int64, key string) (*infinibox.Metadata, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("GetMetadataByObjectAndKey"); err != nil {
+		return nil, err
+	}
+
+	entry, ok := c.metadata[objectID][key]
+	if !ok {
+		return nil, fmt.Errorf("metadata key %s of objectID %d not found", key, objectID)
+	}
+	return &entry, nil
+}
+
+//setMetadata stores metadata value of existing object
+func (c *Client) setMetadata(objectID int64, key string, value interface{}) error {
+
+	objectType := c.objectType(objectID)
+	if objectType == "" {
+		return fmt.Errorf("Adding metadata for objectID %d failed, object not found", objectID)
+	}
+
+	encoded, err := encodeValue(value)
+	if err != nil {
+		return fmt.Errorf("Adding metadata key %s for objectID %d failed, %s", key, objectID, err.Error())
+	}
+
+	if c.metadata[objectID] == nil {
+		c.metadata[objectID] = map[string]infinibox.Metadata{}
+	}
+	entry, ok := c.metadata[objectID][key]
+	if !ok {
+		entry = infinibox.Metadata{ID: c.newID(), Key: key, ObjectID: objectID, ObjectType: objectType}
+	}
+	entry.Value = encoded
+	c.metadata[objectID][key] = entry
+
+	return nil
+}
+
+//AddMetadata sets object metadata key
+func (c *Client) AddMetadata(metadata *infinibox.Metadata) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("AddMetadata"); err != nil {
+		return err
+	}
+	return c.setMetadata(metadata.ObjectID, metadata.Key, metadata.Value)
+}
+
+//AddMetadataMap sets all provided keys for objectID
+func (c *Client) AddMetadataMap(objectID int64, values map[string]interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("AddMetadataMap"); err != nil {
+		return err
+	}
+
+	for key, value := range values {
+		if _, err := encodeValue(value); err != nil {
+			return fmt.Errorf("Adding metadata key %s for objectID %d failed, %s", key, objectID, err.Error())
+		}
+	}
+	for key, value := range values {
+		if err := c.setMetadata(objectID, key, value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+//DeleteMetadata deletes all object metadata
+func (c *Client) DeleteMetadata(objectID int64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("DeleteMetadata"); err != nil {
+		return err
+	}
+
+	delete(c.metadata, objectID)
+	return nil
+}
+
+//DeleteMetadataByKey deletes object metadata key
+func (c *Client) DeleteMetadataByKey(objectID int64, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("DeleteMetadataByKey"); err != nil {
+		return err
+	}
+
+	if _, ok := c.metadata[objectID][key]; !ok {
+		return fmt.Errorf("Deleting metadata for objectID %d and key %s failed, key not found", objectID, key)
+	}
+
+	delete(c.metadata[objectID], key)
+	return nil
+}
+
+//FindObjectsByMetadata returns metadata entries with key set to value, objectType limits
+//results to provided object type when not empty
+func (c *Client) FindObjectsByMetadata(objectType string, key string, value string) (*[]infinibox.Metadata, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("FindObjectsByMetadata"); err != nil {
+		return nil, err
+	}
+
+	matches := []infinibox.Metadata{}
+	for _, entries := range c.metadata {
+		entry, ok := entries[key]
+		if !ok || (objectType != "" && entry.ObjectType != objectType) {
+			continue
+		}
+		if fmt.Sprint(entry.Value) == value {
+			matches = append(matches, entry)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].ObjectID < matches[j].ObjectID })
+
+	return &matches, nil
+}
+
+//metadataValue returns stored value of object metadata key
+func (c *Client) metadataValue(objectID int64, key string) (string, error) {
+	entry, ok := c.metadata[objectID][key]
+	if !ok {
+		return "", fmt.Errorf("Getting metadata by objectID %d and key %s, key not found", objectID, key)
+	}
+	return fmt.Sprint(entry.Value), nil
+}
+
+//GetMetadataString returns objectID metadata key value as string
+func (c *Client) GetMetadataString(objectID int64, key string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("GetMetadataString"); err != nil {
+		return "", err
+	}
+	return c.metadataValue(objectID, key)
+}
+
+//GetMetadataInt returns objectID metadata key value as int64
+func (c *Client) GetMetadataInt(objectID int64, key string) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("GetMetadataInt"); err != nil {
+		return 0, err
+	}
+
+	raw, err := c.metadataValue(objectID, key)
+	if err != nil {
+		return 0, err
+	}
+	value, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("Decoding metadata by objectID %d and key %s, %s", objectID, key, err.Error())
+	}
+	return value, nil
+}
+
+//GetMetadataBool returns objectID metadata key value as bool
+func (c *Client) GetMetadataBool(objectID int64, key string) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("GetMetadataBool"); err != nil {
+		return false, err
+	}
+
+	raw, err := c.metadataValue(objectID, key)
+	if err != nil {
+		return false, err
+	}
+	value, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, fmt.Errorf("Decoding metadata by objectID %d and key %s, %s", objectID, key, err.Error())
+	}
+	return value, nil
+}
+
+//GetMetadataJSON decodes JSON encoded objectID metadata key value into target
+func (c *Client) GetMetadataJSON(objectID int64, key string, target interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("GetMetadataJSON"); err != nil {
+		return err
+	}
+
+	raw, err := c.metadataValue(objectID, key)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal([]byte(raw), target); err != nil {
+		return fmt.Errorf("Decoding metadata by objectID %d and key %s, %s", objectID, key, err.Error())
+	}
+	return nil
+}
+
+//SetMetadataString stores string objectID metadata key value
+func (c *Client) SetMetadataString(objectID int64, key string, value string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("SetMetadataString"); err != nil {
+		return err
+	}
+	return c.setMetadata(objectID, key, value)
+}
+
+//SetMetadataInt stores int64 objectID metadata key value
+func (c *Client) SetMetadataInt(objectID int64, key string, value int64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("SetMetadataInt"); err != nil {
+		return err
+	}
+	return c.setMetadata(objectID, key, value)
+}
+
+//SetMetadataBool stores bool objectID metadata key value
+func (c *Client) SetMetadataBool(objectID int64, key string, value bool) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("SetMetadataBool"); err != nil {
+		return err
+	}
+	return c.setMetadata(objectID, key, value)
+}
+
+//SetMetadataJSON stores JSON encoded value as objectID metadata key
+func (c *Client) SetMetadataJSON(objectID int64, key string, value interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("SetMetadataJSON"); err != nil {
+		return err
+	}
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("Encoding metadata for objectID %d and key %s, %s", objectID, key, err.Error())
+	}
+	return c.setMetadata(objectID, key, string(encoded))
+}
+
+//StampOwnership sets ownership metadata for objectID
+func (c *Client) StampOwnership(objectID int64, ownership infinibox.Ownership) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("StampOwnership"); err != nil {
+		return err
+	}
+
+	if ownership.Owner == "" {
+		return fmt.Errorf("unable to stamp objectID %d, owner is required", objectID)
+	}
+
+	values := map[string]interface{}{
+		infinibox.OwnerMetadataKey:          ownership.Owner,
+		infinibox.OwnerContextMetadataKey:   ownership.Context,
+		infinibox.OwnerCreatedAtMetadataKey: time.Now().Unix(),
+	}
+	for key, value := range values {
+		if err := c.setMetadata(objectID, key, value); err != nil {
+			return fmt.Errorf("unable to stamp objectID %d with owner %s, %s", objectID, ownership.Owner, err.Error())
+		}
+	}
+
+	return nil
+}
+
+//ownership returns owned object built from object metadata, nil when object carries no owner marker
+func (c *Client) ownership(objectID int64) *infinibox.OwnedObject {
+
+	entries := c.metadata[objectID]
+	marker, ok := entries[infinibox.OwnerMetadataKey]
+	if !ok {
+		return nil
+	}
+
+	owned := &infinibox.OwnedObject{ObjectID: objectID, ObjectType: marker.ObjectType, Owner: fmt.Sprint(marker.Value)}
+	if entry, ok := entries[infinibox.OwnerContextMetadataKey]; ok {
+		owned.Context = fmt.Sprint(entry.Value)
+	}
+	if entry, ok := entries[infinibox.OwnerCreatedAtMetadataKey]; ok {
+		owned.CreatedAt, _ = strconv.ParseInt(fmt.Sprint(entry.Value), 10, 64)
+	}
+
+	return owned
+}
+
+//owned returns objects stamped with owner ordered by object id
+func (c *Client) owned(owner string) []infinibox.OwnedObject {
+	ids := make([]int64, 0, len(c.metadata))
+	for objectID := range c.metadata {
+		ids = append(ids, objectID)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	owned := []infinibox.OwnedObject{}
+	for _, objectID := range ids {
+		if object := c.ownership(objectID); object != nil && object.Owner == owner {
+			owned = append(owned, *object)
+		}
+	}
+	return owned
+}
+
+//GetOwnership returns ownership of objectID, nil when object carries no owner marker
+func (c *Client) GetOwnership(objectID int64) (*infinibox.OwnedObject, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("GetOwnership"); err != nil {
+		return nil, err
+	}
+	return c.ownership(objectID), nil
+}
+
+//ListOwned returns all objects stamped with owner
+func (c *Client) ListOwned(owner string) (*[]infinibox.OwnedObject, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("ListOwned"); err != nil {
+		return nil, err
+	}
+
+	owned := c.owned(owner)
+	return &owned, nil
+}
+
+//FindOrphans returns objects owned by owner whose referenced resource no longer exists,
+//exists is called without client lock held so it can use client
+func (c *Client) FindOrphans(owner string, exists infinibox.OwnerExistsFunc) (*[]infinibox.OwnedObject, error) {
+
+	c.mu.Lock()
+	err := c.call("FindOrphans")
+	owned := c.owned(owner)
+	c.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	orphans := []infinibox.OwnedObject{}
+	for _, object := range owned {
+		found, err := exists(object)
+		if err != nil {
+			return nil, fmt.Errorf("unable to check owner of objectID %d, %s", object.ObjectID, err.Error())
+		}
+		if !found {
+			orphans = append(orphans, object)
+		}
+	}
+
+	return &orphans, nil
+}
+
+//CheckOwnership returns error wrapping ErrNotOwner when objectID is stamped with different owner
+func (c *Client) CheckOwnership(objectID int64, owner string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("CheckOwnership"); err != nil {
+		return err
+	}
+
+	if object := c.ownership(objectID); object != nil && object.Owner != owner {
+		return fmt.Errorf("objectID %d owned by %s, not %s: %w", objectID, object.Owner, owner, infinibox.ErrNotOwner)
+	}
+	return nil
+}
+
+//GetPlugin returns plugin by id
+func (c *Client) GetPlugin(pluginID int64) (*infinibox.Plugin, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("GetPlugin"); err != nil {
+		return nil, err
+	}
+
+	p, ok := c.plugins[pluginID]
+	if !ok {
+		return nil, fmt.Errorf("plugin ID %d not found", pluginID)
+	}
+	return &p, nil
+}
+
+//GetPluginByName returns plugin by name
+func (c *Client) GetPluginByName(pluginname string) (*infinibox.Plugin, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("GetPluginByName"); err != nil {
+		return nil, err
+	}
+
+	p, ok := findByName(c.plugins, pluginname, pluginName)
+	if !ok {
+		return nil, fmt.Errorf("plugin %s not found", pluginname)
+	}
+	return &p, nil
+}
+
+//GetAllPlugins returns all plugins
+func (c *Client) GetAllPlugins() (*[]infinibox.Plugin, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("GetAllPlugins"); err != nil {
+		return nil, err
+	}
+	return sorted(c.plugins), nil
+}
+
+//CreatePlugin stores plugin and sets its id
+func (c *Client) CreatePlugin(plugin *infinibox.Plugin) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("CreatePlugin"); err != nil {
+		return err
+	}
+
+	if _, exists := findByName(c.plugins, plugin.Name, pluginName); exists {
+		return fmt.Errorf("error creating plugin: %s, plugin already exists", plugin.Name)
+	}
+
+	plugin.ID = c.newID()
+	c.plugins[plugin.ID] = *plugin
+
+	return nil
+}
+
+//DeletePlugin deletes plugin and its heartbeats
+func (c *Client) DeletePlugin(plugin *infinibox.Plugin) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("DeletePlugin"); err != nil {
+		return err
+	}
+
+	stored, ok := c.plugins[plugin.ID]
+	if !ok {
+		return fmt.Errorf("error deleting plugin: %s, plugin ID %d not found", plugin.Name, plugin.ID)
+	}
+	if err := c.approve(infinibox.OperationDelete, "plugin", stored.Name); err != nil {
+		return fmt.Errorf("error deleting plugin: %s, %w", plugin.Name, err)
+	}
+
+	delete(c.plugins, plugin.ID)
+	delete(c.heartbeats, plugin.ID)
+	delete(c.metadata, plugin.ID)
+
+	return nil
+}
+
+//UpdatePlugin replaces stored plugin attributes
+func (c *Client) UpdatePlugin(plugin *infinibox.Plugin) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("UpdatePlugin"); err != nil {
+		return err
+	}
+
+	if _, ok := c.plugins[plugin.ID]; !ok {
+		return fmt.Errorf("error updating plugin: %s, plugin ID %d not found", plugin.Name, plugin.ID)
+	}
+
+	c.plugins[plugin.ID] = *plugin
+
+	return nil
+}
+
+//SendPluginHeartbeat records plugin heartbeat
+func (c *Client) SendPluginHeartbeat(plugin *infinibox.Plugin, heartbeat infinibox.Heartbeat) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("SendPluginHeartbeat"); err != nil {
+		return err
+	}
+
+	if _, ok := c.plugins[plugin.ID]; !ok {
+		return fmt.Errorf("error sending heartbeat of plugin: %s, plugin ID %d not found", plugin.Name, plugin.ID)
+	}
+
+	c.heartbeats[plugin.ID] = append(c.heartbeats[plugin.ID], heartbeat)
+
+	return nil
+}
+
+//GetAllInitiators returns initiators added by AddInitiator ordered by address
+func (c *Client) GetAllInitiators() (*[]infinibox.Initiator, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("GetAllInitiators"); err != nil {
+		return nil, err
+	}
+
+	initiators := []infinibox.Initiator{}
+	for _, initiator := range c.initiators {
+		initiators = append(initiators, initiator)
+	}
+	sort.Slice(initiators, func(i, j int) bool { return initiators[i].Address < initiators[j].Address })
+
+	return &initiators, nil
+}
+
+//GetInitiatorByAddress returns initiator by address
+func (c *Client) GetInitiatorByAddress(address string) (*infinibox.Initiator, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("GetInitiatorByAddress"); err != nil {
+		return nil, err
+	}
+
+	initiator, ok := c.initiators[address]
+	if !ok {
+		return nil, fmt.Errorf("error getting initiator by address %s, initiator not found", address)
+	}
+	return &initiator, nil
+}
+
+//GetHostIDbyInitiatorAddress returns id of host owning port with address
+func (c *Client) GetHostIDbyInitiatorAddress(address string) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("GetHostIDbyInitiatorAddress"); err != nil {
+		return -1, err
+	}
+
+	for _, h := range *sorted(c.hosts) {
+		for _, port := range h.Ports {
+			if port.Address == address {
+				return h.ID, nil
+			}
+		}
+	}
+
+	return -1, fmt.Errorf("host with initiator address %s not found", address)
+}
+
+//GetSystem returns system set by SetSystem
+func (c *Client) GetSystem() (*infinibox.System, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("GetSystem"); err != nil {
+		return nil, err
+	}
+
+	system := c.system
+	return &system, nil
+}
+
+//Supports reports feature set by SetFeature, other features are decided by system version
+func (c *Client) Supports(feature string) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("Supports"); err != nil {
+		return false, err
+	}
+
+	if supported, ok := c.features[feature]; ok {
+		return supported, nil
+	}
+	return c.system.Supports(feature)
+}
+
+//customEventLevels maps custom event codes to event levels
+var customEventLevels = map[string]string{
+	infinibox.CustomEventInfo:     infinibox.EventLevelInfo,
+	infinibox.CustomEventWarning:  infinibox.EventLevelWarning,
+	infinibox.CustomEventError:    infinibox.EventLevelError,
+	infinibox.CustomEventCritical: infinibox.EventLevelCritical,
+}
+
+//eventMatches reports whether event matches filter
+func eventMatches(event infinibox.Event, filter infinibox.EventFilter) bool {
+	switch {
+	case filter.Level != "" && event.Level != filter.Level:
+		return false
+	case filter.Code != "" && event.Code != filter.Code:
+		return false
+	case !filter.Since.IsZero() && event.Timestamp < filter.Since.UnixNano()/int64(time.Millisecond):
+		return false
+	case !filter.Until.IsZero() && event.Timestamp > filter.Until.UnixNano()/int64(time.Millisecond):
+		return false
+	case filter.AffectedEntityID != 0 && event.AffectedEntityID != filter.AffectedEntityID:
+		return false
+	case filter.AfterID != 0 && event.ID <= filter.AfterID:
+		return false
+	}
+	return true
+}
+
+//eventsPageSize returns number of events read by single SubscribeEvents poll as by infinibox client
+func eventsPageSize(filter infinibox.EventFilter) int {
+	if filter.Limit <= 0 || filter.Limit > 1000 {
+		return 1000
+	}
+	return filter.Limit
+}
+
+//GetEvents returns events matching filter ordered by id, limited to filter.Limit when set
+func (c *Client) GetEvents(filter infinibox.EventFilter) (*[]infinibox.Event, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("GetEvents"); err != nil {
+		return nil, err
+	}
+
+	events := []infinibox.Event{}
+	for _, event := range c.events {
+		if filter.Limit > 0 && len(events) == filter.Limit {
+			break
+		}
+		if eventMatches(event, filter) {
+			events = append(events, event)
+		}
+	}
+	return &events, nil
+}
+
+//GetEvent returns event by id
+func (c *Client) GetEvent(eventID int64) (*infinibox.Event, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("GetEvent"); err != nil {
+		return nil, err
+	}
+
+	for _, event := range c.events {
+		if event.ID == eventID {
+			return &event, nil
+		}
+	}
+	return nil, fmt.Errorf("error getting event %d, event not found", eventID)
+}
+
+//CreateCustomEvent stores custom event, code is one of infinibox CustomEvent codes
+func (c *Client) CreateCustomEvent(code string, description string, data map[string]string) (*infinibox.Event, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("CreateCustomEvent"); err != nil {
+		return nil, err
+	}
+
+	level, ok := customEventLevels[code]
+	if !ok {
+		return nil, fmt.Errorf("error creating custom event %s, unknown custom event code", code)
+	}
+
+	event := infinibox.Event{
+		ID:          c.newID(),
+		Code:        code,
+		Level:       level,
+		Description: description,
+		Timestamp:   time.Now().UnixNano() / int64(time.Millisecond),
+		Reporter:    "CUSTOM",
+		Visibility:  "CUSTOMER",
+		Data:        []infinibox.EventData{},
+	}
+	for name, value := range data {
+		event.Data = append(event.Data, infinibox.EventData{Name: name, Type: "string", Value: value})
+	}
+	sort.Slice(event.Data, func(i, j int) bool { return event.Data[i].Name < event.Data[j].Name })
+	c.events = append(c.events, event)
+
+	return &event, nil
+}
+
+//SubscribeEvents polls events matching filter newer than filter.AfterID and sends them to
+//returned channel in id order, zero filter.AfterID subscribes to events newer than the latest
+//event when subscribing, channel is closed when ctx is cancelled
+func (c *Client) SubscribeEvents(ctx context.Context, filter infinibox.EventFilter) <-chan infinibox.Event {
+
+	events := make(chan infinibox.Event)
+
+	interval := filter.PollInterval
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+
+	poll := filter
+	poll.Limit = eventsPageSize(filter)
+	if poll.AfterID == 0 {
+		c.mu.Lock()
+		if len(c.events) > 0 {
+			poll.AfterID = c.events[len(c.events)-1].ID
+		}
+		c.mu.Unlock()
+	}
+
+	go func() {
+		defer close(events)
+
+		wait := time.Duration(0)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(wait):
+			}
+
+			wait = interval
+			page, err := c.GetEvents(poll)
+			if err != nil {
+				continue
+			}
+
+			for _, event := range *page {
+				select {
+				case events <- event:
+					poll.AfterID = event.ID
+				case <-ctx.Done():
+					return
+				}
+			}
+			if len(*page) >= poll.Limit {
+				wait = 0
+			}
+		}
+	}()
+
+	return events
+}
@@ -0,0 +1,432 @@
+package fake_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/devnal/infinibox-go-client"
+	"github.com/devnal/infinibox-go-client/fake"
+	"regexp"
+	"testing"
+	"time"
+)
+
+//createVolume creates volume in pool created by first call
+func createVolume(t *testing.T, api infinibox.API, name string) *infinibox.Volume {
+
+	pool, err := api.GetPoolByName("pool-1")
+	if err != nil {
+		pool = &infinibox.Pool{Name: "pool-1", PhysicalCapacity: 1 << 40, VirtualCapacity: 1 << 40}
+		if err := api.CreatePool(pool); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	volume := &infinibox.Volume{Name: name, PoolID: pool.ID, Size: 1 << 30}
+	if err := api.CreateVolume(volume); err != nil {
+		t.Fatal(err)
+	}
+	return volume
+}
+
+func TestMapVolumeLunNumbers(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		startLun int
+		used     []int
+		want     int
+	}{
+		{name: "zero start is clamped to first assignable LUN", startLun: 0, want: 1},
+		{name: "negative start is clamped to first assignable LUN", startLun: -3, want: 1},
+		{name: "free start is used", startLun: 5, want: 5},
+		{name: "used LUNs are skipped", startLun: 0, used: []int{1, 2}, want: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			client := fake.NewClient()
+			var api infinibox.API = client
+
+			host := &infinibox.Host{Name: "node-1"}
+			if err := api.CreateHost(host); err != nil {
+				t.Fatal(err)
+			}
+			for _, lun := range tt.used {
+				volume := createVolume(t, api, fmt.Sprintf("used-%d", lun))
+				if err := api.AddHostLUN(host, &infinibox.Lun{Lun: lun, VolumeID: volume.ID}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			volume := createVolume(t, api, "data")
+
+			lun, err := api.MapVolumeToHost(volume, host, tt.startLun)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if lun.Lun != tt.want {
+				t.Fatalf("mapped to LUN %d, want %d", lun.Lun, tt.want)
+			}
+		})
+	}
+}
+
+func TestMappingRules(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		cluster bool
+		delete  func(api infinibox.API, volume *infinibox.Volume, host *infinibox.Host, cluster *infinibox.HostCluster) error
+		refused bool
+	}{
+		{
+			name: "mapped volume is not deleted",
+			delete: func(api infinibox.API, volume *infinibox.Volume, _ *infinibox.Host, _ *infinibox.HostCluster) error {
+				return api.DeleteVolume(volume)
+			},
+			refused: true,
+		},
+		{
+			name: "host with mapped LUNs is not deleted",
+			delete: func(api infinibox.API, _ *infinibox.Volume, host *infinibox.Host, _ *infinibox.HostCluster) error {
+				return api.DeleteHost(host)
+			},
+			refused: true,
+		},
+		{
+			name:    "host cluster with mapped LUNs is not deleted",
+			cluster: true,
+			delete: func(api infinibox.API, _ *infinibox.Volume, _ *infinibox.Host, cluster *infinibox.HostCluster) error {
+				return api.DeleteHostCluster(cluster)
+			},
+			refused: true,
+		},
+		{
+			name:    "host with LUNs of its host cluster only is deleted",
+			cluster: true,
+			delete: func(api infinibox.API, _ *infinibox.Volume, host *infinibox.Host, _ *infinibox.HostCluster) error {
+				return api.DeleteHost(host)
+			},
+			refused: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			var api infinibox.API = fake.NewClient()
+
+			volume := createVolume(t, api, "data")
+			host := &infinibox.Host{Name: "node-1"}
+			cluster := &infinibox.HostCluster{Name: "cluster-1"}
+			if err := api.CreateHost(host); err != nil {
+				t.Fatal(err)
+			}
+			if err := api.CreateHostCluster(cluster); err != nil {
+				t.Fatal(err)
+			}
+			if tt.cluster {
+				if err := api.AddHostToCluster(cluster, uint64(host.ID)); err != nil {
+					t.Fatal(err)
+				}
+				if err := api.AddHostClusterLUN(cluster, &infinibox.Lun{VolumeID: volume.ID}); err != nil {
+					t.Fatal(err)
+				}
+			} else if _, err := api.MapVolumeToHost(volume, host, 0); err != nil {
+				t.Fatal(err)
+			}
+
+			err := tt.delete(api, volume, host, cluster)
+			if !tt.refused {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("mapped object deleted")
+			}
+
+			//nothing is left dangling after refusal, deletion succeeds once volume is unmapped
+			if luns, err := api.GetVolumeLUNs(volume); err != nil || len(*luns) != 1 {
+				t.Fatalf("volume LUNs %v, error %v, want mapping kept", luns, err)
+			}
+			if err := api.UnMapVolume(volume); err != nil {
+				t.Fatal(err)
+			}
+			if err := tt.delete(api, volume, host, cluster); err != nil {
+				t.Fatalf("deleting unmapped object, %s", err.Error())
+			}
+		})
+	}
+}
+
+func TestApprovalAndProtection(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		names    []string
+		patterns []*regexp.Regexp
+		policy   infinibox.ApprovalPolicy
+		confirm  string
+		passed   string
+		want     error
+		approved int
+	}{
+		{name: "unprotected volume is deleted", names: []string{"prod-db"}},
+		{name: "protected name is refused", names: []string{"data"}, want: infinibox.ErrProtectedObject},
+		{name: "protected pattern is refused", patterns: []*regexp.Regexp{regexp.MustCompile(`^da.*$`)}, want: infinibox.ErrProtectedObject},
+		{name: "stored name is checked, not passed one", names: []string{"data"}, passed: "scratch", want: infinibox.ErrProtectedObject},
+		{name: "always policy approves confirmation", confirm: "volume is mapped"},
+		{name: "never policy denies confirmation", policy: infinibox.ApprovalPolicy{Mode: infinibox.ApproveNever}, confirm: "volume is mapped", want: infinibox.ErrApprovalDenied},
+		{name: "accepting callback approves", policy: infinibox.ApprovalWith(func(infinibox.ApprovalRequest) bool { return true }), confirm: "volume is mapped", approved: 1},
+		{name: "rejecting callback denies", policy: infinibox.ApprovalWith(func(infinibox.ApprovalRequest) bool { return false }), confirm: "volume is mapped", want: infinibox.ErrApprovalDenied, approved: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			client := fake.NewClient()
+			var api infinibox.API = client
+
+			volume := createVolume(t, api, "data")
+
+			asked := 0
+			policy := tt.policy
+			if policy.Approve != nil {
+				approve := policy.Approve
+				policy.Approve = func(request infinibox.ApprovalRequest) bool {
+					asked++
+					if request.Operation != infinibox.OperationDelete || request.ObjectName != "data" || request.Message != tt.confirm {
+						t.Errorf("asked to approve %+v", request)
+					}
+					return approve(request)
+				}
+			}
+			client.Protect(tt.names, tt.patterns)
+			client.SetApproval(policy)
+			if tt.confirm != "" {
+				client.RequireApproval(infinibox.OperationDelete, tt.confirm)
+			}
+
+			err := api.DeleteVolume(&infinibox.Volume{ID: volume.ID, Name: tt.passed})
+
+			if tt.want == nil && err != nil {
+				t.Fatal(err)
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Fatalf("error %v, want %v", err, tt.want)
+			}
+			if asked != tt.approved {
+				t.Fatalf("approval asked %d times, want %d", asked, tt.approved)
+			}
+			if _, err := api.GetVolume(volume.ID); (err == nil) != (tt.want != nil) {
+				t.Fatalf("volume exists %v after delete with error %v", err == nil, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetEventsLimit(t *testing.T) {
+
+	tests := []struct {
+		name   string
+		filter infinibox.EventFilter
+		want   []int64
+	}{
+		{name: "all events without limit", filter: infinibox.EventFilter{}, want: []int64{1, 2, 3, 4, 5}},
+		{name: "limit caps events", filter: infinibox.EventFilter{Limit: 2}, want: []int64{1, 2}},
+		{name: "limit counts matching events only", filter: infinibox.EventFilter{Level: infinibox.EventLevelError, Limit: 2}, want: []int64{2, 4}},
+		{name: "events after id", filter: infinibox.EventFilter{AfterID: 3}, want: []int64{4, 5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			client := fake.NewClient()
+			var api infinibox.API = client
+			for i := 0; i < 5; i++ {
+				level := infinibox.EventLevelInfo
+				if i%2 == 1 {
+					level = infinibox.EventLevelError
+				}
+				client.AddEvent(&infinibox.Event{Level: level})
+			}
+
+			events, err := api.GetEvents(tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(*events) != len(tt.want) {
+				t.Fatalf("got %d events, want %v", len(*events), tt.want)
+			}
+			for i, event := range *events {
+				if event.ID != tt.want[i] {
+					t.Fatalf("event %d has id %d, want %v", i, event.ID, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestSubscribeEventsReadsPagesAfterLatestEvent(t *testing.T) {
+
+	client := fake.NewClient()
+	var api infinibox.API = client
+	client.AddEvent(&infinibox.Event{Level: infinibox.EventLevelInfo})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	//page size smaller than number of new events, remaining events are read by next polls
+	events := api.SubscribeEvents(ctx, infinibox.EventFilter{Limit: 2, PollInterval: 10 * time.Millisecond})
+	for i := 0; i < 5; i++ {
+		client.AddEvent(&infinibox.Event{Level: infinibox.EventLevelInfo})
+	}
+
+	for want := int64(2); want <= 6; want++ {
+		select {
+		case event := <-events:
+			if event.ID != want {
+				t.Fatalf("got event %d, want %d", event.ID, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("event %d not received", want)
+		}
+	}
+}
+
+func TestHostChap(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		change  func(api infinibox.API, host *infinibox.Host) error
+		method  string
+		inbound bool
+		secret  string
+	}{
+		{
+			name: "set CHAP",
+			change: func(api infinibox.API, host *infinibox.Host) error {
+				return api.SetHostChap(host, infinibox.ChapCredentials{InboundUsername: "node-1", InboundSecret: "InboundSecret1"})
+			},
+			method:  infinibox.SecurityMethodChap,
+			inbound: true,
+			secret:  "InboundSecret1",
+		},
+		{
+			name: "update host with secret",
+			change: func(api infinibox.API, host *infinibox.Host) error {
+				host.SecurityMethod = "chap"
+				host.SecurityChapInboundUsername = "node-1"
+				host.SecurityChapInboundSecret = "UpdatedSecret1"
+				return api.UpdateHost(host)
+			},
+			method:  infinibox.SecurityMethodChap,
+			inbound: true,
+			secret:  "UpdatedSecret1",
+		},
+		{
+			name: "rotate CHAP",
+			change: func(api infinibox.API, host *infinibox.Host) error {
+				if err := api.SetHostChap(host, infinibox.ChapCredentials{InboundUsername: "node-1", InboundSecret: "InboundSecret1"}); err != nil {
+					return err
+				}
+				_, err := api.RotateHostChap(host, func(_ *infinibox.Host, credentials infinibox.ChapCredentials) error {
+					if credentials.InboundSecret == "InboundSecret1" {
+						return errors.New("secret not rotated")
+					}
+					return nil
+				})
+				return err
+			},
+			method:  infinibox.SecurityMethodChap,
+			inbound: true,
+		},
+		{
+			name: "clear CHAP",
+			change: func(api infinibox.API, host *infinibox.Host) error {
+				if err := api.SetHostChap(host, infinibox.ChapCredentials{InboundUsername: "node-1", InboundSecret: "InboundSecret1"}); err != nil {
+					return err
+				}
+				return api.ClearHostChap(host)
+			},
+			method:  infinibox.SecurityMethodNone,
+			inbound: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			client := fake.NewClient()
+			var api infinibox.API = client
+
+			host := &infinibox.Host{Name: "node-1"}
+			if err := api.CreateHost(host); err != nil {
+				t.Fatal(err)
+			}
+			if err := tt.change(api, host); err != nil {
+				t.Fatal(err)
+			}
+
+			stored, err := api.GetHost(host.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stored.SecurityMethod != tt.method || stored.SecurityChapHasInboundSecret != tt.inbound {
+				t.Fatalf("host security method %s has inbound secret %v, want %s %v", stored.SecurityMethod, stored.SecurityChapHasInboundSecret, tt.method, tt.inbound)
+			}
+			if stored.SecurityChapInboundSecret != "" {
+				t.Fatal("host returned with secret")
+			}
+			credentials, ok := client.HostChap(host.ID)
+			if !ok || credentials.InboundSecret == "" {
+				t.Fatalf("credentials %v not stored", ok)
+			}
+			if tt.secret != "" && credentials.InboundSecret != tt.secret {
+				t.Fatalf("stored secret %s, want %s", credentials.InboundSecret, tt.secret)
+			}
+		})
+	}
+}
+
+func TestSupports(t *testing.T) {
+
+	tests := []struct {
+		name      string
+		set       map[string]bool
+		feature   string
+		supported bool
+		want      error
+	}{
+		{name: "feature without verified version is unknown", feature: infinibox.FeatureNVMe, want: infinibox.ErrFeatureSupportUnknown},
+		{name: "feature set supported", set: map[string]bool{infinibox.FeatureNVMe: true}, feature: infinibox.FeatureNVMe, supported: true},
+		{name: "feature set unsupported", set: map[string]bool{infinibox.FeatureNVMe: false}, feature: infinibox.FeatureNVMe, supported: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			client := fake.NewClient()
+			var api infinibox.API = client
+			for feature, supported := range tt.set {
+				client.SetFeature(feature, supported)
+			}
+
+			supported, err := api.Supports(tt.feature)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("error %v, want %v", err, tt.want)
+			}
+			if supported != tt.supported {
+				t.Fatalf("supported %v, want %v", supported, tt.supported)
+			}
+
+			system, err := api.GetSystem()
+			if err != nil || system.Name != "fake" {
+				t.Fatalf("system %v, error %v", system, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package infinibox
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+//Filesystem represents IBOX filesystem struct
+type Filesystem struct {
+	ID                 int64       `json:"id"`
+	Name               string      `json:"name"`
+	Type               string      `json:"type"`
+	DatasetType        string      `json:"dataset_type"`
+	ParentID           int64       `json:"parent_id"`
+	FamilyID           int         `json:"family_id"`
+	Depth              int         `json:"depth"`
+	HasChildren        bool        `json:"has_children"`
+	PoolID             int64       `json:"pool_id"`
+	PoolName           string      `json:"pool_name"`
+	Size               uint64      `json:"size"`
+	Used               uint64      `json:"used"`
+	Allocated          uint64      `json:"allocated"`
+	TreeAllocated      uint64      `json:"tree_allocated"`
+	Provtype           string      `json:"provtype"`
+	SsdEnabled         bool        `json:"ssd_enabled"`
+	CompressionEnabled bool        `json:"compression_enabled"`
+	CapacitySavings    interface{} `json:"capacity_savings"`
+	WriteProtected     bool        `json:"write_protected"`
+	Mapped             bool        `json:"mapped"`
+	LockState          string      `json:"lock_state"`
+	SecurityStyle      string      `json:"security_style"`
+	CreatedAt          uint64      `json:"created_at"`
+	UpdatedAt          uint64      `json:"updated_at"`
+	TenantID           int64       `json:"tenant_id,omitempty"`
+}
+
+//GetFilesystem get filesystem
+func (c *Client) GetFilesystem(filesystemID int64) (*Filesystem, error) {
+
+	c.debugf("Getting filesystem object ID: %d", filesystemID)
+
+	url := fmt.Sprintf("api/rest/filesystems/%d", filesystemID)
+	response, err := c.request().Get(url)
+
+	result, err := CheckAPIResponse(response, err)
+	if err != nil {
+		return nil, fmt.Errorf("error getting filesystem object, %s", err.Error())
+	}
+
+	var filesystem Filesystem
+	err = json.Unmarshal(*result.APIResult, &filesystem)
+	if err != nil {
+		return nil, fmt.Errorf("error getting filesystem object %s", err.Error())
+	}
+
+	return &filesystem, nil
+}
+
+//GetFilesystemByName get filesystem by name
+func (c *Client) GetFilesystemByName(filesystemname string) (*Filesystem, error) {
+
+	c.debugf("Getting filesystem by name: %s", filesystemname)
+
+	filesystems, err := getAllPages[Filesystem](c, "api/rest/filesystems", map[string]string{"name": "eq:" + filesystemname})
+	if err != nil {
+		return nil, fmt.Errorf("cannot find filesystem by name: %s, error: %s", filesystemname, err.Error())
+	}
+
+	if len(filesystems) == 0 {
+		return nil, fmt.Errorf("filesystem %s not found", filesystemname)
+	}
+
+	return &filesystems[0], nil
+}
+
+//GetAllFilesystems get all defined filesystems
+func (c *Client) GetAllFilesystems() (*[]Filesystem, error) {
+
+	c.debugf("Getting filesystems collection")
+
+	filesystems, err := getAllPages[Filesystem](c, "api/rest/filesystems", nil)
+	if err != nil {
+		return nil, fmt.Errorf("error getting filesystems collection, %s", err.Error())
+	}
+
+	c.debugf("Got %d filesystems", len(filesystems))
+
+	return &filesystems, nil
+}
+
+//SetMetadata sets filesystem metadata key
+func (f *Filesystem) SetMetadata(client *Client, key string, value string) (err error) {
+
+	client.debugf("Setting metadata for filesystem %s", f.Name)
+
+	err = client.AddMetadata(&Metadata{ObjectID: f.ID, Key: key, Value: value})
+	if err != nil {
+		return fmt.Errorf("unable to set metadata for filesystem %s, error %s", f.Name, err.Error())
+	}
+
+	client.debugf("Set metadata for filesystem %s", f.Name)
+
+	return nil
+}
+
+//SetMetadataMap sets all provided filesystem metadata keys in single request
+func (f *Filesystem) SetMetadataMap(client *Client, values map[string]interface{}) (err error) {
+
+	client.debugf("Setting metadata map for filesystem %s", f.Name)
+
+	err = client.AddMetadataMap(f.ID, values)
+	if err != nil {
+		return fmt.Errorf("unable to set metadata for filesystem %s, error %s", f.Name, err.Error())
+	}
+
+	client.debugf("Set metadata map for filesystem %s", f.Name)
+
+	return nil
+}
+
+//GetMetadata returns filesystem metadata, filtered by key when not empty
+func (f *Filesystem) GetMetadata(client *Client, key string) (metadata *[]Metadata, err error) {
+
+	client.debugf("Getting metadata for filesystem %s", f.Name)
+
+	metadata, err = client.GetMetadataByObject(f.ID)
+	if err != nil {
+		return metadata, fmt.Errorf("unable to get metadata for filesystem %s, error %s", f.Name, err.Error())
+	}
+
+	metadata = filterMetadataByKey(metadata, key)
+
+	client.debugf("Got metadata for filesystem %s", f.Name)
+
+	return metadata, nil
+}
+
+//GetMetadataValue returns filesystem metadata key value
+func (f *Filesystem) GetMetadataValue(client *Client, key string) (value interface{}, err error) {
+
+	client.debugf("Getting metadata value for filesystem %s and key %s", f.Name, key)
+
+	metadata, err := client.GetMetadataByObjectAndKey(f.ID, key)
+	if err != nil {
+		return value, fmt.Errorf("unable to get metadata for filesystem %s, error %s", f.Name, err.Error())
+	}
+
+	value = metadata.Value
+
+	client.debugf("Got metadata value for filesystem %s and key %s", f.Name, key)
+
+	return value, nil
+}
+
+//UnSetMetadata removes filesystem metadata key
+func (f *Filesystem) UnSetMetadata(client *Client, key string) (err error) {
+
+	client.debugf("Unsetting metadata for filesystem %s", f.Name)
+
+	err = client.DeleteMetadataByKey(f.ID, key)
+	if err != nil {
+		return fmt.Errorf("unable to unset metadata for filesystem %s, error %s", f.Name, err.Error())
+	}
+
+	client.debugf("Unset metadata for filesystem %s", f.Name)
+
+	return nil
+}
+
+//ClearMetadata removes all filesystem metadata
+func (f *Filesystem) ClearMetadata(client *Client) (err error) {
+
+	client.debugf("Clearing metadata for filesystem %s", f.Name)
+
+	err = client.DeleteMetadata(f.ID)
+	if err != nil {
+		return fmt.Errorf("unable to clear metadata for filesystem %s, error %s", f.Name, err.Error())
+	}
+
+	client.debugf("Cleared metadata for filesystem %s", f.Name)
+
+	return nil
+}
@@ -3,8 +3,7 @@ package infinibox
 import (
 	"encoding/json"
 	"fmt"
-	"github.com/go-resty/resty"
-	log "github.com/sirupsen/logrus"
+	"net/http"
 )
 
 type Port struct {
@@ -44,81 +43,74 @@ type Host struct {
 
 func (c *Client) GetHostByName(hostname string) (*Host, error) {
 
+	host, found, err := cachedLookup(c, cacheHosts, hostname, func() (*Host, bool, error) {
+		return c.lookupHost(hostname)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !found {
+		return nil, fmt.Errorf("host %s not found", hostname)
+	}
+
+	return host, nil
+}
+
+func (c *Client) lookupHost(hostname string) (host *Host, found bool, err error) {
+
 	queryRes, err := c.Find("hosts", "name", "eq", hostname)
 
 	if err != nil {
-		return nil, fmt.Errorf(fmt.Sprintf("cannot find hostname: %s, error: %s", hostname, err.Error()))
+		return nil, false, fmt.Errorf("cannot find hostname: %s, error: %s", hostname, err.Error())
 	}
 
 	if queryRes == nil {
-		return nil, nil
+		return nil, false, nil
 	}
 
 	var hosts []Host
 
 	err = json.Unmarshal(*queryRes, &hosts)
 	if err != nil {
-		return nil, fmt.Errorf(fmt.Sprintf("unable to decode host: %s query result, error: %s", hostname, err.Error()))
+		return nil, false, fmt.Errorf("unable to decode host: %s query result, error: %s", hostname, err.Error())
 	}
 
 	if len(hosts) == 0 {
-		return nil, fmt.Errorf(fmt.Sprintf("host %s not found", hostname))
+		return nil, false, nil
 	}
 
-	log.Debugf("Found host object: %#v", &hosts[0])
+	c.debugf("Found host object: %#v", &hosts[0])
 
-	return &hosts[0], nil
+	return &hosts[0], true, nil
 }
 
 func (c *Client) GetAllHosts() (*[]Host, error) {
 
-	log.Debug("Getting hosts collection")
-
-	url := "api/rest/hosts"
-
-	var request *resty.Request
+	c.debugf("Getting hosts collection")
 
-	if c.config.tenant != "" {
-		log.Debugf("Adding tenant_id %d to request", c.config.tenant)
-		request = c.RestClient.R().SetHeader("X-INFINIDAT-TENANT-ID", c.config.tenant)
-	} else {
-		request = c.RestClient.R()
-	}
-
-	response, err := request.Get(url)
-
-	result, err := CheckAPIResponse(response, err)
+	hosts, err := getAllPages[Host](c, "api/rest/hosts", nil)
 	if err != nil {
-		return nil, fmt.Errorf("error getting hosts collection")
+		return nil, fmt.Errorf("error getting hosts collection, %s", err.Error())
 	}
 
-	num := result.APIMetadata["number_of_objects"]
-	if num == nil {
-		return nil, fmt.Errorf("cannot parse metadata for number_of_objects field")
-	}
-	if num == float64(0) {
-		log.Infof("hosts collection is empty")
+	if len(hosts) == 0 {
+		c.infof("hosts collection is empty")
 		return nil, nil
 	}
 
-	var hosts []Host
-	err = json.Unmarshal(*result.APIResult, &hosts)
-	if err != nil {
-		return nil, fmt.Errorf("error getting hosts collection")
-	}
-
-	log.Debugf("Got hosts collection")
+	c.debugf("Got hosts collection")
 
 	return &hosts, nil
 }
 
 func (c *Client) GetHost(hostID int64) (*Host, error) {
 
-	log.Debugf("Getting host object ID: %d", hostID)
+	c.debugf("Getting host object ID: %d", hostID)
 
 	url := fmt.Sprintf("api/rest/hosts/%d", hostID)
 
-	response, err := c.RestClient.R().Get(url)
+	response, err := c.request().Get(url)
 
 	result, err := CheckAPIResponse(response, err)
 	if err != nil {
@@ -128,31 +120,22 @@ func (c *Client) GetHost(hostID int64) (*Host, error) {
 	var host Host
 	err = json.Unmarshal(*result.APIResult, &host)
 	if err != nil {
-		return nil, fmt.Errorf(fmt.Sprintf("json: %s", err.Error()))
+		return nil, fmt.Errorf("json: %s", err.Error())
 	}
 
-	log.Debugf("Got host object: %#v", host)
+	c.debugf("Got host object: %#v", host)
 
 	return &host, nil
 }
 
 func (c *Client) GetHostIDbyInitiatorAddress(address string) (ID int64, err error) {
 
-	log.Debugf("Getting host ID by initiator addres: %s", address)
+	c.debugf("Getting host ID by initiator addres: %s", address)
 
 	//url := fmt.Sprintf("api/rest/hosts/host_id_by_initiator_address/%s", address)
 	url := "api/rest/hosts"
 
-	var request *resty.Request
-
-	if c.config.tenant != "" {
-		log.Debugf("Adding tenant_id %d to request", c.config.tenant)
-		request = c.RestClient.R().SetHeader("X-INFINIDAT-TENANT-ID", c.config.tenant)
-	} else {
-		request = c.RestClient.R()
-	}
-
-	response, err := request.Get(url)
+	response, err := c.request().Get(url)
 
 	result, err := CheckAPIResponse(response, err)
 	if err != nil {
@@ -163,7 +146,7 @@ func (c *Client) GetHostIDbyInitiatorAddress(address string) (ID int64, err erro
 
 	err = json.Unmarshal(*result.APIResult, &hosts)
 	if err != nil {
-		return -1, fmt.Errorf(fmt.Sprintf("json: %s", err.Error()))
+		return -1, fmt.Errorf("json: %s", err.Error())
 	}
 
 	var host Host
@@ -171,213 +154,218 @@ func (c *Client) GetHostIDbyInitiatorAddress(address string) (ID int64, err erro
 	for _, host = range hosts {
 		for _, port := range host.Ports {
 			if port.Address == address {
-				log.Debugf("Found address")
+				c.debugf("Found address")
 				break
 			}
 		}
 		break
 	}
 	ID = host.ID
-	log.Debugf("Got host ID: %d for address %s", ID, address)
+	c.debugf("Got host ID: %d for address %s", ID, address)
 
 	return ID, nil
 }
 
 func (h *Host) Create(client *Client) (err error) {
 
-	log.Debugf("Creating host: %s", h.Name)
-
-	body := map[string]interface{}{"name": h.Name}
+	client.debugf("Creating host: %s", h.Name)
 
-	if h.SecurityMethod != "" {
-		body["security_method"] = h.SecurityMethod
-	}
-	if h.SecurityChapInboundUsername != "" {
-		body["security_chap_inbound_username"] = h.SecurityChapInboundUsername
-	}
-	if h.SecurityChapInboundSecret != "" {
-		body["security_chap_inbound_secret"] = h.SecurityChapInboundSecret
-	}
-	if h.SecurityChapOutboundUsername != "" {
-		body["security_chap_outbound_username"] = h.SecurityChapOutboundUsername
-	}
-	if h.SecurityChapOutboundSecret != "" {
-		body["security_chap_outbound_secret"] = h.SecurityChapOutboundSecret
+	body, err := h.chapAttributes()
+	if err != nil {
+		return fmt.Errorf("error creating host: %s,  %s", h.Name, err.Error())
 	}
-	url := "api/rest/hosts"
+	body["name"] = h.Name
 
-	var request *resty.Request
-
-	if client.config.tenant != "" {
-		log.Debugf("Adding tenant_id %d to request", client.config.tenant)
-		request = client.RestClient.R().SetHeader("X-INFINIDAT-TENANT-ID", client.config.tenant)
-	} else {
-		request = client.RestClient.R()
-	}
+	url := "api/rest/hosts"
 
-	response, err := request.SetBody(body).Post(url)
+	response, err := client.request().SetBody(body).Post(url)
 
 	result, err := CheckAPIResponse(response, err)
 	if err != nil {
-		return fmt.Errorf(fmt.Sprintf("error creating host: %s,  %s", h.Name, err.Error()))
+		return fmt.Errorf("error creating host: %s,  %s", h.Name, err.Error())
 	}
 
 	err = json.Unmarshal(*result.APIResult, &h)
 	if err != nil {
-		return fmt.Errorf(fmt.Sprintf("error creating host: %s,  %s", h.Name, err.Error()))
+		return fmt.Errorf("error creating host: %s,  %s", h.Name, err.Error())
 	}
 
-	log.Debugf("Successfully created host %s", h.Name)
+	client.debugf("Successfully created host %s", h.Name)
 
 	return nil
 }
 
 func (h *Host) Delete(client *Client) (err error) {
 
-	log.Debugf("Deleting host: %s", h.Name)
+	client.debugf("Deleting host: %s", h.Name)
 
 	url := fmt.Sprintf("api/rest/hosts/%d", h.ID)
-	response, err := client.RestClient.R().SetQueryParam("approved", "true").Delete(url)
+	response, err := client.sendApproved(ApprovalRequest{Operation: OperationDelete, ObjectType: "host", ObjectName: h.Name, Method: http.MethodDelete, URL: url}, nil)
 
 	result, err := CheckAPIResponse(response, err)
 	if err != nil {
-		return fmt.Errorf(fmt.Sprintf("error deleting host: %s,  %s", h.Name, err.Error()))
+		return fmt.Errorf("error deleting host: %s,  %s", h.Name, err.Error())
 	}
 
 	var host Host
 	err = json.Unmarshal(*result.APIResult, &host)
 	if err != nil {
-		return fmt.Errorf(fmt.Sprintf("error deleting host: %s,  %s", h.Name, err.Error()))
+		return fmt.Errorf("error deleting host: %s,  %s", h.Name, err.Error())
 	}
 
-	log.Debugf("Successfully deleted host %s", h.Name)
+	client.debugf("Successfully deleted host %s", h.Name)
 
 	return nil
 }
 
 func (h *Host) Get(client *Client) (host *Host, err error) {
 
-	log.Debugf("Getting host: %s", h.Name)
+	client.debugf("Getting host: %s", h.Name)
 
 	url := fmt.Sprintf("api/rest/hosts/%d", h.ID)
-	response, err := client.RestClient.R().Get(url)
+	response, err := client.request().Get(url)
 
 	result, err := CheckAPIResponse(response, err)
 	if err != nil {
-		return nil, fmt.Errorf(fmt.Sprintf("error getting host: %s,  %s", h.Name, err.Error()))
+		return nil, fmt.Errorf("error getting host: %s,  %s", h.Name, err.Error())
 	}
 
 	err = json.Unmarshal(*result.APIResult, &host)
 	if err != nil {
-		return nil, fmt.Errorf(fmt.Sprintf("error getting host: %s,  %s", h.Name, err.Error()))
+		return nil, fmt.Errorf("error getting host: %s,  %s", h.Name, err.Error())
 	}
 
-	log.Debugf("Successfully fetched host %s", h.Name)
+	client.debugf("Successfully fetched host %s", h.Name)
 
 	return host, nil
 }
 
 func (h *Host) GetPorts(client *Client) (ports *[]Port, err error) {
 
-	log.Debugf("Getting host: %s ports", h.Name)
+	client.debugf("Getting host: %s ports", h.Name)
 
 	url := fmt.Sprintf("api/rest/hosts/%d/ports", h.ID)
-	response, err := client.RestClient.R().Get(url)
+	response, err := client.request().Get(url)
 
 	result, err := CheckAPIResponse(response, err)
 	if err != nil {
-		return nil, fmt.Errorf(fmt.Sprintf("error getting host: %s ports,  %s", h.Name, err.Error()))
+		return nil, fmt.Errorf("error getting host: %s ports,  %s", h.Name, err.Error())
 	}
 
 	err = json.Unmarshal(*result.APIResult, &ports)
 	if err != nil {
-		return nil, fmt.Errorf(fmt.Sprintf("error getting host: %s ports,  %s", h.Name, err.Error()))
+		return nil, fmt.Errorf("error getting host: %s ports,  %s", h.Name, err.Error())
 	}
 
-	log.Debugf("Got host: %s ports", h.Name)
+	client.debugf("Got host: %s ports", h.Name)
 
 	return ports, nil
 }
 
 func (h *Host) Update(client *Client) (err error) {
 
-	log.Debugf("Updating host: %s", h.Name)
+	client.debugf("Updating host: %s", h.Name)
 
 	currentHost, err := h.Get(client)
 	if err != nil {
-		return fmt.Errorf(fmt.Sprintf("host update failed, error: %s", err.Error()))
+		return fmt.Errorf("host update failed, error: %s", err.Error())
+	}
+	body, err := h.chapAttributes()
+	if err != nil {
+		return fmt.Errorf("host update failed, error: %s", err.Error())
 	}
-	body := map[string]interface{}{}
 
 	if currentHost.Name != h.Name {
 		body["name"] = h.Name
-	} else {
-
-		if h.SecurityMethod != "" {
-			body["security_method"] = h.SecurityMethod
-		}
-		if h.SecurityChapInboundUsername != "" {
-			body["security_chap_inbound_username"] = h.SecurityChapInboundUsername
-		}
-		if h.SecurityChapInboundSecret != "" {
-			body["security_chap_inbound_secret"] = h.SecurityChapInboundSecret
-		}
-		if h.SecurityChapOutboundUsername != "" {
-			body["security_chap_outbound_username"] = h.SecurityChapOutboundUsername
-		}
-		if h.SecurityChapOutboundSecret != "" {
-			body["security_chap_outbound_secret"] = h.SecurityChapOutboundSecret
-		}
 	}
 
 	url := fmt.Sprintf("api/rest/hosts/%d", h.ID)
-	response, err := client.RestClient.R().SetBody(body).SetQueryParam("approved", "true").Put(url)
+	response, err := client.sendApproved(ApprovalRequest{Operation: OperationUpdate, ObjectType: "host", ObjectName: h.Name, Method: http.MethodPut, URL: url}, body)
 
 	result, err := CheckAPIResponse(response, err)
 	if err != nil {
-		return fmt.Errorf(fmt.Sprintf("error updating host: %s,  %s", h.Name, err.Error()))
+		return fmt.Errorf("error updating host: %s,  %s", h.Name, err.Error())
 	}
 
 	err = json.Unmarshal(*result.APIResult, &h)
 	if err != nil {
-		return fmt.Errorf(fmt.Sprintf("error updating host: %s,  %s", h.Name, err.Error()))
+		return fmt.Errorf("error updating host: %s,  %s", h.Name, err.Error())
+	}
+
+	client.debugf("Updated host: %s", h.Name)
+
+	return nil
+}
+
+//UpdateName renames host
+func (h *Host) UpdateName(client *Client, name string) error {
+
+	client.debugf("Renaming host %s", h.Name)
+
+	err := h.updateAttributes(client, map[string]interface{}{"name": name})
+	if err != nil {
+		return fmt.Errorf("failed to rename host %s, %s", h.Name, err.Error())
 	}
 
-	log.Debugf("Updated host: %s", h.Name)
+	client.debugf("Succesfully renamed host to %s", h.Name)
+
+	return nil
+}
+
+func (h *Host) updateAttributes(client *Client, attributesMap map[string]interface{}) (err error) {
+
+	client.debugf("Updating host: %s", h.Name)
+
+	if len(attributesMap) > 0 {
+		url := fmt.Sprintf("api/rest/hosts/%d", h.ID)
+		response, err := client.sendApproved(ApprovalRequest{Operation: OperationUpdate, ObjectType: "host", ObjectName: h.Name, Method: http.MethodPut, URL: url}, attributesMap)
+
+		result, err := CheckAPIResponse(response, err)
+		if err != nil {
+			return fmt.Errorf("error updating host: %s,  %s", h.Name, err.Error())
+		}
+
+		err = json.Unmarshal(*result.APIResult, &h)
+		if err != nil {
+			return fmt.Errorf("error updating host: %s,  %s", h.Name, err.Error())
+		}
+	}
+
+	client.debugf("Successfully updated host %s", h.Name)
 
 	return nil
 }
 
 func (h *Host) AddPort(client *Client, port *Port) (err error) {
 
-	log.Debugf("Adding port type: %s address: %s to host: %s", port.Type, port.Address, h.Name)
+	client.debugf("Adding port type: %s address: %s to host: %s", port.Type, port.Address, h.Name)
 
 	body := map[string]interface{}{}
 	body["type"] = port.Type
 	body["address"] = port.Address
 
 	url := fmt.Sprintf("api/rest/hosts/%d/ports", h.ID)
-	response, err := client.RestClient.R().SetBody(body).SetQueryParam("approved", "true").Post(url)
+	response, err := client.sendApproved(ApprovalRequest{Operation: OperationAddPort, ObjectType: "host", ObjectName: h.Name, Method: http.MethodPost, URL: url}, body)
 
 	result, err := CheckAPIResponse(response, err)
 	if err != nil {
-		return fmt.Errorf(fmt.Sprintf("error adding port to host: %s %s", h.Name, err.Error()))
+		return fmt.Errorf("error adding port to host: %s %s", h.Name, err.Error())
 	}
 
 	var newport Port
 	err = json.Unmarshal(*result.APIResult, &newport)
 	if err != nil {
-		return fmt.Errorf(fmt.Sprintf("error adding port to host: %s %s", h.Name, err.Error()))
+		return fmt.Errorf("error adding port to host: %s %s", h.Name, err.Error())
 	}
 
-	log.Debugf("Added port type: %s address: %s to host: %s", port.Type, port.Address, h.Name)
+	client.debugf("Added port type: %s address: %s to host: %s", port.Type, port.Address, h.Name)
 
 	return nil
 }
 
 func (h *Host) AddLUN(client *Client, lun *Lun) (err error) {
 
-	log.Debugf("Adding volume_id: %d as lun to host: %s", lun.VolumeID, h.Name)
+	client.debugf("Adding volume_id: %d as lun to host: %s", lun.VolumeID, h.Name)
 
 	body := map[string]interface{}{}
 
@@ -387,179 +375,182 @@ func (h *Host) AddLUN(client *Client, lun *Lun) (err error) {
 	}
 
 	url := fmt.Sprintf("api/rest/hosts/%d/luns", h.ID)
-	response, err := client.RestClient.R().SetBody(body).SetQueryParam("approved", "true").Post(url)
+	response, err := client.sendApproved(ApprovalRequest{Operation: OperationMap, ObjectType: "host", ObjectName: h.Name, Method: http.MethodPost, URL: url}, body)
 
 	result, err := CheckAPIResponse(response, err)
 	if err != nil {
-		return fmt.Errorf(fmt.Sprintf("error adding lun to host: %s %s", h.Name, err.Error()))
+		return fmt.Errorf("error adding lun to host: %s %s", h.Name, err.Error())
 	}
 
 	var newlun Lun
 	err = json.Unmarshal(*result.APIResult, &newlun)
 	if err != nil {
-		return fmt.Errorf(fmt.Sprintf("error adding lun to host: %s %s", h.Name, err.Error()))
+		return fmt.Errorf("error adding lun to host: %s %s", h.Name, err.Error())
 	}
+	*lun = newlun
 
-	log.Debugf("Added volume_id: %d as lun to host: %s", lun.VolumeID, h.Name)
+	client.debugf("Added volume_id: %d as lun %d to host: %s", lun.VolumeID, lun.Lun, h.Name)
 
 	return nil
 }
 
 func (h *Host) GetLUNs(client *Client) (luns *[]Lun, err error) {
 
-	log.Debugf("Getting host: %s luns", h.Name)
+	client.debugf("Getting host: %s luns", h.Name)
 
 	url := fmt.Sprintf("api/rest/hosts/%d/luns", h.ID)
-	response, err := client.RestClient.R().Get(url)
+	response, err := client.request().Get(url)
 
 	result, err := CheckAPIResponse(response, err)
 	if err != nil {
-		return nil, fmt.Errorf(fmt.Sprintf("error getting host: %s luns,  %s", h.Name, err.Error()))
+		return nil, fmt.Errorf("error getting host: %s luns,  %s", h.Name, err.Error())
 	}
 
 	err = json.Unmarshal(*result.APIResult, &luns)
 	if err != nil {
-		return nil, fmt.Errorf(fmt.Sprintf("error getting host: %s luns,  %s", h.Name, err.Error()))
+		return nil, fmt.Errorf("error getting host: %s luns,  %s", h.Name, err.Error())
 	}
 
-	log.Debugf("Got host: %s luns", h.Name)
+	client.debugf("Got host: %s luns", h.Name)
 
 	return luns, nil
 }
 
 func (h *Host) GetLUN(client *Client, lunID int) (lun *Lun, err error) {
 
-	log.Debugf("Getting host: %s lun ID %d", h.Name, lunID)
+	client.debugf("Getting host: %s lun ID %d", h.Name, lunID)
 
 	url := fmt.Sprintf("api/rest/hosts/%d/luns/%d", h.ID, lunID)
-	response, err := client.RestClient.R().Get(url)
+	response, err := client.request().Get(url)
 
 	result, err := CheckAPIResponse(response, err)
 	if err != nil {
-		return nil, fmt.Errorf(fmt.Sprintf("error getting host: %s lun ID %d ,  %s", h.Name, lunID, err.Error()))
+		return nil, fmt.Errorf("error getting host: %s lun ID %d ,  %s", h.Name, lunID, err.Error())
 	}
 
 	err = json.Unmarshal(*result.APIResult, &lun)
 	if err != nil {
-		return nil, fmt.Errorf(fmt.Sprintf("error getting host: %s lun ID %d,  %s", h.Name, lunID, err.Error()))
+		return nil, fmt.Errorf("error getting host: %s lun ID %d,  %s", h.Name, lunID, err.Error())
 	}
 
-	log.Debugf("Got host: %s lun ID %d", h.Name, lunID)
+	client.debugf("Got host: %s lun ID %d", h.Name, lunID)
 
 	return lun, nil
 }
 
 func (h *Host) DeleteLUN(client *Client, lunID int) (lun *Lun, err error) {
 
-	log.Debugf("Deleting Lun ID %d for host %s", lunID, h.Name)
+	client.debugf("Deleting Lun ID %d for host %s", lunID, h.Name)
 
 	url := fmt.Sprintf("api/rest/hosts/%d/luns/lun/%d", h.ID, lunID)
-	response, err := client.RestClient.R().SetQueryParam("approved", "true").Delete(url)
+	response, err := client.sendApproved(ApprovalRequest{Operation: OperationUnmap, ObjectType: "host", ObjectName: h.Name, Method: http.MethodDelete, URL: url}, nil)
 
 	result, err := CheckAPIResponse(response, err)
 	if err != nil {
-		return nil, fmt.Errorf(fmt.Sprintf("error deleting host: %s lun ID %d ,  %s", h.Name, lunID, err.Error()))
+		return nil, fmt.Errorf("error deleting host: %s lun ID %d ,  %s", h.Name, lunID, err.Error())
 	}
 
 	err = json.Unmarshal(*result.APIResult, &lun)
 	if err != nil {
-		return nil, fmt.Errorf(fmt.Sprintf("error deleting host: %s lun ID %d,  %s", h.Name, lunID, err.Error()))
+		return nil, fmt.Errorf("error deleting host: %s lun ID %d,  %s", h.Name, lunID, err.Error())
 	}
 
-	log.Debugf("Deleting Lun ID %d for host %s", lunID, h.Name)
+	client.debugf("Deleted Lun ID %d for host %s", lunID, h.Name)
 
 	return lun, nil
 }
 
 func (h *Host) UnMapVolume(client *Client, volumeID uint64) (lun *Lun, err error) {
 
-	log.Debugf("Unmapping volume ID: %d from host %s", volumeID, h.Name)
+	client.debugf("Unmapping volume ID: %d from host %s", volumeID, h.Name)
 
 	url := fmt.Sprintf("api/rest/hosts/%d/luns/volume_id/%d", h.ID, volumeID)
-	response, err := client.RestClient.R().SetQueryParam("approved", "true").Delete(url)
+	response, err := client.sendApproved(ApprovalRequest{Operation: OperationUnmap, ObjectType: "host", ObjectName: h.Name, Method: http.MethodDelete, URL: url}, nil)
 
 	result, err := CheckAPIResponse(response, err)
 	if err != nil {
-		return nil, fmt.Errorf(fmt.Sprintf("error umapping volume ID %d from host: %s, %s", volumeID, h.Name, err.Error()))
+		return nil, fmt.Errorf("error umapping volume ID %d from host: %s, %s", volumeID, h.Name, err.Error())
 	}
 
 	err = json.Unmarshal(*result.APIResult, &lun)
 	if err != nil {
-		return nil, fmt.Errorf(fmt.Sprintf("error umapping volume ID %d from host: %s, %s", volumeID, h.Name, err.Error()))
+		return nil, fmt.Errorf("error umapping volume ID %d from host: %s, %s", volumeID, h.Name, err.Error())
 	}
 
-	log.Debugf("Unmapped volume ID: %d from host %s", volumeID, h.Name)
+	client.debugf("Unmapped volume ID: %d from host %s", volumeID, h.Name)
 	return lun, nil
 }
 
 func (h *Host) SetMetadata(client *Client, key string, value string) (err error) {
 
-	log.Debugf("Setting metadata for host %s", h.Name)
+	client.debugf("Setting metadata for host %s", h.Name)
 
 	err = client.AddMetadata(&Metadata{ObjectID: h.ID, Key: key, Value: value})
 	if err != nil {
-		return fmt.Errorf(fmt.Sprintf("unable to set metadata for host %s, error %s", h.Name, err.Error()))
+		return fmt.Errorf("unable to set metadata for host %s, error %s", h.Name, err.Error())
 	}
 
-	log.Debugf("Set metadata for host %s", h.Name)
+	client.debugf("Set metadata for host %s", h.Name)
 
 	return nil
 }
 
 func (h *Host) GetMetadata(client *Client, key string) (metadata *[]Metadata, err error) {
 
-	log.Debugf("Getting metadata for host %s", h.Name)
+	client.debugf("Getting metadata for host %s", h.Name)
 
 	metadata, err = client.GetMetadataByObject(h.ID)
 	if err != nil {
-		return metadata, fmt.Errorf(fmt.Sprintf("unable to get metadata for host %s, error %s", h.Name, err.Error()))
+		return metadata, fmt.Errorf("unable to get metadata for host %s, error %s", h.Name, err.Error())
 	}
 
-	log.Debugf("Got metadata for host %s", h.Name)
+	metadata = filterMetadataByKey(metadata, key)
+
+	client.debugf("Got metadata for host %s", h.Name)
 
 	return metadata, nil
 }
 
 func (h *Host) GetMetadataValue(client *Client, key string) (value interface{}, err error) {
 
-	log.Debugf("Getting metadata value for host %s and key %s", h.Name, key)
+	client.debugf("Getting metadata value for host %s and key %s", h.Name, key)
 
 	metadata, err := client.GetMetadataByObjectAndKey(h.ID, key)
 	if err != nil {
-		return value, fmt.Errorf(fmt.Sprintf("unable to get metadata for host %s, error %s", h.Name, err.Error()))
+		return value, fmt.Errorf("unable to get metadata for host %s, error %s", h.Name, err.Error())
 	}
 
 	value = metadata.Value
 
-	log.Debugf("Got metadata value for host %s and key %s", h.Name, key)
+	client.debugf("Got metadata value for host %s and key %s", h.Name, key)
 
 	return value, nil
 }
 
 func (h *Host) UnSetMetadata(client *Client, key string) (err error) {
 
-	log.Debugf("Setting metadata for host %s", h.Name)
+	client.debugf("Setting metadata for host %s", h.Name)
 
 	err = client.DeleteMetadataByKey(h.ID, key)
 	if err != nil {
-		return fmt.Errorf(fmt.Sprintf("unable to unset metadata for host %s, error %s", h.Name, err.Error()))
+		return fmt.Errorf("unable to unset metadata for host %s, error %s", h.Name, err.Error())
 	}
 
-	log.Debugf("Set metadata for host %s", h.Name)
+	client.debugf("Set metadata for host %s", h.Name)
 
 	return nil
 }
 
 func (h *Host) ClearMetadata(client *Client) (err error) {
 
-	log.Debugf("Clearing metadata for host %s", h.Name)
+	client.debugf("Clearing metadata for host %s", h.Name)
 
 	err = client.DeleteMetadata(h.ID)
 	if err != nil {
-		return fmt.Errorf(fmt.Sprintf("unable to clear metadata for host %s, error %s", h.Name, err.Error()))
+		return fmt.Errorf("unable to clear metadata for host %s, error %s", h.Name, err.Error())
 	}
 
-	log.Debugf("Cleared metadata for host %s", h.Name)
+	client.debugf("Cleared metadata for host %s", h.Name)
 
 	return nil
 }
@@ -3,8 +3,7 @@ package infinibox
 import (
 	"encoding/json"
 	"fmt"
-	"github.com/go-resty/resty"
-	log "github.com/sirupsen/logrus"
+	"net/http"
 	"sync"
 )
 
@@ -23,12 +22,12 @@ type HostCluster struct {
 
 func (c *Client) GetHostClusterByName(clustername string) (*HostCluster, error) {
 
-	log.Infof("querying host cluster by name: %s", clustername)
+	c.infof("querying host cluster by name: %s", clustername)
 
 	queryRes, err := c.Find("clusters", "name", "eq", clustername)
 
 	if err != nil {
-		return nil, fmt.Errorf(fmt.Sprintf("cannot find hostc luster: %s, error: %s", clustername, err.Error()))
+		return nil, fmt.Errorf("cannot find hostc luster: %s, error: %s", clustername, err.Error())
 	}
 
 	if queryRes == nil {
@@ -39,11 +38,11 @@ func (c *Client) GetHostClusterByName(clustername string) (*HostCluster, error)
 
 	err = json.Unmarshal(*queryRes, &hostclusters)
 	if err != nil {
-		return nil, fmt.Errorf(fmt.Sprintf("unable to decode host cluster: %s query result, error: %s", clustername, err.Error()))
+		return nil, fmt.Errorf("unable to decode host cluster: %s query result, error: %s", clustername, err.Error())
 	}
 
 	if len(hostclusters) == 0 {
-		return nil, fmt.Errorf(fmt.Sprintf("host cluster %s not found", clustername))
+		return nil, fmt.Errorf("host cluster %s not found", clustername)
 	}
 
 	return &hostclusters[0], nil
@@ -51,190 +50,184 @@ func (c *Client) GetHostClusterByName(clustername string) (*HostCluster, error)
 
 func (c *Client) GetAllHostClusters() (*[]HostCluster, error) {
 
-	log.Debug("Getting host clusters collection")
+	c.debugf("Getting host clusters collection")
 
-	url := "api/rest/clusters"
-	var request *resty.Request
-
-	if c.config.tenant != "" {
-		log.Debugf("Adding tenant_id %d to request", &c.config.tenant)
-		request = c.RestClient.R().SetHeader("X-INFINIDAT-TENANT-ID", c.config.tenant)
-	} else {
-		request = c.RestClient.R()
-	}
-
-	response, err := request.Get(url)
-
-	result, err := CheckAPIResponse(response, err)
+	clusters, err := getAllPages[HostCluster](c, "api/rest/clusters", nil)
 	if err != nil {
-		return nil, fmt.Errorf("error getting hosts collection")
+		return nil, fmt.Errorf("error getting host clusters collection, %s", err.Error())
 	}
 
-	num := result.APIMetadata["number_of_objects"]
-	if num == nil {
-		return nil, fmt.Errorf("cannot parse metadata for number_of_objects field")
-	}
-	if num == float64(0) {
-		log.Infof("host clusters collection is empty")
+	if len(clusters) == 0 {
+		c.infof("host clusters collection is empty")
 		return nil, nil
 	}
 
-	var hosts []HostCluster
-	err = json.Unmarshal(*result.APIResult, &hosts)
-	if err != nil {
-		return nil, fmt.Errorf("error getting host clusters collection")
-	}
+	c.debugf("Got host clusters collection")
 
-	log.Debugf("Successfully fetched host clusters collection")
-	return &hosts, nil
+	return &clusters, nil
 }
 
 func (hc *HostCluster) Create(client *Client) (err error) {
 
-	log.Debugf("Creating host cluster: %s", hc.Name)
+	client.debugf("Creating host cluster: %s", hc.Name)
 
 	body := map[string]interface{}{"name": hc.Name}
 
 	url := "api/rest/clusters"
-	var request *resty.Request
+	response, err := client.request().SetBody(body).Post(url)
 
-	if client.config.tenant != "" {
-		log.Debugf("Adding tenant_id %d to request", client.config.tenant)
-		request = client.RestClient.R().SetHeader("X-INFINIDAT-TENANT-ID", client.config.tenant)
-	} else {
-		request = client.RestClient.R()
+	result, err := CheckAPIResponse(response, err)
+	if err != nil {
+		return fmt.Errorf("error creating host cluster: %s,  %s", hc.Name, err.Error())
 	}
 
-	response, err := request.SetBody(body).Post(url)
+	err = json.Unmarshal(*result.APIResult, &hc)
+	if err != nil {
+		return fmt.Errorf("error creating host cluster: %s,  %s", hc.Name, err.Error())
+	}
+
+	client.debugf("Successfully created host cluster %s", hc.Name)
+	return nil
+}
+
+//UpdateName renames host cluster
+func (hc *HostCluster) UpdateName(client *Client, name string) error {
+
+	client.debugf("Renaming host cluster %s", hc.Name)
+
+	url := fmt.Sprintf("api/rest/clusters/%d", hc.ID)
+	body := map[string]interface{}{"name": name}
+	response, err := client.sendApproved(ApprovalRequest{Operation: OperationUpdate, ObjectType: "cluster", ObjectName: hc.Name, Method: http.MethodPut, URL: url}, body)
 
 	result, err := CheckAPIResponse(response, err)
 	if err != nil {
-		return fmt.Errorf(fmt.Sprintf("error creating host cluster: %s,  %s", hc.Name, err.Error()))
+		return fmt.Errorf("failed to rename host cluster %s, %s", hc.Name, err.Error())
 	}
 
-	err = json.Unmarshal(*result.APIResult, &hc)
+	err = json.Unmarshal(*result.APIResult, hc)
 	if err != nil {
-		return fmt.Errorf(fmt.Sprintf("error creating host cluster: %s,  %s", hc.Name, err.Error()))
+		return fmt.Errorf("failed to rename host cluster %s, %s", hc.Name, err.Error())
 	}
 
-	log.Debugf("Successfully created host cluster %s", hc.Name)
+	client.debugf("Succesfully renamed host cluster to %s", hc.Name)
+
 	return nil
 }
 
 func (hc *HostCluster) Delete(client *Client) (err error) {
 
-	log.Infof("Deleting host cluster: %s", hc.Name)
+	client.infof("Deleting host cluster: %s", hc.Name)
 
 	url := fmt.Sprintf("api/rest/clusters/%d", hc.ID)
-	response, err := client.RestClient.R().SetQueryParam("approved", "true").Delete(url)
+	response, err := client.sendApproved(ApprovalRequest{Operation: OperationDelete, ObjectType: "cluster", ObjectName: hc.Name, Method: http.MethodDelete, URL: url}, nil)
 
 	result, err := CheckAPIResponse(response, err)
 	if err != nil {
-		return fmt.Errorf(fmt.Sprintf("error deleting host cluster: %s,  %s", hc.Name, err.Error()))
+		return fmt.Errorf("error deleting host cluster: %s,  %s", hc.Name, err.Error())
 	}
 
 	var hostcluster HostCluster
 	err = json.Unmarshal(*result.APIResult, &hostcluster)
 	if err != nil {
-		return fmt.Errorf(fmt.Sprintf("error deleting host cluster: %s,  %s", hc.Name, err.Error()))
+		return fmt.Errorf("error deleting host cluster: %s,  %s", hc.Name, err.Error())
 	}
 
-	log.Debugf("Successfully deleted host cluster %s", hc.Name)
+	client.debugf("Successfully deleted host cluster %s", hc.Name)
 	return nil
 }
 
 func (hc *HostCluster) Get(client *Client) (host *Host, err error) {
 
-	log.Infof("Getting host: %s", hc.Name)
+	client.infof("Getting host: %s", hc.Name)
 
 	url := fmt.Sprintf("api/rest/clusters/%d", hc.ID)
-	response, err := client.RestClient.R().Get(url)
+	response, err := client.request().Get(url)
 
 	result, err := CheckAPIResponse(response, err)
 	if err != nil {
-		return nil, fmt.Errorf(fmt.Sprintf("error getting host cluster: %s,  %s", hc.Name, err.Error()))
+		return nil, fmt.Errorf("error getting host cluster: %s,  %s", hc.Name, err.Error())
 	}
 
 	err = json.Unmarshal(*result.APIResult, &host)
 	if err != nil {
-		return nil, fmt.Errorf(fmt.Sprintf("error getting host cluster: %s,  %s", hc.Name, err.Error()))
+		return nil, fmt.Errorf("error getting host cluster: %s,  %s", hc.Name, err.Error())
 	}
 
-	log.Debugf("Successfully fetched host cluster %s", hc.Name)
+	client.debugf("Successfully fetched host cluster %s", hc.Name)
 	return host, nil
 }
 
 func (hc *HostCluster) AddHost(client *Client, hostID uint64) (err error) {
 
-	log.Debugf("Adding hostID %d to host cluster: %s", hostID, hc.Name)
+	client.debugf("Adding hostID %d to host cluster: %s", hostID, hc.Name)
 
 	body := map[string]interface{}{}
 	body["id"] = hostID
 
 	url := fmt.Sprintf("api/rest/clusters/%d/hosts", hc.ID)
-	response, err := client.RestClient.R().SetBody(body).Post(url)
+	response, err := client.request().SetBody(body).Post(url)
 
 	result, err := CheckAPIResponse(response, err)
 	if err != nil {
-		return fmt.Errorf(fmt.Sprintf("error adding hostID %d to host cluster: %s %s", hostID, hc.Name, err.Error()))
+		return fmt.Errorf("error adding hostID %d to host cluster: %s %s", hostID, hc.Name, err.Error())
 	}
 
 	var newport Port
 	err = json.Unmarshal(*result.APIResult, &newport)
 	if err != nil {
-		return fmt.Errorf(fmt.Sprintf("error adding hostID %d to host cluster: %s %s", hostID, hc.Name, err.Error()))
+		return fmt.Errorf("error adding hostID %d to host cluster: %s %s", hostID, hc.Name, err.Error())
 	}
 
-	log.Debugf("Successfully added hostID %d to host cluster %s", hostID, hc.Name)
+	client.debugf("Successfully added hostID %d to host cluster %s", hostID, hc.Name)
 	return nil
 }
 
 func (hc *HostCluster) GetHosts(client *Client) (hosts *[]Host, err error) {
 
-	log.Debugf("Getting host cluster: %s hosts", hc.Name)
+	client.debugf("Getting host cluster: %s hosts", hc.Name)
 
 	url := fmt.Sprintf("api/rest/clusters/%d/hosts", hc.ID)
-	response, err := client.RestClient.R().Get(url)
+	response, err := client.request().Get(url)
 
 	result, err := CheckAPIResponse(response, err)
 	if err != nil {
-		return nil, fmt.Errorf(fmt.Sprintf("error getting host cluster: %s hosts,  %s", hc.Name, err.Error()))
+		return nil, fmt.Errorf("error getting host cluster: %s hosts,  %s", hc.Name, err.Error())
 	}
 
 	err = json.Unmarshal(*result.APIResult, &hosts)
 	if err != nil {
-		return nil, fmt.Errorf(fmt.Sprintf("error getting host cluster: %s hosts,  %s", hc.Name, err.Error()))
+		return nil, fmt.Errorf("error getting host cluster: %s hosts,  %s", hc.Name, err.Error())
 	}
 
-	log.Debugf("Successfully fetched host cluster %s hosts", hc.Name)
+	client.debugf("Successfully fetched host cluster %s hosts", hc.Name)
 	return hosts, nil
 }
 
 func (hc *HostCluster) DeleteHost(client *Client, hostID uint64) (err error) {
 
-	log.Debugf("Deleting hostID %d from host cluster: %s", hostID, hc.Name)
+	client.debugf("Deleting hostID %d from host cluster: %s", hostID, hc.Name)
 
 	url := fmt.Sprintf("api/rest/clusters/%d/hosts/%d", hc.ID, hostID)
-	response, err := client.RestClient.R().Delete(url)
+	response, err := client.request().Delete(url)
 
 	result, err := CheckAPIResponse(response, err)
 	if err != nil {
-		return fmt.Errorf(fmt.Sprintf("error removing hostID %d from host cluster: %s %s", hostID, hc.Name, err.Error()))
+		return fmt.Errorf("error removing hostID %d from host cluster: %s %s", hostID, hc.Name, err.Error())
 	}
 
 	var newport Port
 	err = json.Unmarshal(*result.APIResult, &newport)
 	if err != nil {
-		return fmt.Errorf(fmt.Sprintf("error removing hostID %d from host cluster: %s %s", hostID, hc.Name, err.Error()))
+		return fmt.Errorf("error removing hostID %d from host cluster: %s %s", hostID, hc.Name, err.Error())
 	}
 
-	log.Debugf("Successfully deleted hostID %d from host cluster %s", hostID, hc.Name)
+	client.debugf("Successfully deleted hostID %d from host cluster %s", hostID, hc.Name)
 	return nil
 }
 
 func (hc *HostCluster) AddLUN(client *Client, lun *Lun) (err error) {
 
-	log.Debugf("Adding volume_id: %d as lun to host cluster: %s", lun.VolumeID, hc.Name)
+	client.debugf("Adding volume_id: %d as lun to host cluster: %s", lun.VolumeID, hc.Name)
 
 	body := map[string]interface{}{}
 
@@ -247,75 +240,76 @@ func (hc *HostCluster) AddLUN(client *Client, lun *Lun) (err error) {
 	defer hc.mu.Unlock()
 
 	url := fmt.Sprintf("api/rest/clusters/%d/luns", hc.ID)
-	response, err := client.RestClient.R().SetBody(body).SetQueryParam("approved", "true").Post(url)
+	response, err := client.sendApproved(ApprovalRequest{Operation: OperationMap, ObjectType: "cluster", ObjectName: hc.Name, Method: http.MethodPost, URL: url}, body)
 
 	result, err := CheckAPIResponse(response, err)
 	if err != nil {
-		return fmt.Errorf(fmt.Sprintf("error adding lun to host cluster: %s %s", hc.Name, err.Error()))
+		return fmt.Errorf("error adding lun to host cluster: %s %s", hc.Name, err.Error())
 	}
 
 	var newlun Lun
 	err = json.Unmarshal(*result.APIResult, &newlun)
 	if err != nil {
-		return fmt.Errorf(fmt.Sprintf("error adding lun to host cluster: %s %s", hc.Name, err.Error()))
+		return fmt.Errorf("error adding lun to host cluster: %s %s", hc.Name, err.Error())
 	}
+	*lun = newlun
 
-	log.Debugf("Successfully added new LUN %+v to host cluster %s", newlun, hc.Name)
+	client.debugf("Successfully added new LUN %+v to host cluster %s", newlun, hc.Name)
 	return nil
 }
 
 func (hc *HostCluster) GetLUNs(client *Client) (luns *[]Lun, err error) {
 
-	log.Debugf("Getting host cluster: %s luns", hc.Name)
+	client.debugf("Getting host cluster: %s luns", hc.Name)
 
 	hc.mu.Lock()
 	defer hc.mu.Unlock()
 
 	url := fmt.Sprintf("api/rest/clusters/%d/luns", hc.ID)
-	response, err := client.RestClient.R().Get(url)
+	response, err := client.request().Get(url)
 
 	result, err := CheckAPIResponse(response, err)
 	if err != nil {
-		return nil, fmt.Errorf(fmt.Sprintf("error getting host cluster: %s luns,  %s", hc.Name, err.Error()))
+		return nil, fmt.Errorf("error getting host cluster: %s luns,  %s", hc.Name, err.Error())
 	}
 
 	err = json.Unmarshal(*result.APIResult, &luns)
 	if err != nil {
-		return nil, fmt.Errorf(fmt.Sprintf("error getting host cluster: %s luns,  %s", hc.Name, err.Error()))
+		return nil, fmt.Errorf("error getting host cluster: %s luns,  %s", hc.Name, err.Error())
 	}
 
-	log.Debugf("Successfully fetched host cluster %s LUNs", hc.Name)
+	client.debugf("Successfully fetched host cluster %s LUNs", hc.Name)
 	return luns, nil
 }
 
 func (hc *HostCluster) DeleteLUN(client *Client, lunID int) (lun *Lun, err error) {
 
-	log.Debugf("Deleting host cluster: %s lun ID %d", hc.Name, lunID)
+	client.debugf("Deleting host cluster: %s lun ID %d", hc.Name, lunID)
 
 	url := fmt.Sprintf("api/rest/clusters/%d/luns/lun/%d", hc.ID, lunID)
-	response, err := client.RestClient.R().SetQueryParam("approved", "true").Delete(url)
+	response, err := client.sendApproved(ApprovalRequest{Operation: OperationUnmap, ObjectType: "cluster", ObjectName: hc.Name, Method: http.MethodDelete, URL: url}, nil)
 
 	result, err := CheckAPIResponse(response, err)
 	if err != nil {
-		return nil, fmt.Errorf(fmt.Sprintf("error deleting host cluster: %s lun ID %d ,  %s", hc.Name, lunID, err.Error()))
+		return nil, fmt.Errorf("error deleting host cluster: %s lun ID %d ,  %s", hc.Name, lunID, err.Error())
 	}
 
 	err = json.Unmarshal(*result.APIResult, &lun)
 	if err != nil {
-		return nil, fmt.Errorf(fmt.Sprintf("error deleting host cluster: %s lun ID %d,  %s", hc.Name, lunID, err.Error()))
+		return nil, fmt.Errorf("error deleting host cluster: %s lun ID %d,  %s", hc.Name, lunID, err.Error())
 	}
 
-	log.Debugf("Successfully deleted host cluster %s LUN %d", hc.Name, lunID)
+	client.debugf("Successfully deleted host cluster %s LUN %d", hc.Name, lunID)
 	return lun, nil
 }
 
 func (hc *HostCluster) SetMetadata(client *Client, key string, value string) (err error) {
 
-	log.Debugf("Setting metadata for host cluster %s", hc.Name)
+	client.debugf("Setting metadata for host cluster %s", hc.Name)
 
 	err = client.AddMetadata(&Metadata{ObjectID: hc.ID, Key: key, Value: value})
 	if err != nil {
-		return fmt.Errorf(fmt.Sprintf("unable to set metadata for host cluster %s, error %s", hc.Name, err.Error()))
+		return fmt.Errorf("unable to set metadata for host cluster %s, error %s", hc.Name, err.Error())
 	}
 
 	return nil
@@ -323,23 +317,25 @@ func (hc *HostCluster) SetMetadata(client *Client, key string, value string) (er
 
 func (hc *HostCluster) GetMetadata(client *Client, key string) (metadata *[]Metadata, err error) {
 
-	log.Debugf("Setting metadata for host cluster %s", hc.Name)
+	client.debugf("Setting metadata for host cluster %s", hc.Name)
 
 	metadata, err = client.GetMetadataByObject(hc.ID)
 	if err != nil {
-		return metadata, fmt.Errorf(fmt.Sprintf("unable to get metadata for host cluster %s, error %s", hc.Name, err.Error()))
+		return metadata, fmt.Errorf("unable to get metadata for host cluster %s, error %s", hc.Name, err.Error())
 	}
 
+	metadata = filterMetadataByKey(metadata, key)
+
 	return metadata, nil
 }
 
 func (hc *HostCluster) GetMetadataValue(client *Client, key string) (value interface{}, err error) {
 
-	log.Debugf("Setting metadata for host cluster %s", hc.Name)
+	client.debugf("Setting metadata for host cluster %s", hc.Name)
 
 	metadata, err := client.GetMetadataByObjectAndKey(hc.ID, key)
 	if err != nil {
-		return value, fmt.Errorf(fmt.Sprintf("unable to get metadata for host cluster %s, error %s", hc.Name, err.Error()))
+		return value, fmt.Errorf("unable to get metadata for host cluster %s, error %s", hc.Name, err.Error())
 	}
 
 	value = metadata.Value
@@ -349,11 +345,11 @@ func (hc *HostCluster) GetMetadataValue(client *Client, key string) (value inter
 
 func (hc *HostCluster) UnSetMetadata(client *Client, key string) (err error) {
 
-	log.Debugf("Setting metadata for host cluster %s", hc.Name)
+	client.debugf("Setting metadata for host cluster %s", hc.Name)
 
 	err = client.DeleteMetadataByKey(hc.ID, key)
 	if err != nil {
-		return fmt.Errorf(fmt.Sprintf("unable to unset metadata for host cluster %s, error %s", hc.Name, err.Error()))
+		return fmt.Errorf("unable to unset metadata for host cluster %s, error %s", hc.Name, err.Error())
 	}
 
 	return nil
@@ -361,11 +357,11 @@ func (hc *HostCluster) UnSetMetadata(client *Client, key string) (err error) {
 
 func (hc *HostCluster) ClearMetadata(client *Client) (err error) {
 
-	log.Debugf("Setting metadata for host cluster %s", hc.Name)
+	client.debugf("Setting metadata for host cluster %s", hc.Name)
 
 	err = client.DeleteMetadata(hc.ID)
 	if err != nil {
-		return fmt.Errorf(fmt.Sprintf("unable to clear metadata for host %s, error %s", hc.Name, err.Error()))
+		return fmt.Errorf("unable to clear metadata for host %s, error %s", hc.Name, err.Error())
 	}
 
 	return nil
@@ -3,8 +3,6 @@ package infinibox
 import (
 	"encoding/json"
 	"fmt"
-	"github.com/go-resty/resty"
-	log "github.com/sirupsen/logrus"
 )
 
 type Initiator struct {
@@ -21,28 +19,19 @@ type Initiator struct {
 
 func (c *Client) GetAllInitiators() (initiators *[]Initiator, err error) {
 
-	log.Debug("Getting all initiators")
+	c.debugf("Getting all initiators")
 
 	url := "api/rest/initiators"
-	var request *resty.Request
-
-	if c.config.tenant != "" {
-		log.Debugf("Adding tenant_id %d to request", &c.config.tenant)
-		request = c.RestClient.R().SetQueryParam("tenant_id", c.config.tenant)
-	} else {
-		request = c.RestClient.R()
-	}
-
-	response, err := request.Get(url)
+	response, err := c.request().Get(url)
 
 	result, err := CheckAPIResponse(response, err)
 	if err != nil {
-		return nil, fmt.Errorf(fmt.Sprintf("error getting all initiators %s", err.Error()))
+		return nil, fmt.Errorf("error getting all initiators %s", err.Error())
 	}
 
 	err = json.Unmarshal(*result.APIResult, &initiators)
 	if err != nil {
-		return nil, fmt.Errorf(fmt.Sprintf("error getting all initiators, error: %s", err.Error()))
+		return nil, fmt.Errorf("error getting all initiators, error: %s", err.Error())
 	}
 
 	return initiators, nil
@@ -50,29 +39,20 @@ func (c *Client) GetAllInitiators() (initiators *[]Initiator, err error) {
 
 func (c *Client) GetInitiatorByAddress(address string) (initiator *Initiator, err error) {
 
-	log.Debugf("Getting initiator by address: %s", address)
+	c.debugf("Getting initiator by address: %s", address)
 
 	url := fmt.Sprintf("api/rest/initiators/%s", address)
 
-	var request *resty.Request
-
-	if c.config.tenant != "" {
-		log.Debugf("Adding tenant_id %d to request", &c.config.tenant)
-		request = c.RestClient.R().SetHeader("X-INFINIDAT-TENANT-ID", c.config.tenant)
-	} else {
-		request = c.RestClient.R()
-	}
-
-	response, err := request.Get(url)
+	response, err := c.request().Get(url)
 
 	result, err := CheckAPIResponse(response, err)
 	if err != nil {
-		return nil, fmt.Errorf(fmt.Sprintf("error getting initiator by address %s", err.Error()))
+		return nil, fmt.Errorf("error getting initiator by address %s", err.Error())
 	}
 
 	err = json.Unmarshal(*result.APIResult, &initiator)
 	if err != nil {
-		return nil, fmt.Errorf(fmt.Sprintf("error getting initiator by address, error: %s", err.Error()))
+		return nil, fmt.Errorf("error getting initiator by address, error: %s", err.Error())
 	}
 
 	return initiator, nil
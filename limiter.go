@@ -0,0 +1,157 @@
+package infinibox
+
+import (
+	"context"
+	"fmt"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/time/rate"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+//EndpointClass groups management api endpoints sharing in-flight request cap
+type EndpointClass string
+
+//Endpoint classes of management api requests
+const (
+	EndpointClassRead    EndpointClass = "read"
+	EndpointClassWrite   EndpointClass = "write"
+	EndpointClassMapping EndpointClass = "mapping"
+)
+
+//Limiter kinds reported with wait time metric
+const (
+	limiterRate     = "rate"
+	limiterInFlight = "in_flight"
+)
+
+//Limiter attribute names of wait time metric and span events
+const (
+	AttributeEndpointClass = "infinibox.endpoint.class"
+	AttributeLimiter       = "infinibox.limiter"
+)
+
+//endpointClass returns class of request with provided method and endpoint template
+func endpointClass(method string, template string) EndpointClass {
+	switch {
+	case method == http.MethodGet || method == http.MethodHead:
+		return EndpointClassRead
+	case strings.HasSuffix(template, "/luns") || strings.Contains(template, "/luns/"):
+		return EndpointClassMapping
+	default:
+		return EndpointClassWrite
+	}
+}
+
+//limitingTransport delays http attempts of RestClient until token bucket and
+//endpoint class in-flight caps allow them, waiting is aborted with request context
+type limitingTransport struct {
+	base      http.RoundTripper
+	limiter   *rate.Limiter
+	inFlight  map[EndpointClass]chan struct{}
+	telemetry *telemetry
+}
+
+//newLimitingTransport wraps base with limits from config, base is returned when no limit is configured
+func newLimitingTransport(base http.RoundTripper, config *Config, telemetry *telemetry) http.RoundTripper {
+
+	if config.RateLimit <= 0 && len(config.MaxInFlight) == 0 {
+		return base
+	}
+
+	t := &limitingTransport{base: base, inFlight: map[EndpointClass]chan struct{}{}, telemetry: telemetry}
+
+	if config.RateLimit > 0 {
+		burst := config.RateBurst
+		if burst < 1 {
+			burst = 1
+		}
+		t.limiter = rate.NewLimiter(rate.Limit(config.RateLimit), burst)
+	}
+
+	for class, max := range config.MaxInFlight {
+		if max > 0 {
+			t.inFlight[class] = make(chan struct{}, max)
+		}
+	}
+
+	return t
+}
+
+func (t *limitingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+
+	ctx := req.Context()
+	class := endpointClass(req.Method, parseEndpoint(req.URL.String()).template)
+
+	if t.limiter != nil {
+		start := time.Now()
+		if err := t.limiter.Wait(ctx); err != nil {
+			closeRequestBody(req)
+			return nil, fmt.Errorf("waiting for rate limiter of %s %s aborted, %w", req.Method, req.URL.Path, err)
+		}
+		t.recordWait(ctx, limiterRate, class, time.Since(start))
+	}
+
+	if slots, ok := t.inFlight[class]; ok {
+		start := time.Now()
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			closeRequestBody(req)
+			return nil, fmt.Errorf("waiting for %s in-flight slot of %s %s aborted, %w", class, req.Method, req.URL.Path, ctx.Err())
+		}
+		t.recordWait(ctx, limiterInFlight, class, time.Since(start))
+
+		release := func() { <-slots }
+		resp, err := t.base.RoundTrip(req)
+		if err != nil || resp.Body == nil {
+			release()
+			return resp, err
+		}
+		//slot is held until response body is read and closed, not only until headers arrive
+		resp.Body = &releasingBody{ReadCloser: resp.Body, release: release}
+		return resp, nil
+	}
+
+	return t.base.RoundTrip(req)
+}
+
+//releasingBody releases in-flight slot once when response body is closed
+type releasingBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
+
+//recordWait records limiter wait time metric and adds wait event to caller span
+func (t *limitingTransport) recordWait(ctx context.Context, limiter string, class EndpointClass, wait time.Duration) {
+
+	attrs := []attribute.KeyValue{
+		attribute.String(AttributeLimiter, limiter),
+		attribute.String(AttributeEndpointClass, string(class)),
+	}
+	t.telemetry.limiterWait.Record(ctx, wait.Seconds(), metric.WithAttributes(attrs...))
+
+	if wait > 0 {
+		trace.SpanFromContext(ctx).AddEvent("infinibox.limiter.wait",
+			trace.WithAttributes(append(attrs, attribute.Float64("infinibox.limiter.wait_seconds", wait.Seconds()))...))
+	}
+}
+
+//closeRequestBody closes body of request which is not sent
+func closeRequestBody(req *http.Request) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+}
@@ -0,0 +1,78 @@
+package infinibox
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+)
+
+//failingTransport fails every round trip
+type failingTransport struct{}
+
+func (failingTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestLimitingTransportHoldsSlotUntilBodyClosed(t *testing.T) {
+
+	ibox := newTestIBOX(t)
+	ibox.handle(http.MethodGet, "/api/rest/volumes", func(w http.ResponseWriter, r *http.Request) {
+		writeResult(w, []Volume{})
+	})
+
+	config := &Config{MaxInFlight: map[EndpointClass]int{EndpointClassRead: 1}}
+	transport := newLimitingTransport(http.DefaultTransport, config, newTelemetry(config))
+
+	get := func(ctx context.Context) (*http.Response, error) {
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, ibox.server.URL+"/api/rest/volumes", nil)
+		return transport.RoundTrip(req)
+	}
+
+	first, err := get(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//headers of first response arrived but its body is open, slot must still be taken
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := get(ctx); err == nil {
+		t.Fatal("second request sent while first response body is open")
+	}
+
+	io.Copy(io.Discard, first.Body)
+	first.Body.Close()
+	first.Body.Close()
+
+	second, err := get(context.Background())
+	if err != nil {
+		t.Fatalf("second request after first body closed, %s", err.Error())
+	}
+	second.Body.Close()
+
+	//closing body twice releases slot once, third request must wait for second one only
+	third, err := get(context.Background())
+	if err != nil {
+		t.Fatalf("third request after second body closed, %s", err.Error())
+	}
+	third.Body.Close()
+}
+
+func TestLimitingTransportReleasesSlotOnError(t *testing.T) {
+
+	config := &Config{MaxInFlight: map[EndpointClass]int{EndpointClassWrite: 1}}
+	transport := newLimitingTransport(failingTransport{}, config, newTelemetry(config))
+
+	for i := 0; i < 3; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		req, _ := http.NewRequestWithContext(ctx, http.MethodPost, "http://ibox/api/rest/volumes", nil)
+		_, err := transport.RoundTrip(req)
+		cancel()
+		if err == nil || errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("request %d: error %v, want transport error", i, err)
+		}
+	}
+}
@@ -0,0 +1,248 @@
+package infinibox
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-resty/resty"
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+	"log/slog"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//Structured log field names attached to client log records
+const (
+	LogFieldOperation  = "operation"
+	LogFieldObjectType = "object_type"
+	LogFieldObjectID   = "object_id"
+	LogFieldTenant     = "tenant"
+	LogFieldRequestID  = "request_id"
+	LogFieldDuration   = "duration"
+	LogFieldStatus     = "status"
+)
+
+//RequestIDHeader carries request id generated for every management api call
+const RequestIDHeader = "X-Request-ID"
+
+//redactedValue replaces secrets in log output
+const redactedValue = "[REDACTED]"
+
+//Fields represents structured log record fields
+type Fields map[string]interface{}
+
+//Logger is implemented by structured loggers receiving client log records
+type Logger interface {
+	Debug(msg string, fields Fields)
+	Info(msg string, fields Fields)
+	Warn(msg string, fields Fields)
+	Error(msg string, fields Fields)
+}
+
+//logrusLogger adapts logrus field logger to Logger
+type logrusLogger struct {
+	logger log.FieldLogger
+}
+
+//NewLogrusLogger returns Logger writing records to provided logrus logger
+func NewLogrusLogger(logger log.FieldLogger) Logger {
+	return &logrusLogger{logger: logger}
+}
+
+func (l *logrusLogger) Debug(msg string, fields Fields) {
+	l.logger.WithFields(log.Fields(fields)).Debug(msg)
+}
+
+func (l *logrusLogger) Info(msg string, fields Fields) {
+	l.logger.WithFields(log.Fields(fields)).Info(msg)
+}
+
+func (l *logrusLogger) Warn(msg string, fields Fields) {
+	l.logger.WithFields(log.Fields(fields)).Warn(msg)
+}
+
+func (l *logrusLogger) Error(msg string, fields Fields) {
+	l.logger.WithFields(log.Fields(fields)).Error(msg)
+}
+
+//slogLogger adapts log/slog logger to Logger
+type slogLogger struct {
+	logger *slog.Logger
+}
+
+//NewSlogLogger returns Logger writing records to provided slog logger
+func NewSlogLogger(logger *slog.Logger) Logger {
+	return &slogLogger{logger: logger}
+}
+
+func (l *slogLogger) Debug(msg string, fields Fields) {
+	l.log(slog.LevelDebug, msg, fields)
+}
+
+func (l *slogLogger) Info(msg string, fields Fields) {
+	l.log(slog.LevelInfo, msg, fields)
+}
+
+func (l *slogLogger) Warn(msg string, fields Fields) {
+	l.log(slog.LevelWarn, msg, fields)
+}
+
+func (l *slogLogger) Error(msg string, fields Fields) {
+	l.log(slog.LevelError, msg, fields)
+}
+
+func (l *slogLogger) log(level slog.Level, msg string, fields Fields) {
+	attrs := make([]slog.Attr, 0, len(fields))
+	for _, key := range fields.keys() {
+		attrs = append(attrs, slog.Any(key, fields[key]))
+	}
+	l.logger.LogAttrs(context.Background(), level, msg, attrs...)
+}
+
+//keys returns field names in stable order
+func (f Fields) keys() []string {
+	keys := make([]string, 0, len(f))
+	for key := range f {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+//redactPatterns match passwords, CHAP secrets and session cookies in json bodies, go formatted structs and http header dumps,
+//secret keys are listed exactly so flags such as security_chap_has_inbound_secret are kept
+var redactPatterns = []struct {
+	pattern     *regexp.Regexp
+	replacement string
+}{
+	{regexp.MustCompile(`"(password|security_chap_inbound_secret|security_chap_outbound_secret)"(\s*):(\s*)"(?:[^"\\]|\\.)*"`), `"$1"$2:$3"` + redactedValue + `"`},
+	{regexp.MustCompile(`\b(Password|SecurityChapInboundSecret|SecurityChapOutboundSecret|InboundSecret|OutboundSecret):(\s*)(?:"(?:[^"\\]|\\.)*"|[^\s,}]*)`), `$1:$2"` + redactedValue + `"`},
+	{regexp.MustCompile(`(?im)^(\s*(?:set-)?cookie|\s*authorization)(\s*:\s*).*$`), `$1$2` + redactedValue},
+}
+
+//redact masks secrets in log message
+func redact(msg string) string {
+	for _, r := range redactPatterns {
+		msg = r.pattern.ReplaceAllString(msg, r.replacement)
+	}
+	return msg
+}
+
+//redactingWriter forwards redacted resty debug dumps to client logger
+type redactingWriter struct {
+	client *Client
+}
+
+func (w *redactingWriter) Write(p []byte) (int, error) {
+	w.client.Logger().Debug(redact(strings.TrimRight(string(p), "\n")), Fields{})
+	return len(p), nil
+}
+
+//defaultLogger keeps logging to logrus standard logger when no logger is configured
+var defaultLogger = NewLogrusLogger(log.StandardLogger())
+
+//Logger returns logger receiving client log records
+func (c *Client) Logger() Logger {
+	if c.logger == nil {
+		return defaultLogger
+	}
+	return c.logger
+}
+
+//logFields returns fields attached to every client log record
+func (c *Client) logFields() Fields {
+	fields := Fields{}
+	if c.config.tenant != "" {
+		fields[LogFieldTenant] = c.config.tenant
+	}
+	return fields
+}
+
+func (c *Client) debugf(format string, args ...interface{}) {
+	c.Logger().Debug(redact(fmt.Sprintf(format, args...)), c.logFields())
+}
+
+func (c *Client) infof(format string, args ...interface{}) {
+	c.Logger().Info(redact(fmt.Sprintf(format, args...)), c.logFields())
+}
+
+func (c *Client) warnf(format string, args ...interface{}) {
+	c.Logger().Warn(redact(fmt.Sprintf(format, args...)), c.logFields())
+}
+
+func (c *Client) errorf(format string, args ...interface{}) {
+	c.Logger().Error(redact(fmt.Sprintf(format, args...)), c.logFields())
+}
+
+//endpoint describes management api call for log records and spans
+type endpoint struct {
+	path       string
+	template   string
+	objectType string
+	objectID   string
+}
+
+//parseEndpoint splits request url into endpoint template, object type and object id
+func parseEndpoint(rawURL string) endpoint {
+
+	path := rawURL
+	if parsed, err := url.Parse(rawURL); err == nil {
+		path = parsed.Path
+	}
+	path = strings.TrimPrefix(strings.Trim(path, "/"), "api/rest/")
+
+	e := endpoint{path: path}
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if _, err := strconv.ParseInt(segment, 10, 64); err == nil {
+			if i == 1 {
+				e.objectID = segment
+			}
+			segments[i] = "{id}"
+		}
+	}
+	e.objectType = segments[0]
+	e.template = "api/rest/" + strings.Join(segments, "/")
+
+	return e
+}
+
+//logRequest tags request with request id
+func (c *Client) logRequest(_ *resty.Client, r *resty.Request) error {
+	if r.Header.Get(RequestIDHeader) == "" {
+		r.SetHeader(RequestIDHeader, uuid.New().String())
+	}
+	return nil
+}
+
+//logResponse writes structured record for completed management api call
+func (c *Client) logResponse(_ *resty.Client, r *resty.Response) error {
+
+	e := parseEndpoint(r.Request.URL)
+
+	fields := Fields{
+		LogFieldOperation:  r.Request.Method + " " + e.template,
+		LogFieldObjectType: e.objectType,
+		LogFieldRequestID:  r.Request.Header.Get(RequestIDHeader),
+		LogFieldDuration:   r.Time(),
+		LogFieldStatus:     r.StatusCode(),
+	}
+	if e.objectID != "" {
+		fields[LogFieldObjectID] = e.objectID
+	}
+	if tenant := r.Request.Header.Get("X-INFINIDAT-TENANT-ID"); tenant != "" {
+		fields[LogFieldTenant] = tenant
+	}
+
+	msg := fmt.Sprintf("%s %s %s", r.Request.Method, r.Request.URL, r.Status())
+	if r.StatusCode() >= 500 {
+		c.Logger().Warn(msg, fields)
+	} else {
+		c.Logger().Debug(msg, fields)
+	}
+
+	return nil
+}
@@ -0,0 +1,87 @@
+package infinibox
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+
+	host := Host{
+		ID:                            7,
+		Name:                          "node-1",
+		SecurityMethod:                SecurityMethodChap,
+		SecurityChapInboundUsername:   "node-1",
+		SecurityChapInboundSecret:     "inbound-s3cret",
+		SecurityChapOutboundSecret:    "outbound-s3cret",
+		SecurityChapHasInboundSecret:  true,
+		SecurityChapHasOutboundSecret: true,
+	}
+	hostJSON, _ := json.Marshal(host)
+	credentials := ChapCredentials{InboundUsername: "node-1", InboundSecret: "inbound-s3cret", OutboundSecret: "outbound-s3cret"}
+
+	tests := []struct {
+		name    string
+		msg     string
+		secrets []string
+		kept    []string
+	}{
+		{
+			name:    "json body",
+			msg:     string(hostJSON),
+			secrets: []string{"inbound-s3cret", "outbound-s3cret"},
+			kept:    []string{`"security_chap_has_inbound_secret":true`, `"security_chap_has_outbound_secret":true`, `"security_chap_inbound_username":"node-1"`},
+		},
+		{
+			name:    "json login body with spacing",
+			msg:     `{"username": "admin", "password" : "pa\"ss"}`,
+			secrets: []string{`pa\"ss`},
+			kept:    []string{`"username": "admin"`},
+		},
+		{
+			name:    "go syntax struct",
+			msg:     fmt.Sprintf("%#v", host),
+			secrets: []string{"inbound-s3cret", "outbound-s3cret"},
+			kept:    []string{"SecurityChapHasInboundSecret:true", "SecurityChapHasOutboundSecret:true", `SecurityChapInboundUsername:"node-1"`},
+		},
+		{
+			name:    "go struct with field names",
+			msg:     fmt.Sprintf("%+v", credentials),
+			secrets: []string{"inbound-s3cret", "outbound-s3cret"},
+			kept:    []string{"InboundUsername:node-1"},
+		},
+		{
+			name:    "config with password",
+			msg:     fmt.Sprintf("%+v", Config{Username: "admin", Password: "adm1n"}),
+			secrets: []string{"adm1n"},
+			kept:    []string{"Username:admin"},
+		},
+		{
+			name:    "http header dump",
+			msg:     "POST /api/rest/users/login HTTP/1.1\nAuthorization: Basic YWRtaW46YWRtMW4=\nCookie: JSESSIONID=abc123\nContent-Type: application/json\nSet-Cookie: JSESSIONID=abc123; Path=/",
+			secrets: []string{"YWRtaW46YWRtMW4=", "abc123"},
+			kept:    []string{"Content-Type: application/json", "POST /api/rest/users/login"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			redacted := redact(tt.msg)
+			for _, secret := range tt.secrets {
+				if strings.Contains(redacted, secret) {
+					t.Errorf("secret %s left in %s", secret, redacted)
+				}
+			}
+			for _, kept := range tt.kept {
+				if !strings.Contains(redacted, kept) {
+					t.Errorf("%s redacted from %s", kept, redacted)
+				}
+			}
+			if !strings.Contains(redacted, redactedValue) {
+				t.Errorf("nothing redacted in %s", redacted)
+			}
+		})
+	}
+}
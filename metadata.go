@@ -3,26 +3,37 @@ package infinibox
 import (
 	"encoding/json"
 	"fmt"
-	log "github.com/sirupsen/logrus"
+	"strconv"
+)
+
+//Metadata object types reported by IBOX
+const (
+	MetadataObjectVolume     = "volume"
+	MetadataObjectSnapshot   = "snapshot"
+	MetadataObjectFilesystem = "filesystem"
+	MetadataObjectPool       = "pool"
+	MetadataObjectHost       = "host"
+	MetadataObjectCluster    = "cluster"
 )
 
 type Metadata struct {
-	Key      string      `json:"key"`
-	Value    interface{} `json:"value"`
-	ID       int64       `json:"id"`
-	ObjectID int64       `json:"object_id"`
+	Key        string      `json:"key"`
+	Value      interface{} `json:"value"`
+	ID         int64       `json:"id"`
+	ObjectID   int64       `json:"object_id"`
+	ObjectType string      `json:"object_type,omitempty"`
 }
 
 func (c *Client) GetAllMetadata() (*[]Metadata, error) {
 
-	log.Debugf("Getting all metadata")
+	c.debugf("Getting all metadata")
 
 	url := "api/rest/metadata/"
-	response, err := c.RestClient.R().Get(url)
+	response, err := c.request().Get(url)
 
 	result, err := CheckAPIResponse(response, err)
 	if err != nil {
-		return nil, fmt.Errorf(fmt.Sprintf("error getting all metadata, %s", err.Error()))
+		return nil, fmt.Errorf("error getting all metadata, %s", err.Error())
 	}
 
 	num := result.APIMetadata["number_of_objects"]
@@ -30,14 +41,14 @@ func (c *Client) GetAllMetadata() (*[]Metadata, error) {
 		return nil, fmt.Errorf("cannot parse metadata for number_of_objects field")
 	}
 	if num == float64(0) {
-		log.Debugln("metadata is empty")
+		c.debugf("metadata is empty")
 		return nil, nil
 	}
 
 	var allMetadata []Metadata
 	err = json.Unmarshal(*result.APIResult, &allMetadata)
 	if err != nil {
-		return nil, fmt.Errorf(fmt.Sprintf("error getting all metadata, %s", err.Error()))
+		return nil, fmt.Errorf("error getting all metadata, %s", err.Error())
 	}
 
 	return &allMetadata, nil
@@ -45,14 +56,14 @@ func (c *Client) GetAllMetadata() (*[]Metadata, error) {
 
 func (c *Client) GetMetadataByObject(objectID int64) (*[]Metadata, error) {
 
-	log.Debugf("Getting metadata by objectID %s", objectID)
+	c.debugf("Getting metadata by objectID %d", objectID)
 
 	url := fmt.Sprintf("api/rest/metadata/%d", objectID)
-	response, err := c.RestClient.R().Get(url)
+	response, err := c.request().Get(url)
 
 	result, err := CheckAPIResponse(response, err)
 	if err != nil {
-		return nil, fmt.Errorf(fmt.Sprintf("Getting metadata by objectID %d, %s", objectID, err.Error()))
+		return nil, fmt.Errorf("Getting metadata by objectID %d, %s", objectID, err.Error())
 	}
 
 	num := result.APIMetadata["number_of_objects"]
@@ -60,14 +71,14 @@ func (c *Client) GetMetadataByObject(objectID int64) (*[]Metadata, error) {
 		return nil, fmt.Errorf("cannot parse metadata for number_of_objects field")
 	}
 	if num == float64(0) {
-		log.Infof("metadata is empty")
+		c.infof("metadata is empty")
 		return nil, nil
 	}
 
 	var objectMetadata []Metadata
 	err = json.Unmarshal(*result.APIResult, &objectMetadata)
 	if err != nil {
-		return nil, fmt.Errorf(fmt.Sprintf("Getting metadata by objectID %d, %s", objectID, err.Error()))
+		return nil, fmt.Errorf("Getting metadata by objectID %d, %s", objectID, err.Error())
 	}
 
 	return &objectMetadata, nil
@@ -75,10 +86,10 @@ func (c *Client) GetMetadataByObject(objectID int64) (*[]Metadata, error) {
 
 func (c *Client) GetMetadataByObjectAndKey(objectID int64, key string) (*Metadata, error) {
 
-	log.Debugf("Getting metadata by objectID %s and key %s", objectID, key)
+	c.debugf("Getting metadata by objectID %d and key %s", objectID, key)
 
 	url := fmt.Sprintf("api/rest/metadata/%d/%s", objectID, key)
-	response, err := c.RestClient.R().Get(url)
+	response, err := c.request().Get(url)
 
 	result, err := CheckAPIResponse(response, err)
 	if err != nil {
@@ -88,7 +99,7 @@ func (c *Client) GetMetadataByObjectAndKey(objectID int64, key string) (*Metadat
 	var objectMetadata Metadata
 	err = json.Unmarshal(*result.APIResult, &objectMetadata)
 	if err != nil {
-		return nil, fmt.Errorf(fmt.Sprintf("Getting metadata by objectID %d and key %s, %s", objectID, key, err.Error()))
+		return nil, fmt.Errorf("Getting metadata by objectID %d and key %s, %s", objectID, key, err.Error())
 	}
 
 	return &objectMetadata, nil
@@ -96,67 +107,266 @@ func (c *Client) GetMetadataByObjectAndKey(objectID int64, key string) (*Metadat
 
 func (c *Client) AddMetadata(metadata *Metadata) error {
 
-	log.Debugf("Adding metadata for objectID %s", metadata.ObjectID)
+	c.debugf("Adding metadata for objectID %d", metadata.ObjectID)
 
 	url := fmt.Sprintf("api/rest/metadata/%d", metadata.ObjectID)
 	body := map[string]interface{}{metadata.Key: metadata.Value}
-	response, err := c.RestClient.R().SetBody(body).Put(url)
+	response, err := c.request().SetBody(body).Put(url)
 
 	result, err := CheckAPIResponse(response, err)
 	if err != nil {
-		return fmt.Errorf(fmt.Sprintf("Adding metadata for objectID %d failed, %s", metadata.ObjectID, err.Error()))
+		return fmt.Errorf("Adding metadata for objectID %d failed, %s", metadata.ObjectID, err.Error())
 	}
 
 	var objectMetadata []Metadata
 	err = json.Unmarshal(*result.APIResult, &objectMetadata)
 	if err != nil {
-		return fmt.Errorf(fmt.Sprintf("Adding metadata for objectID %d failed, %s", metadata.ObjectID, err.Error()))
+		return fmt.Errorf("Adding metadata for objectID %d failed, %s", metadata.ObjectID, err.Error())
 	}
 
-	log.Debugf("Added metadata: %v to objectID %s", metadata.Value, metadata.ObjectID)
+	c.debugf("Added metadata: %v to objectID %d", metadata.Value, metadata.ObjectID)
 	return nil
 }
 
 func (c *Client) DeleteMetadata(objectID int64) error {
 
-	log.Debugf("Deleting metadata for objectID %d", objectID)
+	c.debugf("Deleting metadata for objectID %d", objectID)
 
 	url := fmt.Sprintf("api/rest/metadata/%d", objectID)
-	response, err := c.RestClient.R().Delete(url)
+	response, err := c.request().Delete(url)
 
 	result, err := CheckAPIResponse(response, err)
 	if err != nil {
-		return fmt.Errorf(fmt.Sprintf("Deleting metadata for objectID %d failed, %s", objectID, err.Error()))
+		return fmt.Errorf("Deleting metadata for objectID %d failed, %s", objectID, err.Error())
 	}
 
 	var objectMetadata []Metadata
 	err = json.Unmarshal(*result.APIResult, &objectMetadata)
 	if err != nil {
-		return fmt.Errorf(fmt.Sprintf("Deleting metadata for objectID %d failed, %s", objectID, err.Error()))
+		return fmt.Errorf("Deleting metadata for objectID %d failed, %s", objectID, err.Error())
 	}
 
-	log.Debugf("Deleted metadata: for objectID %s", objectID)
+	c.debugf("Deleted metadata: for objectID %d", objectID)
 	return nil
 }
 
 func (c *Client) DeleteMetadataByKey(objectID int64, key string) error {
 
-	log.Debugf("Deleting metadata for objectID %s and key %s", objectID, key)
+	c.debugf("Deleting metadata for objectID %d and key %s", objectID, key)
 
 	url := fmt.Sprintf("api/rest/metadata/%d/%s", objectID, key)
-	response, err := c.RestClient.R().Delete(url)
+	response, err := c.request().Delete(url)
 
 	result, err := CheckAPIResponse(response, err)
 	if err != nil {
-		return fmt.Errorf(fmt.Sprintf("Deleting metadata for objectID %d and key %s failed, %s", objectID, key, err.Error()))
+		return fmt.Errorf("Deleting metadata for objectID %d and key %s failed, %s", objectID, key, err.Error())
 	}
 
 	var objectMetadata []Metadata
 	err = json.Unmarshal(*result.APIResult, &objectMetadata)
 	if err != nil {
-		return fmt.Errorf(fmt.Sprintf("Deleting metadata for objectID %d and key %s failed, %s", objectID, key, err.Error()))
+		return fmt.Errorf("Deleting metadata for objectID %d and key %s failed, %s", objectID, key, err.Error())
 	}
 
-	log.Debugf("Deleted metadata: for objectID %s and key %s", objectID, key)
+	c.debugf("Deleted metadata: for objectID %d and key %s", objectID, key)
 	return nil
 }
+
+//AddMetadataMap sets all provided keys for objectID in single request
+func (c *Client) AddMetadataMap(objectID int64, values map[string]interface{}) error {
+
+	c.debugf("Adding %d metadata keys for objectID %d", len(values), objectID)
+
+	if len(values) == 0 {
+		return nil
+	}
+
+	body := map[string]interface{}{}
+	for key, value := range values {
+		encoded, err := encodeMetadataValue(value)
+		if err != nil {
+			return fmt.Errorf("Adding metadata key %s for objectID %d failed, %s", key, objectID, err.Error())
+		}
+		body[key] = encoded
+	}
+
+	url := fmt.Sprintf("api/rest/metadata/%d", objectID)
+	response, err := c.request().SetBody(body).Put(url)
+
+	result, err := CheckAPIResponse(response, err)
+	if err != nil {
+		return fmt.Errorf("Adding metadata for objectID %d failed, %s", objectID, err.Error())
+	}
+
+	var objectMetadata []Metadata
+	err = json.Unmarshal(*result.APIResult, &objectMetadata)
+	if err != nil {
+		return fmt.Errorf("Adding metadata for objectID %d failed, %s", objectID, err.Error())
+	}
+
+	c.debugf("Added %d metadata keys to objectID %d", len(values), objectID)
+	return nil
+}
+
+//FindObjectsByMetadata returns metadata entries with key set to value, objectType limits
+//results to provided object type when not empty, entries are filtered by IBOX and read page by page
+func (c *Client) FindObjectsByMetadata(objectType string, key string, value string) (*[]Metadata, error) {
+
+	c.debugf("Finding %s objects with metadata %s=%s", objectType, key, value)
+
+	filters := map[string]string{"key": "eq:" + key, "value": "eq:" + value}
+	if objectType != "" {
+		filters["object_type"] = "eq:" + objectType
+	}
+
+	matches, err := getAllPages[Metadata](c, "api/rest/metadata", filters)
+	if err != nil {
+		return nil, fmt.Errorf("Finding objects by metadata %s=%s failed, %s", key, value, err.Error())
+	}
+
+	c.debugf("Found %d objects with metadata %s=%s", len(matches), key, value)
+	return &matches, nil
+}
+
+//encodeMetadataValue converts value to string representation stored by IBOX,
+//values other than strings, numbers and bools are stored JSON encoded
+func encodeMetadataValue(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case int:
+		return strconv.Itoa(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case uint64:
+		return strconv.FormatUint(v, 10), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	}
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
+
+//decodeMetadataValue converts stored metadata value into target pointer
+func decodeMetadataValue(value interface{}, target interface{}) (err error) {
+
+	raw, ok := value.(string)
+	if !ok {
+		raw = fmt.Sprint(value)
+	}
+
+	switch t := target.(type) {
+	case *string:
+		*t = raw
+	case *int:
+		*t, err = strconv.Atoi(raw)
+	case *int64:
+		*t, err = strconv.ParseInt(raw, 10, 64)
+	case *uint64:
+		*t, err = strconv.ParseUint(raw, 10, 64)
+	case *bool:
+		*t, err = strconv.ParseBool(raw)
+	default:
+		err = json.Unmarshal([]byte(raw), target)
+	}
+
+	return err
+}
+
+//GetMetadataAs returns objectID metadata key value decoded as T
+func GetMetadataAs[T any](client *Client, objectID int64, key string) (value T, err error) {
+
+	metadata, err := client.GetMetadataByObjectAndKey(objectID, key)
+	if err != nil {
+		return value, fmt.Errorf("Getting metadata by objectID %d and key %s, %s", objectID, key, err.Error())
+	}
+
+	err = decodeMetadataValue(metadata.Value, &value)
+	if err != nil {
+		return value, fmt.Errorf("Decoding metadata by objectID %d and key %s, %s", objectID, key, err.Error())
+	}
+
+	return value, nil
+}
+
+//SetMetadataAs stores value of type T as objectID metadata key
+func SetMetadataAs[T any](client *Client, objectID int64, key string, value T) error {
+	return client.AddMetadataMap(objectID, map[string]interface{}{key: value})
+}
+
+//GetMetadataString returns objectID metadata key value as string
+func (c *Client) GetMetadataString(objectID int64, key string) (string, error) {
+	return GetMetadataAs[string](c, objectID, key)
+}
+
+//GetMetadataInt returns objectID metadata key value as int64
+func (c *Client) GetMetadataInt(objectID int64, key string) (int64, error) {
+	return GetMetadataAs[int64](c, objectID, key)
+}
+
+//GetMetadataBool returns objectID metadata key value as bool
+func (c *Client) GetMetadataBool(objectID int64, key string) (bool, error) {
+	return GetMetadataAs[bool](c, objectID, key)
+}
+
+//GetMetadataJSON decodes JSON encoded objectID metadata key value into target
+func (c *Client) GetMetadataJSON(objectID int64, key string, target interface{}) error {
+
+	metadata, err := c.GetMetadataByObjectAndKey(objectID, key)
+	if err != nil {
+		return fmt.Errorf("Getting metadata by objectID %d and key %s, %s", objectID, key, err.Error())
+	}
+
+	err = decodeMetadataValue(metadata.Value, target)
+	if err != nil {
+		return fmt.Errorf("Decoding metadata by objectID %d and key %s, %s", objectID, key, err.Error())
+	}
+
+	return nil
+}
+
+//SetMetadataString stores string objectID metadata key value
+func (c *Client) SetMetadataString(objectID int64, key string, value string) error {
+	return SetMetadataAs(c, objectID, key, value)
+}
+
+//SetMetadataInt stores int64 objectID metadata key value
+func (c *Client) SetMetadataInt(objectID int64, key string, value int64) error {
+	return SetMetadataAs(c, objectID, key, value)
+}
+
+//SetMetadataBool stores bool objectID metadata key value
+func (c *Client) SetMetadataBool(objectID int64, key string, value bool) error {
+	return SetMetadataAs(c, objectID, key, value)
+}
+
+//SetMetadataJSON stores JSON encoded value as objectID metadata key
+func (c *Client) SetMetadataJSON(objectID int64, key string, value interface{}) error {
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("Encoding metadata for objectID %d and key %s, %s", objectID, key, err.Error())
+	}
+
+	return c.AddMetadataMap(objectID, map[string]interface{}{key: string(encoded)})
+}
+
+//filterMetadataByKey returns metadata entries matching key, all entries for empty key
+func filterMetadataByKey(metadata *[]Metadata, key string) *[]Metadata {
+	if metadata == nil || key == "" {
+		return metadata
+	}
+
+	filtered := []Metadata{}
+	for _, entry := range *metadata {
+		if entry.Key == key {
+			filtered = append(filtered, entry)
+		}
+	}
+
+	return &filtered
+}
@@ -0,0 +1,238 @@
+package infinibox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+//Metrics entities collectors can be filtered on
+const (
+	MetricsEntitySystem = ""
+	MetricsEntityVolume = "vol_id"
+	MetricsEntityPool   = "pool_id"
+	MetricsEntityHost   = "host_id"
+)
+
+//DefaultMetricsFields lists fields collected when none are provided
+var DefaultMetricsFields = []string{
+	"ops",
+	"read_ops",
+	"write_ops",
+	"throughput",
+	"read_throughput",
+	"write_throughput",
+	"average_latency",
+	"read_average_latency",
+	"write_average_latency",
+}
+
+//MetricsCollector represents IBOX performance metrics collector struct
+type MetricsCollector struct {
+	ID              int64                  `json:"id"`
+	Type            string                 `json:"type"`
+	Filters         map[string]interface{} `json:"filters"`
+	CollectedFields []string               `json:"collected_fields"`
+}
+
+//PerformanceSample represents single performance data point
+type PerformanceSample struct {
+	Time            time.Time
+	IOPS            float64
+	ReadIOPS        float64
+	WriteIOPS       float64
+	Throughput      float64
+	ReadThroughput  float64
+	WriteThroughput float64
+	Latency         float64
+	ReadLatency     float64
+	WriteLatency    float64
+	Fields          map[string]float64
+}
+
+//PerformanceSeries represents performance samples of single collector
+type PerformanceSeries struct {
+	CollectorID int64
+	Interval    time.Duration
+	Samples     []PerformanceSample
+}
+
+type metricsCollectorData struct {
+	ID                       int64           `json:"id"`
+	Fields                   []string        `json:"fields"`
+	Data                     [][]interface{} `json:"data"`
+	IntervalMilliseconds     int64           `json:"interval_milliseconds"`
+	EndTimestampMilliseconds int64           `json:"end_timestamp_milliseconds"`
+}
+
+//CreateMetricsCollector creates SAN performance collector for entity, empty entity collects
+//system wide counters, empty fields collect DefaultMetricsFields
+func (c *Client) CreateMetricsCollector(entity string, entityID int64, fields []string) (*MetricsCollector, error) {
+
+	c.debugf("Creating metrics collector for %s %d", entity, entityID)
+
+	if len(fields) == 0 {
+		fields = DefaultMetricsFields
+	}
+
+	filters := map[string]interface{}{"protocol_type": "SAN"}
+	if entity != MetricsEntitySystem {
+		filters[entity] = entityID
+	}
+
+	body := map[string]interface{}{
+		"type":             "COUNTER",
+		"filters":          filters,
+		"collected_fields": fields,
+	}
+
+	url := "api/rest/metrics/collectors"
+	response, err := c.request().SetBody(body).Post(url)
+
+	result, err := CheckAPIResponse(response, err)
+	if err != nil {
+		return nil, fmt.Errorf("error creating metrics collector for %s %d, %s", entity, entityID, err.Error())
+	}
+
+	var collector MetricsCollector
+	err = json.Unmarshal(*result.APIResult, &collector)
+	if err != nil {
+		return nil, fmt.Errorf("error creating metrics collector for %s %d, %s", entity, entityID, err.Error())
+	}
+
+	c.debugf("Created metrics collector ID %d", collector.ID)
+
+	return &collector, nil
+}
+
+//DeleteMetricsCollector deletes performance collector
+func (c *Client) DeleteMetricsCollector(collectorID int64) error {
+
+	c.debugf("Deleting metrics collector ID %d", collectorID)
+
+	url := fmt.Sprintf("api/rest/metrics/collectors/%d", collectorID)
+	response, err := c.request().Delete(url)
+
+	_, err = CheckAPIResponse(response, err)
+	if err != nil {
+		return fmt.Errorf("error deleting metrics collector ID %d, %s", collectorID, err.Error())
+	}
+
+	c.debugf("Deleted metrics collector ID %d", collectorID)
+
+	return nil
+}
+
+//CollectMetrics returns samples gathered by collectors since previous collection
+func (c *Client) CollectMetrics(collectorIDs ...int64) (*[]PerformanceSeries, error) {
+
+	c.debugf("Collecting metrics for collectors %v", collectorIDs)
+
+	ids := []string{}
+	for _, id := range collectorIDs {
+		ids = append(ids, fmt.Sprintf("%d", id))
+	}
+
+	url := "api/rest/metrics/collectors/data"
+	response, err := c.request().SetQueryParam("collector_id", fmt.Sprintf("in:[%s]", strings.Join(ids, ","))).Get(url)
+
+	result, err := CheckAPIResponse(response, err)
+	if err != nil {
+		return nil, fmt.Errorf("error collecting metrics, %s", err.Error())
+	}
+
+	var data struct {
+		Collectors []metricsCollectorData `json:"collectors"`
+	}
+	err = json.Unmarshal(*result.APIResult, &data)
+	if err != nil {
+		return nil, fmt.Errorf("error collecting metrics, %s", err.Error())
+	}
+
+	series := []PerformanceSeries{}
+	for _, collector := range data.Collectors {
+		series = append(series, collector.series())
+	}
+
+	return &series, nil
+}
+
+//series converts collector data rows into typed samples
+func (d *metricsCollectorData) series() PerformanceSeries {
+
+	interval := time.Duration(d.IntervalMilliseconds) * time.Millisecond
+	series := PerformanceSeries{CollectorID: d.ID, Interval: interval}
+
+	for i, row := range d.Data {
+		sample := PerformanceSample{Fields: map[string]float64{}}
+		timestamp := d.EndTimestampMilliseconds - int64(len(d.Data)-1-i)*d.IntervalMilliseconds
+
+		for j, field := range d.Fields {
+			if j >= len(row) {
+				break
+			}
+			value, ok := row[j].(float64)
+			if !ok {
+				continue
+			}
+			if field == "timestamp" {
+				timestamp = int64(value)
+				continue
+			}
+			sample.Fields[field] = value
+		}
+
+		sample.Time = time.Unix(0, timestamp*int64(time.Millisecond))
+		sample.IOPS = sample.Fields["ops"]
+		sample.ReadIOPS = sample.Fields["read_ops"]
+		sample.WriteIOPS = sample.Fields["write_ops"]
+		sample.Throughput = sample.Fields["throughput"]
+		sample.ReadThroughput = sample.Fields["read_throughput"]
+		sample.WriteThroughput = sample.Fields["write_throughput"]
+		sample.Latency = sample.Fields["average_latency"]
+		sample.ReadLatency = sample.Fields["read_average_latency"]
+		sample.WriteLatency = sample.Fields["write_average_latency"]
+
+		series.Samples = append(series.Samples, sample)
+	}
+
+	return series
+}
+
+//SampleMetrics collects collector data every interval and passes samples to emit until
+//ctx is cancelled, collector is deleted on return
+func (c *Client) SampleMetrics(ctx context.Context, collector *MetricsCollector, interval time.Duration, emit func(PerformanceSample)) error {
+
+	defer func() {
+		if err := c.DeleteMetricsCollector(collector.ID); err != nil {
+			c.warnf("unable to delete metrics collector ID %d, %s", collector.ID, err.Error())
+		}
+	}()
+
+	scoped := c.WithContext(ctx)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		series, err := scoped.CollectMetrics(collector.ID)
+		if err != nil {
+			c.errorf("sampling metrics collector ID %d failed, %s", collector.ID, err.Error())
+			continue
+		}
+
+		for _, s := range *series {
+			for _, sample := range s.Samples {
+				emit(sample)
+			}
+		}
+	}
+}
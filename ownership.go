@@ -0,0 +1,252 @@
+package infinibox
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+//Ownership metadata keys stamped on objects created through ownership helpers
+const (
+	OwnerMetadataKey          = "owner"
+	OwnerContextMetadataKey   = "owner_context"
+	OwnerCreatedAtMetadataKey = "owner_created_at"
+)
+
+//ErrNotOwner is returned when object is owned by another owner
+var ErrNotOwner = errors.New("object is owned by another owner")
+
+//Ownership represents owner marker and creation context stamped on object
+type Ownership struct {
+	Owner   string
+	Context string
+}
+
+//OwnedObject represents object carrying ownership metadata
+type OwnedObject struct {
+	ObjectID   int64  `json:"object_id"`
+	ObjectType string `json:"object_type"`
+	Owner      string `json:"owner"`
+	Context    string `json:"context"`
+	CreatedAt  int64  `json:"created_at"`
+}
+
+//OwnerExistsFunc reports whether resource referenced by owned object context still exists
+type OwnerExistsFunc func(object OwnedObject) (bool, error)
+
+//StampOwnership sets ownership metadata for objectID
+func (c *Client) StampOwnership(objectID int64, ownership Ownership) error {
+
+	c.debugf("Stamping objectID %d with owner %s", objectID, ownership.Owner)
+
+	if ownership.Owner == "" {
+		return fmt.Errorf("unable to stamp objectID %d, owner is required", objectID)
+	}
+
+	err := c.AddMetadataMap(objectID, map[string]interface{}{
+		OwnerMetadataKey:          ownership.Owner,
+		OwnerContextMetadataKey:   ownership.Context,
+		OwnerCreatedAtMetadataKey: time.Now().Unix(),
+	})
+	if err != nil {
+		return fmt.Errorf("unable to stamp objectID %d with owner %s, %s", objectID, ownership.Owner, err.Error())
+	}
+
+	return nil
+}
+
+//GetOwnership returns ownership of objectID, nil when object carries no owner marker
+func (c *Client) GetOwnership(objectID int64) (*OwnedObject, error) {
+
+	metadata, err := c.GetMetadataByObject(objectID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get ownership of objectID %d, %s", objectID, err.Error())
+	}
+
+	if metadata == nil {
+		return nil, nil
+	}
+
+	return c.ownedObjectFromMetadata(objectID, *metadata), nil
+}
+
+//ownedObjectFromMetadata builds owned object from object metadata entries
+func (c *Client) ownedObjectFromMetadata(objectID int64, metadata []Metadata) *OwnedObject {
+
+	var owned *OwnedObject
+
+	for _, entry := range metadata {
+		if entry.Key == OwnerMetadataKey {
+			owned = &OwnedObject{ObjectID: objectID, ObjectType: entry.ObjectType, Owner: fmt.Sprint(entry.Value)}
+		}
+	}
+	if owned == nil {
+		return nil
+	}
+
+	for _, entry := range metadata {
+		switch entry.Key {
+		case OwnerContextMetadataKey:
+			owned.Context = fmt.Sprint(entry.Value)
+		case OwnerCreatedAtMetadataKey:
+			if err := decodeMetadataValue(entry.Value, &owned.CreatedAt); err != nil {
+				c.warnf("unable to decode %s of objectID %d, %s", OwnerCreatedAtMetadataKey, objectID, err.Error())
+			}
+		}
+	}
+
+	return owned
+}
+
+//ListOwned returns all objects stamped with owner
+func (c *Client) ListOwned(owner string) (*[]OwnedObject, error) {
+
+	c.debugf("Listing objects owned by %s", owner)
+
+	markers, err := c.FindObjectsByMetadata("", OwnerMetadataKey, owner)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list objects owned by %s, %s", owner, err.Error())
+	}
+
+	owned := []OwnedObject{}
+	for _, marker := range *markers {
+		object, err := c.GetOwnership(marker.ObjectID)
+		if err != nil {
+			return nil, fmt.Errorf("unable to list objects owned by %s, %s", owner, err.Error())
+		}
+		if object == nil {
+			continue
+		}
+		if object.ObjectType == "" {
+			object.ObjectType = marker.ObjectType
+		}
+		owned = append(owned, *object)
+	}
+
+	c.debugf("Found %d objects owned by %s", len(owned), owner)
+
+	return &owned, nil
+}
+
+//FindOrphans returns objects owned by owner whose referenced resource no longer exists
+func (c *Client) FindOrphans(owner string, exists OwnerExistsFunc) (*[]OwnedObject, error) {
+
+	c.debugf("Finding orphans owned by %s", owner)
+
+	owned, err := c.ListOwned(owner)
+	if err != nil {
+		return nil, err
+	}
+
+	orphans := []OwnedObject{}
+	for _, object := range *owned {
+		found, err := exists(object)
+		if err != nil {
+			return nil, fmt.Errorf("unable to check owner of objectID %d, %s", object.ObjectID, err.Error())
+		}
+		if !found {
+			orphans = append(orphans, object)
+		}
+	}
+
+	c.debugf("Found %d orphans owned by %s", len(orphans), owner)
+
+	return &orphans, nil
+}
+
+//CheckOwnership returns ErrNotOwner when objectID is stamped with different owner,
+//objects without owner marker pass the check
+func (c *Client) CheckOwnership(objectID int64, owner string) error {
+
+	object, err := c.GetOwnership(objectID)
+	if err != nil {
+		return err
+	}
+
+	if object != nil && object.Owner != owner {
+		return fmt.Errorf("objectID %d owned by %s, not %s: %w", objectID, object.Owner, owner, ErrNotOwner)
+	}
+
+	return nil
+}
+
+//CreateOwned creates volume and stamps it with ownership
+func (v *Volume) CreateOwned(client *Client, ownership Ownership) (err error) {
+
+	err = v.Create(client)
+	if err != nil {
+		return err
+	}
+
+	err = client.StampOwnership(v.ID, ownership)
+	if err != nil {
+		return fmt.Errorf("volume %s created without ownership, %s", v.Name, err.Error())
+	}
+
+	return nil
+}
+
+//DeleteOwned deletes volume unless it is owned by another owner
+func (v *Volume) DeleteOwned(client *Client, owner string) (err error) {
+
+	err = client.CheckOwnership(v.ID, owner)
+	if err != nil {
+		return fmt.Errorf("refusing to delete volume %s, %w", v.Name, err)
+	}
+
+	return v.Delete(client)
+}
+
+//CreateOwned creates host and stamps it with ownership
+func (h *Host) CreateOwned(client *Client, ownership Ownership) (err error) {
+
+	err = h.Create(client)
+	if err != nil {
+		return err
+	}
+
+	err = client.StampOwnership(h.ID, ownership)
+	if err != nil {
+		return fmt.Errorf("host %s created without ownership, %s", h.Name, err.Error())
+	}
+
+	return nil
+}
+
+//DeleteOwned deletes host unless it is owned by another owner
+func (h *Host) DeleteOwned(client *Client, owner string) (err error) {
+
+	err = client.CheckOwnership(h.ID, owner)
+	if err != nil {
+		return fmt.Errorf("refusing to delete host %s, %w", h.Name, err)
+	}
+
+	return h.Delete(client)
+}
+
+//CreateOwned creates host cluster and stamps it with ownership
+func (hc *HostCluster) CreateOwned(client *Client, ownership Ownership) (err error) {
+
+	err = hc.Create(client)
+	if err != nil {
+		return err
+	}
+
+	err = client.StampOwnership(hc.ID, ownership)
+	if err != nil {
+		return fmt.Errorf("host cluster %s created without ownership, %s", hc.Name, err.Error())
+	}
+
+	return nil
+}
+
+//DeleteOwned deletes host cluster unless it is owned by another owner
+func (hc *HostCluster) DeleteOwned(client *Client, owner string) (err error) {
+
+	err = client.CheckOwnership(hc.ID, owner)
+	if err != nil {
+		return fmt.Errorf("refusing to delete host cluster %s, %w", hc.Name, err)
+	}
+
+	return hc.Delete(client)
+}
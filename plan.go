@@ -0,0 +1,187 @@
+package infinibox
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+//PlanSyntheticIDBase is first id assigned to objects created in dry run mode
+const PlanSyntheticIDBase = 1 << 40
+
+//nonMutatingEndpoints lists endpoints which do not change IBOX configuration despite method
+var nonMutatingEndpoints = map[string]bool{
+	"POST api/rest/users/login": true,
+}
+
+//isMutating returns true for requests changing IBOX configuration
+func isMutating(method string, template string) bool {
+	if method == http.MethodGet || method == http.MethodHead {
+		return false
+	}
+	return !nonMutatingEndpoints[method+" "+template]
+}
+
+//PlanStep represents mutating request recorded in dry run mode, Approved reports whether client
+//approval policy would send request approved
+type PlanStep struct {
+	Method   string          `json:"method"`
+	URL      string          `json:"url"`
+	Endpoint string          `json:"endpoint"`
+	Tenant   string          `json:"tenant,omitempty"`
+	Approved bool            `json:"approved"`
+	Body     json.RawMessage `json:"body,omitempty"`
+	Result   json.RawMessage `json:"result"`
+}
+
+//Plan holds mutating requests recorded in dry run mode in order they were made
+type Plan struct {
+	mu     sync.Mutex
+	steps  []PlanStep
+	nextID int64
+}
+
+//Steps returns recorded plan steps
+func (p *Plan) Steps() []PlanStep {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]PlanStep{}, p.steps...)
+}
+
+//Reset drops recorded plan steps
+func (p *Plan) Reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.steps = nil
+}
+
+//MarshalJSON returns plan steps as JSON array
+func (p *Plan) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.Steps())
+}
+
+//Plan returns plan recorded by client in dry run mode, nil when client sends mutating requests
+func (c *Client) Plan() *Plan {
+	return c.plan
+}
+
+//record appends step and returns synthetic result of request
+func (p *Plan) record(step PlanStep, e endpoint) json.RawMessage {
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	result, _ := json.Marshal(p.syntheticResult(step.Method, e, step.Body))
+	step.Result = result
+	p.steps = append(p.steps, step)
+
+	return result
+}
+
+//syntheticResult builds result letting callers continue as if request succeeded, request body is
+//echoed back, created objects get synthetic ids and metadata endpoints return metadata entries
+func (p *Plan) syntheticResult(method string, e endpoint, body []byte) interface{} {
+
+	var object map[string]interface{}
+	json.Unmarshal(body, &object)
+
+	if e.objectType == "metadata" {
+		objectID, _ := strconv.ParseInt(e.objectID, 10, 64)
+		entries := []Metadata{}
+		if method == http.MethodPut {
+			for key, value := range object {
+				entries = append(entries, Metadata{Key: key, Value: value, ObjectID: objectID})
+			}
+		}
+		return entries
+	}
+
+	if object == nil {
+		object = map[string]interface{}{}
+	}
+	if _, ok := object["id"]; !ok {
+		if method == http.MethodPost {
+			object["id"] = PlanSyntheticIDBase + p.nextID
+			p.nextID++
+		} else if id := lastNumericSegment(e.path); id != "" {
+			object["id"], _ = strconv.ParseInt(id, 10, 64)
+		}
+	}
+
+	return object
+}
+
+//lastNumericSegment returns last numeric segment of url path
+func lastNumericSegment(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i := len(segments) - 1; i >= 0; i-- {
+		if _, err := strconv.ParseInt(segments[i], 10, 64); err == nil {
+			return segments[i]
+		}
+	}
+	return ""
+}
+
+//planTransport records mutating requests of RestClient into plan and answers them with
+//synthetic results, GET requests are sent to IBOX
+type planTransport struct {
+	base http.RoundTripper
+	plan *Plan
+}
+
+func (t *planTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+
+	e := parseEndpoint(req.URL.String())
+
+	if !isMutating(req.Method, e.template) {
+		return t.base.RoundTrip(req)
+	}
+
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	step := PlanStep{
+		Method:   req.Method,
+		URL:      req.URL.String(),
+		Endpoint: e.template,
+		Tenant:   req.Header.Get("X-INFINIDAT-TENANT-ID"),
+		Approved: req.URL.Query().Get("approved") == "true",
+	}
+	if len(bytes.TrimSpace(body)) > 0 {
+		step.Body = json.RawMessage(body)
+	}
+
+	result := t.plan.record(step, e)
+
+	envelope, err := json.Marshal(map[string]interface{}{
+		"result":   result,
+		"error":    nil,
+		"metadata": map[string]interface{}{"ready": true},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(envelope)),
+		ContentLength: int64(len(envelope)),
+		Request:       req,
+	}, nil
+}
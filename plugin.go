@@ -3,171 +3,337 @@ package infinibox
 import (
 	"encoding/json"
 	"fmt"
-	log "github.com/sirupsen/logrus"
+	"net/http"
 )
 
 type Plugin struct {
-	ID                     int64  `json:"id"`
-	Type                   string `json:"type"`
-	Name                   string `json:"name"`
-	Version                string `json:"version"`
-	APIRedirectSuffix      string `json:"api_redirect_suffix"`
-	ManagementURL          string `json:"management_url"`
-	MaxSecWithoutHeartbeat int    `json:"max_sec_without_heartbeat"`
-	CreatedAt              int64  `json:"created_at"`
-	UpdatedAt              int64  `json:"updated_at"`
-	TenantID               int64  `json:"tenant_id"`
-	Capacity               struct {
-		TotalPhysicalCapacity  int64 `json:"total_physical_capacity"`
-		AllocatedPhysicalSpace int64 `json:"allocated_physical_space"`
-		TotalVirtualCapacity   int64 `json:"total_virtual_capacity"`
-		AllocatedVirtualSpace  int64 `json:"allocated_virtual_space"`
-	} `json:"capacity"`
-	LastHeartbeat  int64     `json:"last_heartbeat"`
-	HeartbeatValid bool      `json:"heartbeat_valid"`
-	Heartbeat      Heartbeat `json:"heartbeat"`
+	ID                     int64          `json:"id"`
+	Type                   string         `json:"type"`
+	Name                   string         `json:"name"`
+	Version                string         `json:"version"`
+	APIRedirectSuffix      string         `json:"api_redirect_suffix"`
+	ManagementURL          string         `json:"management_url"`
+	MaxSecWithoutHeartbeat int            `json:"max_sec_without_heartbeat"`
+	CreatedAt              int64          `json:"created_at"`
+	UpdatedAt              int64          `json:"updated_at"`
+	TenantID               int64          `json:"tenant_id"`
+	Capacity               PluginCapacity `json:"capacity"`
+	LastHeartbeat          int64          `json:"last_heartbeat"`
+	HeartbeatValid         bool           `json:"heartbeat_valid"`
+	Heartbeat              Heartbeat      `json:"heartbeat"`
+}
+
+type PluginCapacity struct {
+	TotalPhysicalCapacity  int64 `json:"total_physical_capacity"`
+	AllocatedPhysicalSpace int64 `json:"allocated_physical_space"`
+	TotalVirtualCapacity   int64 `json:"total_virtual_capacity"`
+	AllocatedVirtualSpace  int64 `json:"allocated_virtual_space"`
 }
 
 type Heartbeat struct {
-	EntityCounts []struct {
-		Entity string `json:"entity"`
-		Count  int64  `json:"count"`
-	} `json:"entity_counts"`
-	HealthState struct {
-		State    string   `json:"state"`
-		Messages []string `json:"messages"`
-	} `json:"health_state"`
+	EntityCounts []EntityCount `json:"entity_counts,omitempty"`
+	HealthState  HealthState   `json:"health_state"`
+}
+
+type EntityCount struct {
+	Entity string `json:"entity"`
+	Count  int64  `json:"count"`
+}
+
+type HealthState struct {
+	State    string   `json:"state"`
+	Messages []string `json:"messages"`
 }
 
+//GetPlugintByName returns plugin by name
+//
+//Deprecated: use GetPluginByName
 func (c *Client) GetPlugintByName(pluginname string) (*Plugin, error) {
+	return c.GetPluginByName(pluginname)
+}
+
+func (c *Client) GetPluginByName(pluginname string) (*Plugin, error) {
+
+	plugin, found, err := c.lookupPlugin(pluginname)
+	if err != nil {
+		return nil, err
+	}
+
+	if !found {
+		return nil, fmt.Errorf("plugin %s not found", pluginname)
+	}
+
+	return plugin, nil
+}
+
+func (c *Client) lookupPlugin(pluginname string) (plugin *Plugin, found bool, err error) {
 
 	queryRes, err := c.Find("plugins", "name", "eq", pluginname)
 
 	if err != nil {
-		return nil, fmt.Errorf(fmt.Sprintf("cannot find plugin by name: %s, error: %s", pluginname, err.Error()))
+		return nil, false, fmt.Errorf("cannot find plugin by name: %s, error: %s", pluginname, err.Error())
 	}
 
 	if queryRes == nil {
-		return nil, nil
+		return nil, false, nil
 	}
 
 	var plugins []Plugin
 
 	err = json.Unmarshal(*queryRes, &plugins)
 	if err != nil {
-		return nil, fmt.Errorf(fmt.Sprintf("unable to decode plugins collection: %s query result, error: %s", pluginname, err.Error()))
+		return nil, false, fmt.Errorf("unable to decode plugins collection: %s query result, error: %s", pluginname, err.Error())
 	}
 
 	if len(plugins) == 0 {
-		return nil, fmt.Errorf(fmt.Sprintf("plugin %s not found", pluginname))
+		return nil, false, nil
 	}
 
-	log.Debugf("Found plugin %#v", &plugins[0])
+	c.debugf("Found plugin %#v", &plugins[0])
 
-	return &plugins[0], nil
+	return &plugins[0], true, nil
+}
+
+func (c *Client) GetAllPlugins() (*[]Plugin, error) {
+
+	c.debugf("Getting plugins collection")
+
+	plugins, err := getAllPages[Plugin](c, "api/rest/plugins", nil)
+	if err != nil {
+		return nil, fmt.Errorf("error getting plugins collection, %s", err.Error())
+	}
+
+	if len(plugins) == 0 {
+		c.infof("plugins collection is empty")
+		return nil, nil
+	}
+
+	c.debugf("Got plugins collection")
+
+	return &plugins, nil
+}
+
+func (c *Client) GetPlugin(pluginID int64) (*Plugin, error) {
+
+	c.debugf("Getting plugin object ID: %d", pluginID)
+
+	url := fmt.Sprintf("api/rest/plugins/%d", pluginID)
+	response, err := c.request().Get(url)
+
+	result, err := CheckAPIResponse(response, err)
+	if err != nil {
+		return nil, fmt.Errorf("error getting plugin object, %s", err.Error())
+	}
+
+	var plugin Plugin
+	err = json.Unmarshal(*result.APIResult, &plugin)
+	if err != nil {
+		return nil, fmt.Errorf("error getting plugin object, %s", err.Error())
+	}
+
+	c.debugf("Got plugin object: %#v", plugin)
+
+	return &plugin, nil
+}
+
+func (p *Plugin) attributes() map[string]interface{} {
+
+	body := map[string]interface{}{"name": p.Name}
+
+	if p.Type != "" {
+		body["type"] = p.Type
+	}
+	if p.Version != "" {
+		body["version"] = p.Version
+	}
+	if p.APIRedirectSuffix != "" {
+		body["api_redirect_suffix"] = p.APIRedirectSuffix
+	}
+	if p.ManagementURL != "" {
+		body["management_url"] = p.ManagementURL
+	}
+	if p.MaxSecWithoutHeartbeat > 0 {
+		body["max_sec_without_heartbeat"] = p.MaxSecWithoutHeartbeat
+	}
+
+	return body
 }
 
 func (p *Plugin) Create(client *Client) (err error) {
 
-	log.Debugf("Creating plugin: %s", p.Name)
+	client.debugf("Creating plugin: %s", p.Name)
 	url := "api/rest/plugins"
-	response, err := client.RestClient.R().SetBody(map[string]interface{}{
-		"name": p.Name}).Post(url)
+	response, err := client.request().SetBody(p.attributes()).Post(url)
 
 	result, err := CheckAPIResponse(response, err)
 	if err != nil {
-		return fmt.Errorf(fmt.Sprintf("error creating plugin: %s,  %v", p.Name, err))
+		return fmt.Errorf("error creating plugin: %s,  %v", p.Name, err)
 	}
 
 	err = json.Unmarshal(*result.APIResult, &p)
 	if err != nil {
-		return fmt.Errorf(fmt.Sprintf("error creating plugin: %s,  %v", p.Name, err))
+		return fmt.Errorf("error creating plugin: %s,  %v", p.Name, err)
 	}
 
-	log.Debugf("Successfully created plugin %s", p.Name)
+	client.debugf("Successfully created plugin %s", p.Name)
 
 	return nil
 }
 
 func (p *Plugin) Delete(client *Client) (plugin *Plugin, err error) {
 
-	log.Debugf("Deleting tenant: %s", p.Name)
+	client.debugf("Deleting plugin: %s", p.Name)
 	url := fmt.Sprintf("api/rest/plugins/%d", p.ID)
-	response, err := client.RestClient.R().SetQueryParam("approved", "true").Delete(url)
+	response, err := client.sendApproved(ApprovalRequest{Operation: OperationDelete, ObjectType: "plugin", ObjectName: p.Name, Method: http.MethodDelete, URL: url}, nil)
 
 	result, err := CheckAPIResponse(response, err)
 	if err != nil {
-		return nil, fmt.Errorf(fmt.Sprintf("error deleting plugin: %s,  %v", p.Name, err))
+		return nil, fmt.Errorf("error deleting plugin: %s,  %v", p.Name, err)
 	}
 
 	err = json.Unmarshal(*result.APIResult, &plugin)
 	if err != nil {
-		return nil, fmt.Errorf(fmt.Sprintf("error deleting plugin: %s,  %v", p.Name, err))
+		return nil, fmt.Errorf("error deleting plugin: %s,  %v", p.Name, err)
 	}
 
-	log.Debugf("Successfully deleted plugin %s", p.Name)
+	client.debugf("Successfully deleted plugin %s", p.Name)
 
 	return plugin, nil
 }
 
 func (p *Plugin) updateAttributes(client *Client, attributesMap map[string]interface{}) (err error) {
 
-	log.Debugf("Updating plugin: %s", p.Name)
+	client.debugf("Updating plugin: %s", p.Name)
 	url := fmt.Sprintf("api/rest/plugins/%d", p.ID)
 
 	if len(attributesMap) > 0 {
-		response, err := client.RestClient.R().SetBody(attributesMap).Put(url)
+		response, err := client.request().SetBody(attributesMap).Put(url)
 
 		result, err := CheckAPIResponse(response, err)
 		if err != nil {
-			return fmt.Errorf(fmt.Sprintf("error updating plugin: %s,  %v", p.Name, err))
+			return fmt.Errorf("error updating plugin: %s,  %v", p.Name, err)
 		}
 
 		err = json.Unmarshal(*result.APIResult, &p)
 		if err != nil {
-			return fmt.Errorf(fmt.Sprintf("error updating plugin: %s,  %v", p.Name, err))
+			return fmt.Errorf("error updating plugin: %s,  %v", p.Name, err)
 		}
 	}
 
-	log.Debugf("Successfully updated plugin %s", p.Name)
+	client.debugf("Successfully updated plugin %s", p.Name)
 
 	return nil
 }
 
 func (p *Plugin) UpdateName(client *Client, name string) error {
 
-	log.Debugf("Renaming plugin %s", p.Name)
+	client.debugf("Renaming plugin %s", p.Name)
 
 	attributesMap := map[string]interface{}{"name": name}
 	err := p.updateAttributes(client, attributesMap)
 	if err != nil {
-		return fmt.Errorf(fmt.Sprintf("failed to rename plugin %s, %s", p.Name, err.Error()))
+		return fmt.Errorf("failed to rename plugin %s, %s", p.Name, err.Error())
+	}
+
+	client.debugf("Succesfully renamed plugin %s to %s", p.Name, name)
+
+	return nil
+}
+
+func (p *Plugin) Update(client *Client) error {
+
+	client.debugf("Updating plugin %s attributes", p.Name)
+
+	err := p.updateAttributes(client, p.attributes())
+	if err != nil {
+		return fmt.Errorf("failed to update plugin %s, %s", p.Name, err.Error())
+	}
+
+	client.debugf("Succesfully updated plugin %s attributes", p.Name)
+
+	return nil
+}
+
+//CollectCapacity fills plugin Capacity from volumes and pools visible in plugin tenant, total
+//capacities come from pools, virtual space is provisioned volume size and physical space is allocated
+func (p *Plugin) CollectCapacity(client *Client) error {
+
+	client.debugf("Collecting capacity for plugin %s", p.Name)
+
+	scoped := client
+	if p.TenantID != 0 {
+		scoped = client.WithTenantID(p.TenantID)
+	}
+
+	capacity := PluginCapacity{}
+
+	pools, err := scoped.GetAllPools()
+	if err != nil {
+		return fmt.Errorf("failed to collect capacity for plugin %s, %s", p.Name, err.Error())
+	}
+	if pools != nil {
+		for _, pool := range *pools {
+			capacity.TotalPhysicalCapacity += int64(pool.PhysicalCapacity)
+			capacity.TotalVirtualCapacity += int64(pool.VirtualCapacity)
+		}
+	}
+
+	volumes, err := scoped.GetAllVolumes()
+	if err != nil {
+		return fmt.Errorf("failed to collect capacity for plugin %s, %s", p.Name, err.Error())
+	}
+	if volumes != nil {
+		for _, volume := range *volumes {
+			if volume.Type == "SNAPSHOT" {
+				continue
+			}
+			capacity.AllocatedVirtualSpace += int64(volume.Size)
+			capacity.AllocatedPhysicalSpace += int64(volume.Allocated)
+		}
 	}
 
-	log.Debugf("Succesfully renamed plugin %s to %s", p.Name, name)
+	p.Capacity = capacity
+
+	client.debugf("Collected capacity for plugin %s: %+v", p.Name, capacity)
+
+	return nil
+}
+
+//ReportCapacity collects plugin capacity and updates it on IBOX
+func (p *Plugin) ReportCapacity(client *Client) error {
+
+	err := p.CollectCapacity(client)
+	if err != nil {
+		return err
+	}
+
+	err = p.updateAttributes(client, map[string]interface{}{"capacity": p.Capacity})
+	if err != nil {
+		return fmt.Errorf("failed to report capacity for plugin %s, %s", p.Name, err.Error())
+	}
 
 	return nil
 }
 
 func (p *Plugin) SendPluginHeartbeat(client *Client, heartbeat Heartbeat) error {
 
-	log.Debugf("Sending plugin heartbeat %s", p.Name)
+	client.debugf("Sending plugin heartbeat %s", p.Name)
 
 	url := fmt.Sprintf("api/rest/plugins/%d/heartbeat", p.ID)
 
-	response, err := client.RestClient.R().SetBody(heartbeat).Put(url)
+	response, err := client.request().SetBody(heartbeat).Put(url)
 
 	result, err := CheckAPIResponse(response, err)
 	if err != nil {
-		return fmt.Errorf(fmt.Sprintf("error sending plugin heartbeat: %s,  %v", p.Name, err))
+		return fmt.Errorf("error sending plugin heartbeat: %s,  %v", p.Name, err)
 	}
 
 	err = json.Unmarshal(*result.APIResult, &heartbeat)
 	if err != nil {
-		return fmt.Errorf(fmt.Sprintf("error sending plugin heartbeat: %s,  %v", p.Name, err))
+		return fmt.Errorf("error sending plugin heartbeat: %s,  %v", p.Name, err)
 	}
 
-	log.Debugf("Succesfully sent plugin heartbeat %s to %s", p.Name, heartbeat)
+	client.debugf("Succesfully sent plugin heartbeat %+v to %s", heartbeat, p.Name)
 
 	return nil
 }
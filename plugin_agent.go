@@ -0,0 +1,227 @@
+package infinibox
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+//Plugin health states reported in heartbeat
+const (
+	PluginHealthOK      = "OK"
+	PluginHealthWarning = "WARNING"
+	PluginHealthError   = "ERROR"
+)
+
+//defaultHeartbeatInterval used when plugin defines no MaxSecWithoutHeartbeat
+const defaultHeartbeatInterval = 30 * time.Second
+
+//defaultCountsInterval used when agent defines no CountsInterval
+const defaultCountsInterval = 5 * time.Minute
+
+//PluginAgent registers plugin and keeps its heartbeat valid until stopped
+type PluginAgent struct {
+	Client *Client
+	Plugin *Plugin
+	Owner  string
+	//Interval between heartbeats, it is clamped to half of plugin MaxSecWithoutHeartbeat
+	Interval time.Duration
+	//CountsInterval between entity counts refreshes, heartbeats in between report cached counts
+	CountsInterval time.Duration
+
+	mu              sync.Mutex
+	health          HealthState
+	counts          []EntityCount
+	countsUpdatedAt time.Time
+}
+
+//NewPluginAgent returns agent for plugin, entity counts are computed from objects
+//stamped with owner, empty owner disables entity counts
+func NewPluginAgent(client *Client, plugin *Plugin, owner string) *PluginAgent {
+	return &PluginAgent{
+		Client: client,
+		Plugin: plugin,
+		Owner:  owner,
+		health: HealthState{State: PluginHealthOK, Messages: []string{}},
+	}
+}
+
+//Register looks plugin up by name and creates it when missing
+func (a *PluginAgent) Register() error {
+
+	a.Client.debugf("Registering plugin %s", a.Plugin.Name)
+
+	plugin, found, err := a.Client.lookupPlugin(a.Plugin.Name)
+	if err != nil {
+		return fmt.Errorf("error registering plugin %s, %s", a.Plugin.Name, err.Error())
+	}
+
+	if found {
+		a.Plugin = plugin
+		a.Client.debugf("Plugin %s already registered with ID %d", plugin.Name, plugin.ID)
+		return nil
+	}
+
+	err = a.Plugin.Create(a.Client)
+	if err != nil {
+		return fmt.Errorf("error registering plugin %s, %s", a.Plugin.Name, err.Error())
+	}
+
+	a.Client.debugf("Registered plugin %s with ID %d", a.Plugin.Name, a.Plugin.ID)
+
+	return nil
+}
+
+//SetHealth sets health state and messages reported by following heartbeats
+func (a *PluginAgent) SetHealth(state string, messages ...string) {
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if messages == nil {
+		messages = []string{}
+	}
+	a.health = HealthState{State: state, Messages: messages}
+}
+
+//Health returns currently reported health state
+func (a *PluginAgent) Health() HealthState {
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return HealthState{State: a.health.State, Messages: append([]string{}, a.health.Messages...)}
+}
+
+//interval returns heartbeat period keeping plugin within MaxSecWithoutHeartbeat
+func (a *PluginAgent) interval() time.Duration {
+
+	if a.Plugin.MaxSecWithoutHeartbeat <= 0 {
+		if a.Interval > 0 {
+			return a.Interval
+		}
+		return defaultHeartbeatInterval
+	}
+
+	limit := time.Duration(a.Plugin.MaxSecWithoutHeartbeat) * time.Second / 2
+	if limit < time.Second {
+		limit = time.Second
+	}
+	if a.Interval > 0 && a.Interval < limit {
+		return a.Interval
+	}
+	return limit
+}
+
+//countsInterval returns entity counts refresh period
+func (a *PluginAgent) countsInterval() time.Duration {
+	if a.CountsInterval > 0 {
+		return a.CountsInterval
+	}
+	return defaultCountsInterval
+}
+
+//entityCounts counts objects stamped with agent owner by object type through client, counts are
+//cached for CountsInterval and refresh must finish within half of heartbeat interval
+func (a *PluginAgent) entityCounts(client *Client) ([]EntityCount, error) {
+
+	if a.Owner == "" {
+		return []EntityCount{}, nil
+	}
+
+	a.mu.Lock()
+	if a.counts != nil && time.Since(a.countsUpdatedAt) < a.countsInterval() {
+		counts := append([]EntityCount{}, a.counts...)
+		a.mu.Unlock()
+		return counts, nil
+	}
+	a.mu.Unlock()
+
+	ctx := client.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, cancel := context.WithTimeout(ctx, a.interval()/2)
+	defer cancel()
+
+	markers, err := client.WithContext(ctx).FindObjectsByMetadata("", OwnerMetadataKey, a.Owner)
+	if err != nil {
+		return nil, err
+	}
+
+	byType := map[string]int64{}
+	for _, marker := range *markers {
+		byType[marker.ObjectType]++
+	}
+
+	counts := []EntityCount{}
+	for objectType, count := range byType {
+		counts = append(counts, EntityCount{Entity: fmt.Sprintf("%ss", objectType), Count: count})
+	}
+	sort.Slice(counts, func(i, j int) bool { return counts[i].Entity < counts[j].Entity })
+
+	a.mu.Lock()
+	a.counts = counts
+	a.countsUpdatedAt = time.Now()
+	a.mu.Unlock()
+
+	return append([]EntityCount{}, counts...), nil
+}
+
+//SendHeartbeat sends single heartbeat with current health state and entity counts, heartbeat
+//is sent without counts and with degraded health when counts cannot be computed
+func (a *PluginAgent) SendHeartbeat() error {
+	return a.sendHeartbeat(a.Client)
+}
+
+//sendHeartbeat sends heartbeat through client, Run passes client scoped to its context so
+//cancelling Run aborts heartbeat in flight
+func (a *PluginAgent) sendHeartbeat(client *Client) error {
+
+	health := a.Health()
+
+	counts, err := a.entityCounts(client)
+	if err != nil {
+		client.warnf("error counting entities for plugin %s, %s", a.Plugin.Name, err.Error())
+		if health.State == PluginHealthOK {
+			health.State = PluginHealthWarning
+		}
+		health.Messages = append(health.Messages, fmt.Sprintf("entity counts unavailable: %s", err.Error()))
+	}
+
+	heartbeat := Heartbeat{EntityCounts: counts, HealthState: health}
+
+	return a.Plugin.SendPluginHeartbeat(client, heartbeat)
+}
+
+//Run registers plugin and sends heartbeats until ctx is cancelled
+func (a *PluginAgent) Run(ctx context.Context) error {
+
+	err := a.Register()
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(a.interval())
+	defer ticker.Stop()
+
+	a.Client.infof("Started heartbeat for plugin %s every %s", a.Plugin.Name, a.interval())
+
+	scoped := a.Client.WithContext(ctx)
+
+	for {
+		err = a.sendHeartbeat(scoped)
+		if err != nil && ctx.Err() == nil {
+			a.Client.errorf("heartbeat for plugin %s failed, %s", a.Plugin.Name, err.Error())
+		}
+
+		select {
+		case <-ctx.Done():
+			a.Client.infof("Stopped heartbeat for plugin %s", a.Plugin.Name)
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package infinibox
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestPluginAgentRunCancelsHeartbeatInFlight(t *testing.T) {
+
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	defer close(release)
+
+	ibox := newTestIBOX(t)
+	ibox.handle(http.MethodGet, "/api/rest/plugins", func(w http.ResponseWriter, r *http.Request) {
+		writeResult(w, []Plugin{{ID: 3, Name: "csi"}})
+	})
+	ibox.handle(http.MethodPut, "/api/rest/plugins/3/heartbeat", func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+		writeResult(w, map[string]interface{}{})
+	})
+	client := ibox.client(t, Config{})
+
+	agent := NewPluginAgent(client, &Plugin{Name: "csi"}, "")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- agent.Run(ctx) }()
+
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("heartbeat was not sent")
+	}
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Run returned %s", err.Error())
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Run did not return after cancel while heartbeat was in flight")
+	}
+}
@@ -3,8 +3,7 @@ package infinibox
 import (
 	"encoding/json"
 	"fmt"
-	"github.com/go-resty/resty"
-	log "github.com/sirupsen/logrus"
+	"net/http"
 )
 
 type Pool struct {
@@ -37,81 +36,74 @@ type Pool struct {
 
 func (c *Client) GetPoolByName(poolname string) (*Pool, error) {
 
+	pool, found, err := cachedLookup(c, cachePools, poolname, func() (*Pool, bool, error) {
+		return c.lookupPool(poolname)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !found {
+		return nil, fmt.Errorf("pool %s not found", poolname)
+	}
+
+	return pool, nil
+}
+
+func (c *Client) lookupPool(poolname string) (pool *Pool, found bool, err error) {
+
 	queryRes, err := c.Find("pools", "name", "eq", poolname)
 
 	if err != nil {
-		return nil, fmt.Errorf(fmt.Sprintf("cannot find pool by name: %s, error: %s", poolname, err.Error()))
+		return nil, false, fmt.Errorf("cannot find pool by name: %s, error: %s", poolname, err.Error())
 	}
 
 	if queryRes == nil {
-		return nil, nil
+		return nil, false, nil
 	}
 
 	var pools []Pool
 
 	err = json.Unmarshal(*queryRes, &pools)
 	if err != nil {
-		return nil, fmt.Errorf(fmt.Sprintf("unable to decode pool: %s query result, error: %s", poolname, err.Error()))
+		return nil, false, fmt.Errorf("unable to decode pool: %s query result, error: %s", poolname, err.Error())
 	}
 
 	if len(pools) == 0 {
-		return nil, fmt.Errorf(fmt.Sprintf("pool %s not found", poolname))
+		return nil, false, nil
 	}
 
-	log.Debugf("Found pool %#v", &pools[0])
+	c.debugf("Found pool %#v", &pools[0])
 
-	return &pools[0], nil
+	return &pools[0], true, nil
 }
 
 func (c *Client) GetAllPools() (*[]Pool, error) {
 
-	log.Debug("Getting pools collection")
+	c.debugf("Getting pools collection")
 
-	url := "api/rest/pools"
-
-	var request *resty.Request
-
-	if c.config.tenant != "" {
-		log.Debugf("Adding tenant_id %d to request", c.config.tenant)
-		request = c.RestClient.R().SetHeader("X-INFINIDAT-TENANT-ID", c.config.tenant)
-	} else {
-		request = c.RestClient.R()
-	}
-
-	response, err := request.Get(url)
-
-	result, err := CheckAPIResponse(response, err)
+	pools, err := getAllPages[Pool](c, "api/rest/pools", nil)
 	if err != nil {
-		return nil, fmt.Errorf("error getting pools collection")
+		return nil, fmt.Errorf("error getting pools collection, %s", err.Error())
 	}
 
-	num := result.APIMetadata["number_of_objects"]
-	if num == nil {
-		return nil, fmt.Errorf("cannot parse metadata for number_of_objects field")
-	}
-	if num == float64(0) {
-		log.Infof("pools collection is empty")
+	if len(pools) == 0 {
+		c.infof("pools collection is empty")
 		return nil, nil
 	}
 
-	var pools []Pool
-	err = json.Unmarshal(*result.APIResult, &pools)
-	if err != nil {
-		return nil, fmt.Errorf("error getting pools collection")
-	}
-
-	log.Debugf("Got pools collection")
+	c.debugf("Got pools collection")
 
 	return &pools, nil
 }
 
 func (c *Client) GetPool(poolID int64) (*Pool, error) {
 
-	log.Debugf("Getting host object ID: %s", poolID)
+	c.debugf("Getting pool object ID: %d", poolID)
 
-	url := fmt.Sprintf("api/rest/hosts/%d", poolID)
+	url := fmt.Sprintf("api/rest/pools/%d", poolID)
 
-	response, err := c.RestClient.R().Get(url)
+	response, err := c.request().Get(url)
 
 	result, err := CheckAPIResponse(response, err)
 	if err != nil {
@@ -121,164 +113,249 @@ func (c *Client) GetPool(poolID int64) (*Pool, error) {
 	var pool Pool
 	err = json.Unmarshal(*result.APIResult, &pool)
 	if err != nil {
-		return nil, fmt.Errorf(fmt.Sprintf("json: %s", err.Error()))
+		return nil, fmt.Errorf("json: %s", err.Error())
 	}
 
-	log.Debugf("Got pool object: %#v", pool)
+	c.debugf("Got pool object: %#v", pool)
 
 	return &pool, nil
 }
 
 func (p *Pool) Create(client *Client) (err error) {
 
-	log.Debugf("Creating pool: %s", p.Name)
+	client.debugf("Creating pool: %s", p.Name)
 	url := "api/rest/pools"
 
-	var request *resty.Request
-
-	if client.config.tenant != "" {
-		log.Debugf("Adding tenant_id %d to request", client.config.tenant)
-		request = client.RestClient.R().SetHeader("X-INFINIDAT-TENANT-ID", client.config.tenant)
-	} else {
-		request = client.RestClient.R()
-	}
-
-	response, err := request.SetBody(map[string]interface{}{
+	response, err := client.request().SetBody(map[string]interface{}{
 		"name":              p.Name,
 		"physical_capacity": p.PhysicalCapacity,
 		"virtual_capacity":  p.VirtualCapacity}).Post(url)
 
 	result, err := CheckAPIResponse(response, err)
 	if err != nil {
-		return fmt.Errorf(fmt.Sprintf("error creating pool: %s,  %v", p.Name, err))
+		return fmt.Errorf("error creating pool: %s,  %v", p.Name, err)
 	}
 
 	err = json.Unmarshal(*result.APIResult, &p)
 	if err != nil {
-		return fmt.Errorf(fmt.Sprintf("error creating pool: %s,  %v", p.Name, err))
+		return fmt.Errorf("error creating pool: %s,  %v", p.Name, err)
 	}
 
-	log.Debugf("Successfully created pool %s", p.Name)
+	client.debugf("Successfully created pool %s", p.Name)
 
 	return nil
 }
 
 func (p *Pool) Delete(client *Client) (pool *Pool, err error) {
 
-	log.Debugf("Deleting pool: %s", p.Name)
+	client.debugf("Deleting pool: %s", p.Name)
 	url := fmt.Sprintf("api/rest/pools/%d", p.ID)
-	response, err := client.RestClient.R().SetQueryParam("approved", "true").Delete(url)
+	response, err := client.sendApproved(ApprovalRequest{Operation: OperationDelete, ObjectType: "pool", ObjectName: p.Name, Method: http.MethodDelete, URL: url}, nil)
 
 	result, err := CheckAPIResponse(response, err)
 	if err != nil {
-		return nil, fmt.Errorf(fmt.Sprintf("error deleting pool: %s,  %v", p.Name, err))
+		return nil, fmt.Errorf("error deleting pool: %s,  %v", p.Name, err)
 	}
 
 	err = json.Unmarshal(*result.APIResult, &pool)
 	if err != nil {
-		return nil, fmt.Errorf(fmt.Sprintf("error deleting pool: %s,  %v", p.Name, err))
+		return nil, fmt.Errorf("error deleting pool: %s,  %v", p.Name, err)
 	}
 
-	log.Debugf("Successfully deleted pool %s", p.Name)
+	client.debugf("Successfully deleted pool %s", p.Name)
 
 	return pool, nil
 }
 
 func (p *Pool) updateAttributes(client *Client, attributesMap map[string]interface{}) (err error) {
 
-	log.Debugf("Updating pool: %s", p.Name)
+	client.debugf("Updating pool: %s", p.Name)
 	url := fmt.Sprintf("api/rest/pools/%d", p.ID)
 
 	if len(attributesMap) > 0 {
-		response, err := client.RestClient.R().SetBody(attributesMap).Put(url)
+		response, err := client.request().SetBody(attributesMap).Put(url)
 
 		result, err := CheckAPIResponse(response, err)
 		if err != nil {
-			return fmt.Errorf(fmt.Sprintf("error updating pool: %s,  %v", p.Name, err))
+			return fmt.Errorf("error updating pool: %s,  %v", p.Name, err)
 		}
 
 		err = json.Unmarshal(*result.APIResult, &p)
 		if err != nil {
-			return fmt.Errorf(fmt.Sprintf("error updating pool: %s,  %v", p.Name, err))
+			return fmt.Errorf("error updating pool: %s,  %v", p.Name, err)
 		}
 	}
 
-	log.Debugf("Successfully updated pool %s", p.Name)
+	client.debugf("Successfully updated pool %s", p.Name)
 
 	return nil
 }
 
 func (p *Pool) UpdateName(client *Client, name string) error {
 
-	log.Debugf("Renaming pool %s", p.Name)
+	client.debugf("Renaming pool %s", p.Name)
 
 	attributesMap := map[string]interface{}{"name": name}
 	err := p.updateAttributes(client, attributesMap)
 	if err != nil {
-		return fmt.Errorf(fmt.Sprintf("failed to rename pool %s, %s", p.Name, err.Error()))
+		return fmt.Errorf("failed to rename pool %s, %s", p.Name, err.Error())
 	}
 
-	log.Debugf("Succesfully renamed pool %s to %s", p.Name, name)
+	client.debugf("Succesfully renamed pool %s to %s", p.Name, name)
 
 	return nil
 }
 
 func (p *Pool) UpdatePhysicalCapacity(client *Client, capacity uint64) error {
 
-	log.Debugf("Updating PhysicalCapacity for pool %s", p.Name)
+	client.debugf("Updating PhysicalCapacity for pool %s", p.Name)
 
 	attributesMap := map[string]interface{}{"physical_capacity": capacity}
 	err := p.updateAttributes(client, attributesMap)
 	if err != nil {
-		return fmt.Errorf(fmt.Sprintf("failed to update pool %s PhysicalCapacity, %s", p.Name, err.Error()))
+		return fmt.Errorf("failed to update pool %s PhysicalCapacity, %s", p.Name, err.Error())
 	}
 
-	log.Debugf("Succesfully updated pool %s PhysicalCapacity to %d", p.Name, capacity)
+	client.debugf("Succesfully updated pool %s PhysicalCapacity to %d", p.Name, capacity)
 
 	return nil
 }
 
 func (p *Pool) UpdateVirtualCapacity(client *Client, capacity uint64) error {
 
-	log.Debugf("Updating VirtualCapacity for pool %s", p.Name)
+	client.debugf("Updating VirtualCapacity for pool %s", p.Name)
 
 	attributesMap := map[string]interface{}{"virtual_capacity": capacity}
 	err := p.updateAttributes(client, attributesMap)
 	if err != nil {
-		return fmt.Errorf(fmt.Sprintf("failed to update pool %s VirtualCapacity, %s", p.Name, err.Error()))
+		return fmt.Errorf("failed to update pool %s VirtualCapacity, %s", p.Name, err.Error())
 	}
 
-	log.Debugf("Succesfully updated pool %s VirtualCapacity to %d", p.Name, capacity)
+	client.debugf("Succesfully updated pool %s VirtualCapacity to %d", p.Name, capacity)
 
 	return nil
 }
 
 func (p *Pool) UpdateSsdEnabled(client *Client, enabled bool) error {
 
-	log.Debugf("Updating SsdEnabled for pool %s", p.Name)
+	client.debugf("Updating SsdEnabled for pool %s", p.Name)
 
 	attributesMap := map[string]interface{}{"ssd_enabled": enabled}
 	err := p.updateAttributes(client, attributesMap)
 	if err != nil {
-		return fmt.Errorf(fmt.Sprintf("failed to update pool %s SsdEnabled, %s", p.Name, err.Error()))
+		return fmt.Errorf("failed to update pool %s SsdEnabled, %s", p.Name, err.Error())
 	}
 
-	log.Debugf("Succesfully updated pool %s SsdEnabled to %v", p.Name, enabled)
+	client.debugf("Succesfully updated pool %s SsdEnabled to %v", p.Name, enabled)
 
 	return nil
 }
 
 func (p *Pool) UpdateCompressionEnabled(client *Client, enabled bool) error {
 
-	log.Debugf("Updating CompressionEnabled for pool %s", p.Name)
+	client.debugf("Updating CompressionEnabled for pool %s", p.Name)
 
 	attributesMap := map[string]interface{}{"compression_enabled": enabled}
 	err := p.updateAttributes(client, attributesMap)
 	if err != nil {
-		return fmt.Errorf(fmt.Sprintf("failed to update pool %s CompressionEnabled, %s", p.Name, err.Error()))
+		return fmt.Errorf("failed to update pool %s CompressionEnabled, %s", p.Name, err.Error())
+	}
+
+	client.debugf("Succesfully updated pool %s CompressionEnabled to %v", p.Name, enabled)
+
+	return nil
+}
+
+//SetMetadata sets pool metadata key value
+func (p *Pool) SetMetadata(client *Client, key string, value string) (err error) {
+
+	client.debugf("Setting metadata for pool %s", p.Name)
+
+	err = client.AddMetadata(&Metadata{ObjectID: p.ID, Key: key, Value: value})
+	if err != nil {
+		return fmt.Errorf("unable to set metadata for pool %s, error %s", p.Name, err.Error())
+	}
+
+	client.debugf("Set metadata for pool %s", p.Name)
+
+	return nil
+}
+
+//SetMetadataMap sets all provided pool metadata keys in single request
+func (p *Pool) SetMetadataMap(client *Client, values map[string]interface{}) (err error) {
+
+	client.debugf("Setting metadata map for pool %s", p.Name)
+
+	err = client.AddMetadataMap(p.ID, values)
+	if err != nil {
+		return fmt.Errorf("unable to set metadata for pool %s, error %s", p.Name, err.Error())
+	}
+
+	client.debugf("Set metadata map for pool %s", p.Name)
+
+	return nil
+}
+
+//GetMetadata returns pool metadata, filtered by key when not empty
+func (p *Pool) GetMetadata(client *Client, key string) (metadata *[]Metadata, err error) {
+
+	client.debugf("Getting metadata for pool %s", p.Name)
+
+	metadata, err = client.GetMetadataByObject(p.ID)
+	if err != nil {
+		return metadata, fmt.Errorf("unable to get metadata for pool %s, error %s", p.Name, err.Error())
+	}
+
+	metadata = filterMetadataByKey(metadata, key)
+
+	client.debugf("Got metadata for pool %s", p.Name)
+
+	return metadata, nil
+}
+
+//GetMetadataValue returns pool metadata key value
+func (p *Pool) GetMetadataValue(client *Client, key string) (value interface{}, err error) {
+
+	client.debugf("Getting metadata value for pool %s and key %s", p.Name, key)
+
+	metadata, err := client.GetMetadataByObjectAndKey(p.ID, key)
+	if err != nil {
+		return value, fmt.Errorf("unable to get metadata for pool %s, error %s", p.Name, err.Error())
+	}
+
+	value = metadata.Value
+
+	client.debugf("Got metadata value for pool %s and key %s", p.Name, key)
+
+	return value, nil
+}
+
+//UnSetMetadata removes pool metadata key
+func (p *Pool) UnSetMetadata(client *Client, key string) (err error) {
+
+	client.debugf("Unsetting metadata for pool %s", p.Name)
+
+	err = client.DeleteMetadataByKey(p.ID, key)
+	if err != nil {
+		return fmt.Errorf("unable to unset metadata for pool %s, error %s", p.Name, err.Error())
+	}
+
+	client.debugf("Unset metadata for pool %s", p.Name)
+
+	return nil
+}
+
+//ClearMetadata removes all pool metadata
+func (p *Pool) ClearMetadata(client *Client) (err error) {
+
+	client.debugf("Clearing metadata for pool %s", p.Name)
+
+	err = client.DeleteMetadata(p.ID)
+	if err != nil {
+		return fmt.Errorf("unable to clear metadata for pool %s, error %s", p.Name, err.Error())
 	}
 
-	log.Debugf("Succesfully updated pool %s CompressionEnabled to %v", p.Name, enabled)
+	client.debugf("Cleared metadata for pool %s", p.Name)
 
 	return nil
 }
@@ -3,33 +3,26 @@ package infinibox
 import (
 	"encoding/json"
 	"fmt"
-	"github.com/go-resty/resty"
-	log "github.com/sirupsen/logrus"
+	"strconv"
 )
 
+//collectionPageSize is page size used when reading all pages of collection, it is IBOX maximum
+const collectionPageSize = 1000
+
 func (c *Client) Find(collection string, param string, op string, value string) (queryRes *json.RawMessage, err error) {
 
 	url := fmt.Sprintf("/api/rest/%s", collection)
 
-	var request *resty.Request
-
-	if c.config.tenant != "" {
-		log.Debugf("Adding tenant_id %d to request", c.config.tenant)
-		request = c.RestClient.R().SetQueryParam("tenant_id", c.config.tenant)
-	} else {
-		request = c.RestClient.R()
-	}
-
-	response, err := request.SetQueryParam(param, fmt.Sprint(op+string(':')+value)).Get(url)
+	response, err := c.request().SetQueryParam(param, fmt.Sprint(op+string(':')+value)).Get(url)
 
 	if err != nil {
-		log.Error(err.Error())
+		c.errorf("%s", err.Error())
 		return nil, err
 	}
 
 	apiResult, err := CheckAPIResponse(response, err)
 	if err != nil {
-		log.Error(err.Error())
+		c.errorf("%s", err.Error())
 		return nil, err
 	}
 
@@ -37,3 +30,41 @@ func (c *Client) Find(collection string, param string, op string, value string)
 
 	return queryRes, nil
 }
+
+//getAllPages returns objects of collection at url reading every page, filters map
+//field names to op:value expressions evaluated by IBOX
+func getAllPages[T any](c *Client, url string, filters map[string]string) ([]T, error) {
+
+	objects := []T{}
+
+	for page := 1; ; page++ {
+
+		request := c.request()
+		for field, filter := range filters {
+			request.SetQueryParam(field, filter)
+		}
+		request.SetQueryParam("page", strconv.Itoa(page))
+		request.SetQueryParam("page_size", strconv.Itoa(collectionPageSize))
+
+		response, err := request.Get(url)
+
+		result, err := CheckAPIResponse(response, err)
+		if err != nil {
+			return nil, err
+		}
+
+		if result.APIResult != nil {
+			var pageObjects []T
+			err = json.Unmarshal(*result.APIResult, &pageObjects)
+			if err != nil {
+				return nil, fmt.Errorf("unable to decode %s page %d, %s", url, page, err.Error())
+			}
+			objects = append(objects, pageObjects...)
+		}
+
+		pagesTotal, _ := result.APIMetadata["pages_total"].(float64)
+		if page >= int(pagesTotal) {
+			return objects, nil
+		}
+	}
+}
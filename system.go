@@ -0,0 +1,188 @@
+package infinibox
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//System features probed by System.Supports
+const (
+	FeatureNVMe         = "nvme"
+	FeatureSMB          = "smb"
+	FeatureSnapshotLock = "snapshot_lock"
+	FeatureReplication  = "replication"
+	FeatureQoS          = "qos"
+)
+
+//FeatureProbePaths maps features to API collections present only on systems supporting them,
+//Client.Supports queries collection and treats missing path as unsupported feature
+var FeatureProbePaths = map[string]string{
+	FeatureReplication: "api/rest/replicas",
+	FeatureQoS:         "api/rest/qos/policies",
+	FeatureSMB:         "api/rest/shares",
+}
+
+//FeatureMinVersions maps features to minimal system software version supporting them, it is used for
+//features without probe path. IBOX does not report these thresholds and no verified defaults are
+//shipped, callers set entries from release notes of deployed firmware to enable version checks
+var FeatureMinVersions = map[string]string{}
+
+//ErrFeatureSupportUnknown is wrapped by errors of features whose support cannot be determined
+var ErrFeatureSupportUnknown = errors.New("feature support unknown")
+
+//SystemCapacity represents IBOX system capacity struct
+type SystemCapacity struct {
+	TotalPhysicalCapacity uint64  `json:"total_physical_capacity"`
+	FreePhysicalSpace     uint64  `json:"free_physical_space"`
+	TotalVirtualCapacity  uint64  `json:"total_virtual_capacity"`
+	FreeVirtualSpace      uint64  `json:"free_virtual_space"`
+	AllocatedVirtualSpace uint64  `json:"allocated_virtual_space"`
+	DataReductionRatio    float64 `json:"data_reduction_ratio"`
+}
+
+//SystemOperationalState represents IBOX system operational state struct
+type SystemOperationalState struct {
+	State           string `json:"state"`
+	Mode            string `json:"mode"`
+	ReadinessStatus string `json:"readiness_status"`
+	InitState       string `json:"init_state"`
+	Lockdown        bool   `json:"lockdown"`
+}
+
+//System represents IBOX system struct
+type System struct {
+	Name             string                 `json:"name"`
+	SerialNumber     int64                  `json:"serial_number"`
+	ModelName        string                 `json:"model_name"`
+	Version          string                 `json:"version"`
+	Uptime           int64                  `json:"uptime"`
+	Capacity         SystemCapacity         `json:"capacity"`
+	OperationalState SystemOperationalState `json:"operational_state"`
+	APIReady         bool                   `json:"-"`
+}
+
+//GetSystem returns system information, capacity and operational state
+func (c *Client) GetSystem() (*System, error) {
+
+	c.debugf("Getting system information")
+
+	url := "api/rest/system"
+	response, err := c.request().Get(url)
+
+	result, err := CheckAPIResponse(response, err)
+	if err != nil {
+		return nil, fmt.Errorf("error getting system information, %s", err.Error())
+	}
+
+	var system System
+	err = json.Unmarshal(*result.APIResult, &system)
+	if err != nil {
+		return nil, fmt.Errorf("error getting system information, %s", err.Error())
+	}
+
+	if ready, ok := result.APIMetadata["ready"].(bool); ok {
+		system.APIReady = ready
+	}
+
+	c.debugf("Got system %s serial %d version %s", system.ModelName, system.SerialNumber, system.Version)
+
+	return &system, nil
+}
+
+//UptimeDuration returns system uptime, IBOX reports uptime in milliseconds
+func (s *System) UptimeDuration() time.Duration {
+	return time.Duration(s.Uptime) * time.Millisecond
+}
+
+//IsActive reports whether system is operational
+func (s *System) IsActive() bool {
+	return strings.EqualFold(s.OperationalState.State, "ACTIVE")
+}
+
+//AtLeastVersion reports whether system software version is not lower than version
+func (s *System) AtLeastVersion(version string) bool {
+	return compareVersions(s.Version, version) >= 0
+}
+
+//Supports reports whether system software version supports feature according to FeatureMinVersions,
+//error wrapping ErrFeatureSupportUnknown is returned for features without entry, use Client.Supports to probe system
+func (s *System) Supports(feature string) (bool, error) {
+
+	minVersion, ok := FeatureMinVersions[feature]
+	if !ok {
+		return false, fmt.Errorf("no verified minimal version of feature %s, %w", feature, ErrFeatureSupportUnknown)
+	}
+
+	return s.AtLeastVersion(minVersion), nil
+}
+
+//Supports reports whether system supports feature, features with probe path are probed by querying
+//their collection, other features are decided by software version read from system and FeatureMinVersions
+func (c *Client) Supports(feature string) (bool, error) {
+
+	path, ok := FeatureProbePaths[feature]
+	if !ok {
+		system, err := c.GetSystem()
+		if err != nil {
+			return false, err
+		}
+		return system.Supports(feature)
+	}
+
+	c.debugf("Probing feature %s at %s", feature, path)
+
+	response, err := c.request().SetQueryParam("page_size", "1").Get(path)
+	if err == nil && response.StatusCode() == http.StatusNotFound {
+		c.debugf("Feature %s is not supported", feature)
+		return false, nil
+	}
+
+	_, err = CheckAPIResponse(response, err)
+	if err != nil {
+		return false, fmt.Errorf("error probing feature %s, %s", feature, err.Error())
+	}
+
+	return true, nil
+}
+
+//compareVersions compares dotted numeric versions, non numeric suffixes are ignored
+func compareVersions(a string, b string) int {
+
+	as := strings.Split(a, ".")
+	bs := strings.Split(b, ".")
+
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		av, bv := versionPart(as, i), versionPart(bs, i)
+		if av != bv {
+			if av < bv {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	return 0
+}
+
+func versionPart(parts []string, i int) int {
+
+	if i >= len(parts) {
+		return 0
+	}
+
+	digits := strings.TrimFunc(parts[i], func(r rune) bool { return r < '0' || r > '9' })
+	if end := strings.IndexFunc(digits, func(r rune) bool { return r < '0' || r > '9' }); end >= 0 {
+		digits = digits[:end]
+	}
+
+	n, err := strconv.Atoi(digits)
+	if err != nil {
+		return 0
+	}
+	return n
+}
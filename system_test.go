@@ -0,0 +1,92 @@
+package infinibox
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestCompareVersions(t *testing.T) {
+
+	tests := []struct {
+		a    string
+		b    string
+		want int
+	}{
+		{a: "7.1", b: "7.1", want: 0},
+		{a: "7.1", b: "7.1.0", want: 0},
+		{a: "7.1.0.0", b: "7.1", want: 0},
+		{a: "7.0", b: "7.1", want: -1},
+		{a: "7.10", b: "7.9", want: 1},
+		{a: "6.9.99", b: "7.0", want: -1},
+		{a: "8", b: "7.9.9", want: 1},
+		{a: "7.1.2-rc1", b: "7.1.2", want: 0},
+		{a: "v5.0", b: "5.0", want: 0},
+		{a: "7.3.10.12", b: "7.3.10.2", want: 1},
+		{a: "", b: "0", want: 0},
+		{a: "", b: "1", want: -1},
+	}
+
+	for _, tt := range tests {
+		if got := compareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+		if got := compareVersions(tt.b, tt.a); got != -tt.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", tt.b, tt.a, got, -tt.want)
+		}
+	}
+}
+
+func TestSystemSupports(t *testing.T) {
+
+	saved := FeatureMinVersions
+	defer func() { FeatureMinVersions = saved }()
+	FeatureMinVersions = map[string]string{FeatureNVMe: "7.1"}
+
+	tests := []struct {
+		version string
+		feature string
+		want    bool
+		unknown bool
+	}{
+		{version: "7.1.0", feature: FeatureNVMe, want: true},
+		{version: "7.0.9", feature: FeatureNVMe, want: false},
+		{version: "8.0", feature: FeatureSnapshotLock, unknown: true},
+	}
+
+	for _, tt := range tests {
+		system := &System{Version: tt.version}
+		got, err := system.Supports(tt.feature)
+		if tt.unknown {
+			if !errors.Is(err, ErrFeatureSupportUnknown) {
+				t.Errorf("%s on %s: error %v, want ErrFeatureSupportUnknown", tt.feature, tt.version, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("%s on %s: %t, %v, want %t", tt.feature, tt.version, got, err, tt.want)
+		}
+	}
+}
+
+func TestClientSupportsProbesCollection(t *testing.T) {
+
+	ibox := newTestIBOX(t)
+	ibox.handle(http.MethodGet, "/api/rest/replicas", func(w http.ResponseWriter, r *http.Request) {
+		writeResult(w, []interface{}{})
+	})
+	ibox.handle(http.MethodGet, "/api/rest/system", func(w http.ResponseWriter, r *http.Request) {
+		writeResult(w, System{Version: "7.3"})
+	})
+	client := ibox.client(t, Config{})
+
+	if supported, err := client.Supports(FeatureReplication); err != nil || !supported {
+		t.Errorf("replication: %t, %v, want supported", supported, err)
+	}
+	if supported, err := client.Supports(FeatureQoS); err != nil || supported {
+		t.Errorf("qos without collection: %t, %v, want unsupported", supported, err)
+	}
+	if _, err := client.Supports(FeatureSnapshotLock); !errors.Is(err, ErrFeatureSupportUnknown) {
+		t.Errorf("snapshot lock without verified version: error %v, want ErrFeatureSupportUnknown", err)
+	}
+}
@@ -0,0 +1,182 @@
+package infinibox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+//instrumentationName identifies client tracer and meter
+const instrumentationName = "github.com/devnal/infinibox-go-client"
+
+//Span and metric attribute names of management api calls
+const (
+	AttributeEndpoint     = "infinibox.endpoint"
+	AttributeMethod       = "http.request.method"
+	AttributeStatus       = "http.response.status_code"
+	AttributeAPIErrorCode = "infinibox.api.error_code"
+)
+
+//Client-side metric names
+const (
+	MetricRequests        = "infinibox.client.requests"
+	MetricRequestDuration = "infinibox.client.request.duration"
+	MetricRetries         = "infinibox.client.retries"
+	MetricLimiterWait     = "infinibox.client.limiter.wait"
+)
+
+//telemetry holds client tracer and metric instruments
+type telemetry struct {
+	tracer   trace.Tracer
+	requests metric.Int64Counter
+	duration metric.Float64Histogram
+	retries  metric.Int64Counter
+
+	limiterWait metric.Float64Histogram
+}
+
+//newTelemetry creates instruments from configured providers, global providers are used by default
+func newTelemetry(config *Config) *telemetry {
+
+	tracerProvider := config.TracerProvider
+	if tracerProvider == nil {
+		tracerProvider = otel.GetTracerProvider()
+	}
+	meterProvider := config.MeterProvider
+	if meterProvider == nil {
+		meterProvider = otel.GetMeterProvider()
+	}
+	meter := meterProvider.Meter(instrumentationName)
+
+	t := &telemetry{tracer: tracerProvider.Tracer(instrumentationName)}
+
+	var err error
+	if t.requests, err = meter.Int64Counter(MetricRequests,
+		metric.WithDescription("Number of management api requests sent"),
+		metric.WithUnit("{request}")); err != nil {
+		otel.Handle(err)
+	}
+	if t.duration, err = meter.Float64Histogram(MetricRequestDuration,
+		metric.WithDescription("Latency of management api requests"),
+		metric.WithUnit("s")); err != nil {
+		otel.Handle(err)
+	}
+	if t.retries, err = meter.Int64Counter(MetricRetries,
+		metric.WithDescription("Number of retried management api requests"),
+		metric.WithUnit("{retry}")); err != nil {
+		otel.Handle(err)
+	}
+	if t.limiterWait, err = meter.Float64Histogram(MetricLimiterWait,
+		metric.WithDescription("Time management api requests waited for rate limiter and in-flight caps"),
+		metric.WithUnit("s")); err != nil {
+		otel.Handle(err)
+	}
+
+	return t
+}
+
+//tracingTransport wraps every http attempt of RestClient into client span and records request metrics
+type tracingTransport struct {
+	base      http.RoundTripper
+	telemetry *telemetry
+}
+
+func (t *tracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+
+	e := parseEndpoint(req.URL.String())
+	attrs := []attribute.KeyValue{
+		attribute.String(AttributeEndpoint, e.template),
+		attribute.String(AttributeMethod, req.Method),
+	}
+
+	ctx, span := t.telemetry.tracer.Start(req.Context(), req.Method+" "+e.template,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...))
+	defer span.End()
+
+	req = req.Clone(ctx)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	elapsed := time.Since(start)
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	} else {
+		attrs = append(attrs, attribute.Int(AttributeStatus, resp.StatusCode))
+		span.SetAttributes(attribute.Int(AttributeStatus, resp.StatusCode))
+
+		if code := peekAPIErrorCode(resp); code != "" {
+			span.SetAttributes(attribute.String(AttributeAPIErrorCode, code))
+			span.SetStatus(codes.Error, code)
+		} else if resp.StatusCode >= 500 {
+			span.SetStatus(codes.Error, resp.Status)
+		}
+	}
+
+	t.telemetry.requests.Add(ctx, 1, metric.WithAttributes(attrs...))
+	if isRetry(ctx) {
+		t.telemetry.retries.Add(ctx, 1, metric.WithAttributes(attrs[:2]...))
+	}
+	t.telemetry.duration.Record(ctx, elapsed.Seconds(), metric.WithAttributes(attrs...))
+
+	return resp, err
+}
+
+//peekAPIErrorCode returns error code of failed management api response leaving body readable
+func peekAPIErrorCode(resp *http.Response) string {
+
+	if resp.StatusCode < 400 || resp.Body == nil {
+		return ""
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return ""
+	}
+
+	var apiresponse APIResponse
+	if err := json.Unmarshal(body, &apiresponse); err != nil {
+		return ""
+	}
+	code, _ := apiresponse.apiError()
+
+	return code
+}
+
+//attemptsKey carries attempt counter of single management api request in request context,
+//resty reuses request context for every retried attempt so transport counts attempts of request
+type attemptsKey struct{}
+
+//withAttemptCounter returns ctx carrying new attempt counter
+func withAttemptCounter(ctx context.Context) context.Context {
+	return context.WithValue(ctx, attemptsKey{}, new(int32))
+}
+
+//isRetry counts attempt of request carrying attempt counter and reports whether attempt is retry
+func isRetry(ctx context.Context) bool {
+	attempts, ok := ctx.Value(attemptsKey{}).(*int32)
+	return ok && atomic.AddInt32(attempts, 1) > 1
+}
+
+//WithContext returns client issuing requests with provided context, spans of
+//scoped client requests are children of span carried by ctx
+func (c *Client) WithContext(ctx context.Context) *Client {
+	scoped := *c
+	scoped.ctx = ctx
+	return &scoped
+}
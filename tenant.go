@@ -3,7 +3,7 @@ package infinibox
 import (
 	"encoding/json"
 	"fmt"
-	log "github.com/sirupsen/logrus"
+	"net/http"
 )
 
 type Tenant struct {
@@ -30,106 +30,249 @@ type Tenant struct {
 
 func (c *Client) GetTenantByName(tenantname string) (*Tenant, error) {
 
-	queryRes, err := c.Find("tenants", "name", "eq", tenantname)
-
+	tenant, found, err := cachedLookup(c, cacheTenants, tenantname, func() (*Tenant, bool, error) {
+		return c.lookupTenant(tenantname)
+	})
 	if err != nil {
 		return nil, err
 	}
 
+	if !found {
+		return nil, fmt.Errorf("tenant %s not found", tenantname)
+	}
+
+	return tenant, nil
+}
+
+func (c *Client) lookupTenant(tenantname string) (tenant *Tenant, found bool, err error) {
+
+	queryRes, err := c.withTenantID("").Find("tenants", "name", "eq", tenantname)
+
+	if err != nil {
+		return nil, false, err
+	}
+
+	if queryRes == nil {
+		return nil, false, nil
+	}
+
 	var tenants []Tenant
 
 	err = json.Unmarshal(*queryRes, &tenants)
 	if err != nil {
-		return nil, fmt.Errorf(fmt.Sprintf("unable to decode tenants collection: %s query result, error: %s", tenantname, err.Error()))
+		return nil, false, fmt.Errorf("unable to decode tenants collection: %s query result, error: %s", tenantname, err.Error())
+	}
+
+	if len(tenants) == 0 {
+		return nil, false, nil
+	}
+
+	c.debugf("Found tenant %#v", &tenants[0])
+
+	return &tenants[0], true, nil
+}
+
+func (c *Client) GetAllTenants() (*[]Tenant, error) {
+
+	c.debugf("Getting tenants collection")
+
+	tenants, err := getAllPages[Tenant](c.withTenantID(""), "api/rest/tenants", nil)
+	if err != nil {
+		return nil, fmt.Errorf("error getting tenants collection, %s", err.Error())
 	}
 
 	if len(tenants) == 0 {
-		return nil, fmt.Errorf(fmt.Sprintf("tenant %s not found", tenantname))
+		c.infof("tenants collection is empty")
+		return nil, nil
 	}
 
-	log.Debugf("Found tenant %#v", &tenants[0])
+	c.debugf("Got tenants collection")
 
-	return &tenants[0], nil
+	return &tenants, nil
+}
+
+func (c *Client) GetTenant(tenantID int64) (*Tenant, error) {
+
+	c.debugf("Getting tenant object ID: %d", tenantID)
+
+	url := fmt.Sprintf("api/rest/tenants/%d", tenantID)
+	response, err := c.withTenantID("").request().Get(url)
+
+	result, err := CheckAPIResponse(response, err)
+	if err != nil {
+		return nil, fmt.Errorf("error getting tenant object, %s", err.Error())
+	}
+
+	var tenant Tenant
+	err = json.Unmarshal(*result.APIResult, &tenant)
+	if err != nil {
+		return nil, fmt.Errorf("error getting tenant object, %s", err.Error())
+	}
+
+	c.debugf("Got tenant object: %#v", tenant)
+
+	return &tenant, nil
 }
 
 func (t *Tenant) Create(client *Client) (err error) {
 
-	log.Debugf("Creating tenant: %s", t.Name)
+	client.debugf("Creating tenant: %s", t.Name)
 	url := "api/rest/tenants"
-	response, err := client.RestClient.R().SetBody(map[string]interface{}{
+	response, err := client.withTenantID("").request().SetBody(map[string]interface{}{
 		"name": t.Name}).Post(url)
 
 	result, err := CheckAPIResponse(response, err)
 	if err != nil {
-		return fmt.Errorf(fmt.Sprintf("error creating tenant: %s,  %v", t.Name, err))
+		return fmt.Errorf("error creating tenant: %s,  %v", t.Name, err)
 	}
 
 	err = json.Unmarshal(*result.APIResult, &t)
 	if err != nil {
-		return fmt.Errorf(fmt.Sprintf("error creating tenant: %s,  %v", t.Name, err))
+		return fmt.Errorf("error creating tenant: %s,  %v", t.Name, err)
 	}
 
-	log.Debugf("Successfully created tenant %s", t.Name)
+	client.debugf("Successfully created tenant %s", t.Name)
 
 	return nil
 }
 
 func (t *Tenant) Delete(client *Client) (tenant *Tenant, err error) {
 
-	log.Debugf("Deleting tenant: %s", t.Name)
+	client.debugf("Deleting tenant: %s", t.Name)
 	url := fmt.Sprintf("api/rest/tenants/%d", t.ID)
-	response, err := client.RestClient.R().SetQueryParam("approved", "true").Delete(url)
+	response, err := client.withTenantID("").sendApproved(ApprovalRequest{Operation: OperationDelete, ObjectType: "tenant", ObjectName: t.Name, Method: http.MethodDelete, URL: url}, nil)
 
 	result, err := CheckAPIResponse(response, err)
 	if err != nil {
-		return nil, fmt.Errorf(fmt.Sprintf("error deleting tenant: %s,  %v", t.Name, err))
+		return nil, fmt.Errorf("error deleting tenant: %s,  %v", t.Name, err)
 	}
 
 	err = json.Unmarshal(*result.APIResult, &tenant)
 	if err != nil {
-		return nil, fmt.Errorf(fmt.Sprintf("error deleting tenant: %s,  %v", t.Name, err))
+		return nil, fmt.Errorf("error deleting tenant: %s,  %v", t.Name, err)
 	}
 
-	log.Debugf("Successfully deleted tenant %s", t.Name)
+	client.debugf("Successfully deleted tenant %s", t.Name)
 
 	return tenant, nil
 }
 
 func (t *Tenant) updateAttributes(client *Client, attributesMap map[string]interface{}) (err error) {
 
-	log.Debugf("Updating tenant: %s", t.Name)
+	client.debugf("Updating tenant: %s", t.Name)
 	url := fmt.Sprintf("api/rest/tenants/%d", t.ID)
 
 	if len(attributesMap) > 0 {
-		response, err := client.RestClient.R().SetBody(attributesMap).Put(url)
+		response, err := client.withTenantID("").request().SetBody(attributesMap).Put(url)
 
 		result, err := CheckAPIResponse(response, err)
 		if err != nil {
-			return fmt.Errorf(fmt.Sprintf("error updating tenant: %s,  %v", t.Name, err))
+			return fmt.Errorf("error updating tenant: %s,  %v", t.Name, err)
 		}
 
 		err = json.Unmarshal(*result.APIResult, &t)
 		if err != nil {
-			return fmt.Errorf(fmt.Sprintf("error updating tenant: %s,  %v", t.Name, err))
+			return fmt.Errorf("error updating tenant: %s,  %v", t.Name, err)
 		}
 	}
 
-	log.Debugf("Successfully updated tenant %s", t.Name)
+	client.debugf("Successfully updated tenant %s", t.Name)
 
 	return nil
 }
 
 func (t *Tenant) UpdateName(client *Client, name string) error {
 
-	log.Debugf("Renaming tenant %s", t.Name)
+	client.debugf("Renaming tenant %s", t.Name)
 
 	attributesMap := map[string]interface{}{"name": name}
 	err := t.updateAttributes(client, attributesMap)
 	if err != nil {
-		return fmt.Errorf(fmt.Sprintf("failed to rename tenant %s, %s", t.Name, err.Error()))
+		return fmt.Errorf("failed to rename tenant %s, %s", t.Name, err.Error())
 	}
 
-	log.Debugf("Succesfully renamed tenant %s to %s", t.Name, name)
+	client.debugf("Succesfully renamed tenant %s to %s", t.Name, name)
 
 	return nil
 }
+
+func (t *Tenant) UpdateVisibleToSysadmin(client *Client, visible bool) error {
+
+	client.debugf("Updating VisibleToSysadmin for tenant %s", t.Name)
+
+	attributesMap := map[string]interface{}{"visible_to_sysadmin": visible}
+	err := t.updateAttributes(client, attributesMap)
+	if err != nil {
+		return fmt.Errorf("failed to update tenant %s VisibleToSysadmin, %s", t.Name, err.Error())
+	}
+
+	client.debugf("Succesfully updated tenant %s VisibleToSysadmin to %v", t.Name, visible)
+
+	return nil
+}
+
+//TenantUsageReport represents tenant capacity usage and entity counts
+type TenantUsageReport struct {
+	TenantID               int64   `json:"tenant_id"`
+	Name                   string  `json:"name"`
+	TotalPhysicalCapacity  int64   `json:"total_physical_capacity"`
+	AllocatedPhysicalSpace int64   `json:"allocated_physical_space"`
+	PhysicalUsagePercent   float64 `json:"physical_usage_percent"`
+	TotalVirtualCapacity   int64   `json:"total_virtual_capacity"`
+	AllocatedVirtualSpace  int64   `json:"allocated_virtual_space"`
+	VirtualUsagePercent    float64 `json:"virtual_usage_percent"`
+	Plugins                int64   `json:"plugins"`
+	Pools                  int64   `json:"pools"`
+	NetworkSpaces          int64   `json:"network_spaces"`
+	Hosts                  int64   `json:"hosts"`
+	Clusters               int64   `json:"clusters"`
+}
+
+//usagePercent returns allocated share of total capacity, zero for empty capacity
+func usagePercent(allocated int64, total int64) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return float64(allocated) * 100 / float64(total)
+}
+
+//UsageReport combines tenant capacity and entity counts into usage report
+func (t *Tenant) UsageReport() *TenantUsageReport {
+	return &TenantUsageReport{
+		TenantID:               t.ID,
+		Name:                   t.Name,
+		TotalPhysicalCapacity:  t.Capacity.TotalPhysicalCapacity,
+		AllocatedPhysicalSpace: t.Capacity.AllocatedPhysicalSpace,
+		PhysicalUsagePercent:   usagePercent(t.Capacity.AllocatedPhysicalSpace, t.Capacity.TotalPhysicalCapacity),
+		TotalVirtualCapacity:   t.Capacity.TotalVirtualCapacity,
+		AllocatedVirtualSpace:  t.Capacity.AllocatedVirtualSpace,
+		VirtualUsagePercent:    usagePercent(t.Capacity.AllocatedVirtualSpace, t.Capacity.TotalVirtualCapacity),
+		Plugins:                t.EntityCounts.Plugins,
+		Pools:                  t.EntityCounts.Pools,
+		NetworkSpaces:          t.EntityCounts.NetworkSpaces,
+		Hosts:                  t.EntityCounts.Hosts,
+		Clusters:               t.EntityCounts.Clusters,
+	}
+}
+
+//GetTenantsUsageReport returns usage report for every tenant
+func (c *Client) GetTenantsUsageReport() (*[]TenantUsageReport, error) {
+
+	c.debugf("Building tenants usage report")
+
+	tenants, err := c.GetAllTenants()
+	if err != nil {
+		return nil, fmt.Errorf("error building tenants usage report, %s", err.Error())
+	}
+
+	reports := []TenantUsageReport{}
+	if tenants != nil {
+		for i := range *tenants {
+			reports = append(reports, *(*tenants)[i].UsageReport())
+		}
+	}
+
+	c.debugf("Built usage report for %d tenants", len(reports))
+
+	return &reports, nil
+}
@@ -3,9 +3,9 @@ package infinibox
 import (
 	"encoding/json"
 	"fmt"
-	"github.com/go-resty/resty"
 	"github.com/google/uuid"
-	log "github.com/sirupsen/logrus"
+	"net/http"
+	"sort"
 )
 
 //Volume represents IBOX volume struct
@@ -53,72 +53,64 @@ type Volume struct {
 //GetVolumeByName get volume by name
 func (c *Client) GetVolumeByName(volumename string) (*Volume, error) {
 
+	volume, found, err := cachedLookup(c, cacheVolumes, volumename, func() (*Volume, bool, error) {
+		return c.lookupVolume(volumename)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !found {
+		return nil, fmt.Errorf("volume %s not found", volumename)
+	}
+
+	return volume, nil
+}
+
+func (c *Client) lookupVolume(volumename string) (volume *Volume, found bool, err error) {
+
 	queryRes, err := c.Find("volumes", "name", "eq", volumename)
 
 	if err != nil {
-		return nil, fmt.Errorf("cannot find volume by name: %s, error: %s", volumename, err.Error())
+		return nil, false, fmt.Errorf("cannot find volume by name: %s, error: %s", volumename, err.Error())
 	}
 
 	if queryRes == nil {
-		return nil, nil
+		return nil, false, nil
 	}
 
 	var volumes []Volume
 
 	err = json.Unmarshal(*queryRes, &volumes)
 	if err != nil {
-		return nil, fmt.Errorf("unable to decode volume: %s query result, error: %s", volumename, err.Error())
+		return nil, false, fmt.Errorf("unable to decode volume: %s query result, error: %s", volumename, err.Error())
 	}
 
 	if len(volumes) == 0 {
-		return nil, fmt.Errorf("volume %s not found", volumename)
+		return nil, false, nil
 	}
 
-	log.Debugf("Found volume %#v", &volumes[0])
+	c.debugf("Found volume %#v", &volumes[0])
 
-	return &volumes[0], nil
+	return &volumes[0], true, nil
 }
 
 //GetAllVolumes get all defined volumes
 func (c *Client) GetAllVolumes() (*[]Volume, error) {
 
-	log.Debug("Getting volumes collection")
+	c.debugf("Getting volumes collection")
 
-	url := "api/rest/volumes"
-
-	var request *resty.Request
-
-	if c.config.tenant != "" {
-		log.Debugf("Adding tenant_id %s to request", c.config.tenant)
-		request = c.RestClient.R().SetHeader("X-INFINIDAT-TENANT-ID", c.config.tenant)
-	} else {
-		request = c.RestClient.R()
-	}
-
-	response, err := request.Get(url)
-
-	result, err := CheckAPIResponse(response, err)
+	volumes, err := getAllPages[Volume](c, "api/rest/volumes", nil)
 	if err != nil {
-		return nil, fmt.Errorf("error getting volumes collection")
+		return nil, fmt.Errorf("error getting volumes collection, %s", err.Error())
 	}
 
-	num := result.APIMetadata["number_of_objects"]
-
-	if num == nil {
-		return nil, fmt.Errorf("cannot parse metadata for number_of_objects field")
-	}
-	if num == float64(0) {
-		log.Infof("volumes collection is empty")
+	if len(volumes) == 0 {
+		c.infof("volumes collection is empty")
 		return nil, nil
 	}
 
-	var volumes []Volume
-	err = json.Unmarshal(*result.APIResult, &volumes)
-	if err != nil {
-		return nil, fmt.Errorf("error getting volumes collection")
-	}
-
-	log.Debugf("Got volumes collection")
+	c.debugf("Got volumes collection")
 
 	return &volumes, nil
 }
@@ -126,10 +118,10 @@ func (c *Client) GetAllVolumes() (*[]Volume, error) {
 //GetVolume get volume
 func (c *Client) GetVolume(volumeID int64) (*Volume, error) {
 
-	log.Debugf("Getting volume object ID: %d", volumeID)
+	c.debugf("Getting volume object ID: %d", volumeID)
 
 	url := fmt.Sprintf("api/rest/volumes/%d", volumeID)
-	response, err := c.RestClient.R().Get(url)
+	response, err := c.request().Get(url)
 
 	result, err := CheckAPIResponse(response, err)
 	if err != nil {
@@ -142,7 +134,7 @@ func (c *Client) GetVolume(volumeID int64) (*Volume, error) {
 		return nil, fmt.Errorf("error getting volume object %s", err.Error())
 	}
 
-	log.Debugf("Got volume object: %#v", volume)
+	c.debugf("Got volume object: %#v", volume)
 
 	return &volume, nil
 }
@@ -150,7 +142,7 @@ func (c *Client) GetVolume(volumeID int64) (*Volume, error) {
 //Create volume create method
 func (v *Volume) Create(client *Client) (err error) {
 
-	log.Debugf("Creating volume: %s", v.Name)
+	client.debugf("Creating volume: %s", v.Name)
 
 	if v.Provtype == "" {
 		v.Provtype = "THIN"
@@ -158,16 +150,7 @@ func (v *Volume) Create(client *Client) (err error) {
 
 	url := "api/rest/volumes"
 
-	var request *resty.Request
-
-	if client.config.tenant != "" {
-		log.Debugf("Adding tenant_id %s to request", client.config.tenant)
-		request = client.RestClient.R().SetHeader("X-INFINIDAT-TENANT-ID", client.config.tenant)
-	} else {
-		request = client.RestClient.R()
-	}
-
-	response, err := request.SetBody(map[string]interface{}{
+	response, err := client.request().SetBody(map[string]interface{}{
 		"name":            v.Name,
 		"pool_id":         v.PoolID,
 		"size":            v.Size,
@@ -185,17 +168,17 @@ func (v *Volume) Create(client *Client) (err error) {
 		return fmt.Errorf("error creating volume: %s,  %s", v.Name, err.Error())
 	}
 
-	log.Debugf("Succesfully created volume %s", v.Name)
+	client.debugf("Succesfully created volume %s", v.Name)
 	return nil
 }
 
 //Get volume get
 func (v *Volume) Get(client *Client) (volume *Volume, err error) {
 
-	log.Debugf("Getting volume: %s", v.Name)
+	client.debugf("Getting volume: %s", v.Name)
 
 	url := fmt.Sprintf("api/rest/volumes/%d", v.ID)
-	response, err := client.RestClient.R().Get(url)
+	response, err := client.request().Get(url)
 
 	result, err := CheckAPIResponse(response, err)
 	if err != nil {
@@ -207,7 +190,7 @@ func (v *Volume) Get(client *Client) (volume *Volume, err error) {
 		return nil, fmt.Errorf("error getting volume: %s,  %s", v.Name, err.Error())
 	}
 
-	log.Debugf("Succesfully fetched volume %s", v.Name)
+	client.debugf("Succesfully fetched volume %s", v.Name)
 
 	return volume, nil
 }
@@ -215,10 +198,10 @@ func (v *Volume) Get(client *Client) (volume *Volume, err error) {
 //GetLUNs volume defines LUNs
 func (v *Volume) GetLUNs(client *Client) (luns *[]Lun, err error) {
 
-	log.Debugf("Getting volume: %s luns", v.Name)
+	client.debugf("Getting volume: %s luns", v.Name)
 
 	url := fmt.Sprintf("api/rest/volumes/%d/luns", v.ID)
-	response, err := client.RestClient.R().Get(url)
+	response, err := client.request().Get(url)
 
 	result, err := CheckAPIResponse(response, err)
 	if err != nil {
@@ -231,15 +214,133 @@ func (v *Volume) GetLUNs(client *Client) (luns *[]Lun, err error) {
 		return nil, fmt.Errorf("error getting volume %s luns,  %s", v.Name, err.Error())
 	}
 
-	log.Debugf("Succesfully fetched all LUNs information for volume %s", v.Name)
+	client.debugf("Succesfully fetched all LUNs information for volume %s", v.Name)
 
 	return luns, nil
 }
 
+//firstAssignableLun lowest LUN number assigned by MapToHost and MapToCluster
+const firstAssignableLun = 1
+
+//nextFreeLun returns first LUN number starting from start not present in used
+func nextFreeLun(used map[int]bool, start int) int {
+	if start < firstAssignableLun {
+		start = firstAssignableLun
+	}
+	for used[start] {
+		start++
+	}
+	return start
+}
+
+//MapToHost maps volume to host using first free LUN starting from startLun, returns assigned LUN
+func (v *Volume) MapToHost(client *Client, host *Host, startLun int) (lun *Lun, err error) {
+
+	client.debugf("Mapping volume %s to host %s", v.Name, host.Name)
+
+	mu := client.lunLock(fmt.Sprintf("hosts/%d", host.ID))
+	mu.Lock()
+	defer mu.Unlock()
+
+	luns, err := host.GetLUNs(client)
+	if err != nil {
+		return nil, fmt.Errorf("error mapping volume %s to host %s, %s", v.Name, host.Name, err.Error())
+	}
+
+	used := map[int]bool{}
+	if luns != nil {
+		for _, l := range *luns {
+			if l.VolumeID == v.ID {
+				existing := l
+				client.infof("volume %s already mapped to host %s as LUN %d", v.Name, host.Name, existing.Lun)
+				return &existing, nil
+			}
+			used[l.Lun] = true
+		}
+	}
+
+	lun = &Lun{VolumeID: v.ID, Lun: nextFreeLun(used, startLun)}
+	err = host.AddLUN(client, lun)
+	if err != nil {
+		return nil, fmt.Errorf("error mapping volume %s to host %s, %s", v.Name, host.Name, err.Error())
+	}
+
+	client.debugf("Succesfully mapped volume %s to host %s as LUN %d", v.Name, host.Name, lun.Lun)
+
+	return lun, nil
+}
+
+//MapToCluster maps volume to host cluster using first LUN starting from startLun
+//which is free on the cluster and all of its hosts, returns assigned LUN
+func (v *Volume) MapToCluster(client *Client, cluster *HostCluster, startLun int) (lun *Lun, err error) {
+
+	client.debugf("Mapping volume %s to host cluster %s", v.Name, cluster.Name)
+
+	mu := client.lunLock(fmt.Sprintf("clusters/%d", cluster.ID))
+	mu.Lock()
+	defer mu.Unlock()
+
+	hosts, err := cluster.GetHosts(client)
+	if err != nil {
+		return nil, fmt.Errorf("error mapping volume %s to host cluster %s, %s", v.Name, cluster.Name, err.Error())
+	}
+
+	var members []Host
+	if hosts != nil {
+		members = *hosts
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].ID < members[j].ID })
+
+	for _, host := range members {
+		hostMu := client.lunLock(fmt.Sprintf("hosts/%d", host.ID))
+		hostMu.Lock()
+		defer hostMu.Unlock()
+	}
+
+	luns, err := cluster.GetLUNs(client)
+	if err != nil {
+		return nil, fmt.Errorf("error mapping volume %s to host cluster %s, %s", v.Name, cluster.Name, err.Error())
+	}
+
+	used := map[int]bool{}
+	if luns != nil {
+		for _, l := range *luns {
+			if l.VolumeID == v.ID {
+				existing := l
+				client.infof("volume %s already mapped to host cluster %s as LUN %d", v.Name, cluster.Name, existing.Lun)
+				return &existing, nil
+			}
+			used[l.Lun] = true
+		}
+	}
+
+	for _, host := range members {
+		hostLuns, err := host.GetLUNs(client)
+		if err != nil {
+			return nil, fmt.Errorf("error mapping volume %s to host cluster %s, %s", v.Name, cluster.Name, err.Error())
+		}
+		if hostLuns != nil {
+			for _, l := range *hostLuns {
+				used[l.Lun] = true
+			}
+		}
+	}
+
+	lun = &Lun{VolumeID: v.ID, Lun: nextFreeLun(used, startLun)}
+	err = cluster.AddLUN(client, lun)
+	if err != nil {
+		return nil, fmt.Errorf("error mapping volume %s to host cluster %s, %s", v.Name, cluster.Name, err.Error())
+	}
+
+	client.debugf("Succesfully mapped volume %s to host cluster %s as LUN %d", v.Name, cluster.Name, lun.Lun)
+
+	return lun, nil
+}
+
 //UnMap volume unmap
 func (v *Volume) UnMap(client *Client) (err error) {
 
-	log.Debugf("Unmapping volume: %s luns", v.Name)
+	client.debugf("Unmapping volume: %s luns", v.Name)
 
 	currentVolume, err := v.Get(client)
 
@@ -253,12 +354,15 @@ func (v *Volume) UnMap(client *Client) (err error) {
 		if err != nil {
 			return fmt.Errorf("error unmapping volume %s luns, error: %s", v.Name, err.Error())
 		}
+		if luns == nil {
+			luns = &[]Lun{}
+		}
 
 		for _, lun := range *luns {
 			if lun.Clustered && lun.HostID != 0 {
-				log.Infof("unmapping host cluster LUN %+v from volume %s", lun, v.Name)
+				client.infof("unmapping host cluster LUN %+v from volume %s", lun, v.Name)
 				url := fmt.Sprintf("api/rest/clusters/%d/luns/lun/%d", lun.HostClusterID, lun.Lun)
-				response, err := client.RestClient.R().SetQueryParam("approved", "true").Delete(url)
+				response, err := client.sendApproved(ApprovalRequest{Operation: OperationUnmap, ObjectType: "volume", ObjectName: v.Name, Method: http.MethodDelete, URL: url}, nil)
 
 				result, err := CheckAPIResponse(response, err)
 				if err != nil {
@@ -270,16 +374,22 @@ func (v *Volume) UnMap(client *Client) (err error) {
 				if err != nil {
 					return fmt.Errorf("error deleting host cluster: %d lun ID %d,  %s", lun.HostClusterID, lun.Lun, err.Error())
 				}
-				log.Infof("unmapped host cluster LUN %+v from volume %s", lun, v.Name)
+				client.infof("unmapped host cluster LUN %+v from volume %s", lun, v.Name)
 			}
 		}
 
 		luns, err = currentVolume.GetLUNs(client)
+		if err != nil {
+			return fmt.Errorf("error unmapping volume %s luns, error: %s", v.Name, err.Error())
+		}
+		if luns == nil {
+			luns = &[]Lun{}
+		}
 
 		for _, lun := range *luns {
-			log.Infof("unmapping host LUN %+v from volume %s", lun, v.Name)
+			client.infof("unmapping host LUN %+v from volume %s", lun, v.Name)
 			url := fmt.Sprintf("api/rest/hosts/%d/luns/lun/%d", lun.HostID, lun.Lun)
-			response, err := client.RestClient.R().SetQueryParam("approved", "true").Delete(url)
+			response, err := client.sendApproved(ApprovalRequest{Operation: OperationUnmap, ObjectType: "volume", ObjectName: v.Name, Method: http.MethodDelete, URL: url}, nil)
 
 			result, err := CheckAPIResponse(response, err)
 			if err != nil {
@@ -291,13 +401,13 @@ func (v *Volume) UnMap(client *Client) (err error) {
 			if err != nil {
 				return fmt.Errorf("error deleting host: %d lun ID %d,  %s", lun.HostID, lun.Lun, err.Error())
 			}
-			log.Infof("unmapped host LUN %+v from volume %s", lun, v.Name)
+			client.infof("unmapped host LUN %+v from volume %s", lun, v.Name)
 		}
 	} else {
-		log.Infof("volume %s is not mapped", v.Name)
+		client.infof("volume %s is not mapped", v.Name)
 	}
 
-	log.Debugf("Succesfully unmapped volume %s", v.Name)
+	client.debugf("Succesfully unmapped volume %s", v.Name)
 
 	return nil
 }
@@ -305,33 +415,148 @@ func (v *Volume) UnMap(client *Client) (err error) {
 //Delete volume delete
 func (v *Volume) Delete(client *Client) (err error) {
 
-	log.Debugf("Deleting volume: %s", v.Name)
+	client.debugf("Deleting volume: %s", v.Name)
 
 	url := fmt.Sprintf("api/rest/volumes/%d", v.ID)
-	response, err := client.RestClient.R().SetQueryParam("approved", "true").Delete(url)
+	response, err := client.sendApproved(ApprovalRequest{Operation: OperationDelete, ObjectType: "volume", ObjectName: v.Name, Method: http.MethodDelete, URL: url}, nil)
 
 	result, err := CheckAPIResponse(response, err)
 	if err != nil {
 		return fmt.Errorf("error deleting volume: %s,  %s", v.Name, err.Error())
 	}
+
 	var volume Volume
 	err = json.Unmarshal(*result.APIResult, &volume)
 	if err != nil {
 		return fmt.Errorf("error deleting volume: %s,  %s", v.Name, err.Error())
 	}
 
-	log.Debugf("Succesfully deleted volume %s", v.Name)
+	client.debugf("Succesfully deleted volume %s", v.Name)
 
 	return nil
 }
 
+//UnMapDeleteReport describes steps performed by UnMapAndDelete
+type UnMapDeleteReport struct {
+	VolumeID       int64
+	VolumeName     string
+	Mappings       []Lun
+	Unmapped       []Lun
+	Deleted        bool
+	RolledBack     bool
+	Restored       []Lun
+	RollbackErrors []error
+}
+
+//lunMappingKey identifies single host or host cluster mapping of volume LUN
+func lunMappingKey(lun Lun) string {
+	if lun.Clustered && lun.HostClusterID != 0 {
+		return fmt.Sprintf("clusters/%d/%d", lun.HostClusterID, lun.Lun)
+	}
+	return fmt.Sprintf("hosts/%d/%d", lun.HostID, lun.Lun)
+}
+
+//unmapLun removes single host or host cluster mapping of volume LUN
+func (v *Volume) unmapLun(client *Client, lun Lun) (err error) {
+
+	if lun.Clustered && lun.HostClusterID != 0 {
+		hostCluster := &HostCluster{ID: lun.HostClusterID}
+		_, err = hostCluster.DeleteLUN(client, lun.Lun)
+	} else {
+		host := &Host{ID: lun.HostID}
+		_, err = host.DeleteLUN(client, lun.Lun)
+	}
+
+	return err
+}
+
+//mapLun restores single host or host cluster mapping of volume LUN
+func (v *Volume) mapLun(client *Client, lun Lun) (err error) {
+
+	restored := &Lun{VolumeID: v.ID, Lun: lun.Lun}
+
+	if lun.Clustered && lun.HostClusterID != 0 {
+		hostCluster := &HostCluster{ID: lun.HostClusterID}
+		return hostCluster.AddLUN(client, restored)
+	}
+	host := &Host{ID: lun.HostID}
+	return host.AddLUN(client, restored)
+}
+
+//UnMapAndDelete records volume LUN mappings, unmaps them and deletes volume,
+//on failure original mappings are restored and report describes what was done
+func (v *Volume) UnMapAndDelete(client *Client) (report *UnMapDeleteReport, err error) {
+
+	client.debugf("Unmapping and deleting volume: %s", v.Name)
+
+	report = &UnMapDeleteReport{VolumeID: v.ID, VolumeName: v.Name}
+
+	luns, err := v.GetLUNs(client)
+	if err != nil {
+		return report, fmt.Errorf("error unmapping and deleting volume %s, %s", v.Name, err.Error())
+	}
+
+	seen := map[string]bool{}
+	if luns != nil {
+		for _, lun := range *luns {
+			key := lunMappingKey(lun)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			report.Mappings = append(report.Mappings, lun)
+		}
+	}
+
+	for _, lun := range report.Mappings {
+		client.infof("unmapping LUN %+v from volume %s", lun, v.Name)
+		err = v.unmapLun(client, lun)
+		if err != nil {
+			err = fmt.Errorf("error unmapping LUN %d from volume %s, %s", lun.Lun, v.Name, err.Error())
+			v.rollbackUnMap(client, report)
+			return report, err
+		}
+		report.Unmapped = append(report.Unmapped, lun)
+	}
+
+	err = v.Delete(client)
+	if err != nil {
+		v.rollbackUnMap(client, report)
+		return report, err
+	}
+	report.Deleted = true
+
+	client.debugf("Succesfully unmapped and deleted volume %s", v.Name)
+
+	return report, nil
+}
+
+//rollbackUnMap restores mappings removed by UnMapAndDelete in reverse order
+func (v *Volume) rollbackUnMap(client *Client, report *UnMapDeleteReport) {
+
+	client.infof("Restoring %d LUN mappings of volume %s", len(report.Unmapped), v.Name)
+
+	report.RolledBack = true
+
+	for i := len(report.Unmapped) - 1; i >= 0; i-- {
+		lun := report.Unmapped[i]
+		err := v.mapLun(client, lun)
+		if err != nil {
+			client.errorf("failed to restore LUN %d of volume %s, %s", lun.Lun, v.Name, err.Error())
+			report.RollbackErrors = append(report.RollbackErrors, err)
+			continue
+		}
+		report.Restored = append(report.Restored, lun)
+	}
+}
+
 func (v *Volume) updateAttributes(client *Client, attributesMap map[string]interface{}) (err error) {
 
-	log.Debugf("Updating volume: %s", v.Name)
+	client.debugf("Updating volume: %s", v.Name)
 
 	if len(attributesMap) > 0 {
 		url := fmt.Sprintf("api/rest/volumes/%d", v.ID)
-		response, err := client.RestClient.R().SetBody(attributesMap).Put(url)
+		response, err := client.request().SetBody(attributesMap).Put(url)
 
 		result, err := CheckAPIResponse(response, err)
 		if err != nil {
@@ -343,7 +568,7 @@ func (v *Volume) updateAttributes(client *Client, attributesMap map[string]inter
 			return fmt.Errorf("error updating volume: %s,  %s", v.Name, err.Error())
 		}
 
-		log.Infof("Succesfully updated volume %s", v.Name)
+		client.infof("Succesfully updated volume %s", v.Name)
 	}
 	return nil
 }
@@ -351,7 +576,7 @@ func (v *Volume) updateAttributes(client *Client, attributesMap map[string]inter
 //UpdateName sets volume name
 func (v *Volume) UpdateName(client *Client, name string) error {
 
-	log.Debugf("Renaming volume %s", v.Name)
+	client.debugf("Renaming volume %s", v.Name)
 
 	body := map[string]interface{}{"name": name}
 	err := v.updateAttributes(client, body)
@@ -359,7 +584,7 @@ func (v *Volume) UpdateName(client *Client, name string) error {
 		return fmt.Errorf("failed to rename volume %s, %s", v.Name, err.Error())
 	}
 
-	log.Debugf("Succesfully renamed volume to %s", v.Name)
+	client.debugf("Succesfully renamed volume to %s", v.Name)
 
 	return nil
 }
@@ -367,7 +592,7 @@ func (v *Volume) UpdateName(client *Client, name string) error {
 //UpdateProvisioning sets volume thin/thick provision type
 func (v *Volume) UpdateProvisioning(client *Client, provtype string) error {
 
-	log.Debugf("Updating provisioning type for volume %s", v.Name)
+	client.debugf("Updating provisioning type for volume %s", v.Name)
 
 	body := map[string]interface{}{"provtype": provtype}
 	err := v.updateAttributes(client, body)
@@ -375,7 +600,7 @@ func (v *Volume) UpdateProvisioning(client *Client, provtype string) error {
 		return fmt.Errorf("failed to update provisioning type %s, %s", v.Name, err.Error())
 	}
 
-	log.Debugf("Succesfully updated provisioning type to %s for volume %s", v.Provtype, v.Name)
+	client.debugf("Succesfully updated provisioning type to %s for volume %s", v.Provtype, v.Name)
 
 	return nil
 }
@@ -383,7 +608,7 @@ func (v *Volume) UpdateProvisioning(client *Client, provtype string) error {
 //UpdateSsdEnabled enable/disable volume SSD cache flag
 func (v *Volume) UpdateSsdEnabled(client *Client, ssdEnabled bool) error {
 
-	log.Debugf("Updating provisioning type for volume %s", v.Name)
+	client.debugf("Updating provisioning type for volume %s", v.Name)
 
 	body := map[string]interface{}{"ssd_enabled": ssdEnabled}
 	err := v.updateAttributes(client, body)
@@ -391,7 +616,7 @@ func (v *Volume) UpdateSsdEnabled(client *Client, ssdEnabled bool) error {
 		return fmt.Errorf("failed to update ssd_enabled to %s, %s", v.Name, err.Error())
 	}
 
-	log.Debugf("Succesfully updated ssd_enabled to %v for volume %s", v.SsdEnabled, v.Name)
+	client.debugf("Succesfully updated ssd_enabled to %v for volume %s", v.SsdEnabled, v.Name)
 
 	return nil
 }
@@ -399,7 +624,7 @@ func (v *Volume) UpdateSsdEnabled(client *Client, ssdEnabled bool) error {
 //UpdateWriteProtected volume parameter
 func (v *Volume) UpdateWriteProtected(client *Client, writeProtected bool) error {
 
-	log.Debugf("Updating write protected for volume %s", v.Name)
+	client.debugf("Updating write protected for volume %s", v.Name)
 
 	body := map[string]interface{}{"write_protected": writeProtected}
 	err := v.updateAttributes(client, body)
@@ -407,7 +632,7 @@ func (v *Volume) UpdateWriteProtected(client *Client, writeProtected bool) error
 		return fmt.Errorf("failed to update write_protected to %s, %s", v.Name, err.Error())
 	}
 
-	log.Debugf("Succesfully updated write_protected to %v for volume %s", v.WriteProtected, v.Name)
+	client.debugf("Succesfully updated write_protected to %v for volume %s", v.WriteProtected, v.Name)
 
 	return nil
 }
@@ -415,7 +640,7 @@ func (v *Volume) UpdateWriteProtected(client *Client, writeProtected bool) error
 //UpdateSize volume parameter
 func (v *Volume) UpdateSize(client *Client, size uint64) error {
 
-	log.Debugf("Updating provisioning type for volume %s", v.Name)
+	client.debugf("Updating provisioning type for volume %s", v.Name)
 
 	body := map[string]interface{}{"size": size}
 	err := v.updateAttributes(client, body)
@@ -423,7 +648,7 @@ func (v *Volume) UpdateSize(client *Client, size uint64) error {
 		return fmt.Errorf("failed to update size to %s, %s", v.Name, err.Error())
 	}
 
-	log.Debugf("Succesfully updated size to %v for volume %s", v.WriteProtected, v.Name)
+	client.debugf("Succesfully updated size to %d for volume %s", v.Size, v.Name)
 
 	return nil
 }
@@ -431,15 +656,17 @@ func (v *Volume) UpdateSize(client *Client, size uint64) error {
 //Snapshot create volume snapshot
 func (v *Volume) Snapshot(client *Client, name string) (snapshot *Volume, err error) {
 
-	log.Debugf("Creating snapshot: %s", v.Name)
+	client.debugf("Creating snapshot: %s", v.Name)
 
 	url := "api/rest/volumes"
 	body := map[string]interface{}{"parent_id": v.ID}
 
 	if name == "" {
-		body["name"] = fmt.Sprintf("auto-snapshot-%s", uuid.New())
+		name = fmt.Sprintf("auto-snapshot-%s", uuid.New())
 	}
-	response, err := client.RestClient.R().SetBody(body).Post(url)
+	body["name"] = name
+
+	response, err := client.request().SetBody(body).Post(url)
 
 	result, err := CheckAPIResponse(response, err)
 	if err != nil {
@@ -451,7 +678,7 @@ func (v *Volume) Snapshot(client *Client, name string) (snapshot *Volume, err er
 		return nil, fmt.Errorf("error creating volume: %s,  %s", v.Name, err.Error())
 	}
 
-	log.Debugf("Succesfully created snapshot %s for volume %s", snapshot.Name, v.Name)
+	client.debugf("Succesfully created snapshot %s for volume %s", snapshot.Name, v.Name)
 
 	return snapshot, nil
 }
@@ -459,12 +686,12 @@ func (v *Volume) Snapshot(client *Client, name string) (snapshot *Volume, err er
 //Restore volume from snapshot
 func (v *Volume) Restore(client *Client, snapshotID uint64) (err error) {
 
-	log.Debugf("Restoring volume %s from snapshot ID %d", v.Name, snapshotID)
+	client.debugf("Restoring volume %s from snapshot ID %d", v.Name, snapshotID)
 
 	url := fmt.Sprintf("api/rest/volumes/%d/restore", v.ID)
 	body := fmt.Sprintf("%d", snapshotID)
 
-	response, err := client.RestClient.R().SetBody(body).SetQueryParam("approved", "true").Post(url)
+	response, err := client.sendApproved(ApprovalRequest{Operation: OperationRestore, ObjectType: "volume", ObjectName: v.Name, Method: http.MethodPost, URL: url}, body)
 
 	result, err := CheckAPIResponse(response, err)
 	if err != nil {
@@ -480,7 +707,7 @@ func (v *Volume) Restore(client *Client, snapshotID uint64) (err error) {
 		return fmt.Errorf("error restoring volume: %s from snapshot ID %d, operation not completed successfully", v.Name, snapshotID)
 	}
 
-	log.Debugf("Succesfully restored volume %s to snapshotID %d", v.Name, snapshotID)
+	client.debugf("Succesfully restored volume %s to snapshotID %d", v.Name, snapshotID)
 
 	return nil
 }
@@ -488,14 +715,14 @@ func (v *Volume) Restore(client *Client, snapshotID uint64) (err error) {
 //Refresh update snapshot from volume
 func (v *Volume) Refresh(client *Client, snapshotID uint64) (err error) {
 
-	log.Debugf("Refreshing volume %s to snapshot ID %d", v.Name, snapshotID)
+	client.debugf("Refreshing volume %s to snapshot ID %d", v.Name, snapshotID)
 
 	url := fmt.Sprintf("api/rest/volumes/%d/refresh", snapshotID)
 
 	body := map[string]interface{}{}
 	body["source_id"] = v.ID
 
-	response, err := client.RestClient.R().SetBody(body).SetQueryParam("approved", "true").Post(url)
+	response, err := client.sendApproved(ApprovalRequest{Operation: OperationRefresh, ObjectType: "volume", ObjectName: v.Name, Method: http.MethodPost, URL: url}, body)
 
 	result, err := CheckAPIResponse(response, err)
 	if err != nil {
@@ -508,7 +735,101 @@ func (v *Volume) Refresh(client *Client, snapshotID uint64) (err error) {
 		return fmt.Errorf("error refreshing volume: %s to snapshot ID %d,  %s", v.Name, snapshotID, err.Error())
 	}
 
-	log.Debugf("Succesfully refreshed volume %s to snapshotID %d", v.Name, snapshotID)
+	client.debugf("Succesfully refreshed volume %s to snapshotID %d", v.Name, snapshotID)
+
+	return nil
+}
+
+//SetMetadata sets volume metadata key value
+func (v *Volume) SetMetadata(client *Client, key string, value string) (err error) {
+
+	client.debugf("Setting metadata for volume %s", v.Name)
+
+	err = client.AddMetadata(&Metadata{ObjectID: v.ID, Key: key, Value: value})
+	if err != nil {
+		return fmt.Errorf("unable to set metadata for volume %s, error %s", v.Name, err.Error())
+	}
+
+	client.debugf("Set metadata for volume %s", v.Name)
+
+	return nil
+}
+
+//SetMetadataMap sets all provided volume metadata keys in single request
+func (v *Volume) SetMetadataMap(client *Client, values map[string]interface{}) (err error) {
+
+	client.debugf("Setting metadata map for volume %s", v.Name)
+
+	err = client.AddMetadataMap(v.ID, values)
+	if err != nil {
+		return fmt.Errorf("unable to set metadata for volume %s, error %s", v.Name, err.Error())
+	}
+
+	client.debugf("Set metadata map for volume %s", v.Name)
+
+	return nil
+}
+
+//GetMetadata returns volume metadata, filtered by key when not empty
+func (v *Volume) GetMetadata(client *Client, key string) (metadata *[]Metadata, err error) {
+
+	client.debugf("Getting metadata for volume %s", v.Name)
+
+	metadata, err = client.GetMetadataByObject(v.ID)
+	if err != nil {
+		return metadata, fmt.Errorf("unable to get metadata for volume %s, error %s", v.Name, err.Error())
+	}
+
+	metadata = filterMetadataByKey(metadata, key)
+
+	client.debugf("Got metadata for volume %s", v.Name)
+
+	return metadata, nil
+}
+
+//GetMetadataValue returns volume metadata key value
+func (v *Volume) GetMetadataValue(client *Client, key string) (value interface{}, err error) {
+
+	client.debugf("Getting metadata value for volume %s and key %s", v.Name, key)
+
+	metadata, err := client.GetMetadataByObjectAndKey(v.ID, key)
+	if err != nil {
+		return value, fmt.Errorf("unable to get metadata for volume %s, error %s", v.Name, err.Error())
+	}
+
+	value = metadata.Value
+
+	client.debugf("Got metadata value for volume %s and key %s", v.Name, key)
+
+	return value, nil
+}
+
+//UnSetMetadata removes volume metadata key
+func (v *Volume) UnSetMetadata(client *Client, key string) (err error) {
+
+	client.debugf("Unsetting metadata for volume %s", v.Name)
+
+	err = client.DeleteMetadataByKey(v.ID, key)
+	if err != nil {
+		return fmt.Errorf("unable to unset metadata for volume %s, error %s", v.Name, err.Error())
+	}
+
+	client.debugf("Unset metadata for volume %s", v.Name)
+
+	return nil
+}
+
+//ClearMetadata removes all volume metadata
+func (v *Volume) ClearMetadata(client *Client) (err error) {
+
+	client.debugf("Clearing metadata for volume %s", v.Name)
+
+	err = client.DeleteMetadata(v.ID)
+	if err != nil {
+		return fmt.Errorf("unable to clear metadata for volume %s, error %s", v.Name, err.Error())
+	}
+
+	client.debugf("Cleared metadata for volume %s", v.Name)
 
 	return nil
 }
@@ -0,0 +1,49 @@
+//Package zaplogger adapts zap loggers to infinibox client Logger
+package zaplogger
+
+import (
+	"github.com/devnal/infinibox-go-client"
+	"go.uber.org/zap"
+	"sort"
+)
+
+//zapLogger adapts zap logger to infinibox Logger
+type zapLogger struct {
+	logger *zap.Logger
+}
+
+//New returns infinibox Logger writing records to provided zap logger
+func New(logger *zap.Logger) infinibox.Logger {
+	return &zapLogger{logger: logger}
+}
+
+func (l *zapLogger) Debug(msg string, fields infinibox.Fields) {
+	l.logger.Debug(msg, zapFields(fields)...)
+}
+
+func (l *zapLogger) Info(msg string, fields infinibox.Fields) {
+	l.logger.Info(msg, zapFields(fields)...)
+}
+
+func (l *zapLogger) Warn(msg string, fields infinibox.Fields) {
+	l.logger.Warn(msg, zapFields(fields)...)
+}
+
+func (l *zapLogger) Error(msg string, fields infinibox.Fields) {
+	l.logger.Error(msg, zapFields(fields)...)
+}
+
+//zapFields converts record fields to zap fields in stable order
+func zapFields(fields infinibox.Fields) []zap.Field {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	converted := make([]zap.Field, 0, len(keys))
+	for _, key := range keys {
+		converted = append(converted, zap.Any(key, fields[key]))
+	}
+	return converted
+}